@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"go.uber.org/zap"
+)
+
+const CName = "publish.admin"
+
+var log = logger.NewNamed(CName)
+
+func New() Admin {
+	return &admin{mux: http.NewServeMux()}
+}
+
+// Admin is the http api for operators, other components register their handlers via Handle
+type Admin interface {
+	Handle(pattern string, handler http.Handler)
+	app.ComponentRunnable
+}
+
+type admin struct {
+	config Config
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+func (a *admin) Name() (name string) {
+	return CName
+}
+
+func (a *admin) Init(ap *app.App) (err error) {
+	a.config = ap.MustComponent("config").(configGetter).GetAdmin()
+	return
+}
+
+func (a *admin) Handle(pattern string, handler http.Handler) {
+	a.mux.Handle(pattern, handler)
+}
+
+func (a *admin) Run(ctx context.Context) (err error) {
+	if a.config.Addr == "" {
+		return
+	}
+	a.server = &http.Server{Addr: a.config.Addr, Handler: a.authHandler(a.mux)}
+	var errCh = make(chan error)
+	go func() {
+		errCh <- a.server.ListenAndServe()
+	}()
+	select {
+	case err = <-errCh:
+		return err
+	case <-time.After(200 * time.Millisecond):
+		log.Info("admin api started", zap.String("addr", a.config.Addr))
+		return
+	}
+}
+
+func (a *admin) authHandler(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + a.config.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// an empty token disables the api instead of opening it
+		if a.config.Token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			WriteErr(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *admin) Close(ctx context.Context) (err error) {
+	if a.server != nil {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		return a.server.Shutdown(ctx)
+	}
+	return
+}
+
+// WriteJSON writes the value as json response
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		WriteErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// WriteErr writes the error as json response
+func WriteErr(w http.ResponseWriter, status int, err error) {
+	WriteJSON(w, status, struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
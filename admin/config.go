@@ -0,0 +1,12 @@
+package admin
+
+type configGetter interface {
+	GetAdmin() Config
+}
+
+type Config struct {
+	// Addr is the listen address of the admin http api, the api is disabled when empty
+	Addr string `yaml:"addr"`
+	// Token is the bearer token required by every admin request
+	Token string `yaml:"token"`
+}
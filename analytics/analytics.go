@@ -0,0 +1,221 @@
+// Package analytics stores per-identity analytics integrations and renders their snippets.
+//
+// Owners choose a provider from the allowlist and pass its validated parameters,
+// the snippet html is always built by the server.
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+const CName = "publish.analytics"
+
+const (
+	ProviderPlausible = "plausible"
+	ProviderUmami     = "umami"
+	ProviderGoogle    = "google"
+
+	configCacheTTL = time.Minute
+)
+
+type provider struct {
+	siteId      *regexp.Regexp
+	defaultHost string
+	snippet     func(host, siteId string) string
+}
+
+// providers is the allowlist of the supported analytics providers
+var providers = map[string]provider{
+	ProviderPlausible: {
+		siteId:      regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`),
+		defaultHost: "plausible.io",
+		snippet: func(host, siteId string) string {
+			return fmt.Sprintf(`<script defer data-domain="%s" src="https://%s/js/script.js"></script>`, siteId, host)
+		},
+	},
+	ProviderUmami: {
+		siteId:      regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+		defaultHost: "cloud.umami.is",
+		snippet: func(host, siteId string) string {
+			return fmt.Sprintf(`<script defer src="https://%s/script.js" data-website-id="%s"></script>`, host, siteId)
+		},
+	},
+	ProviderGoogle: {
+		siteId: regexp.MustCompile(`^G-[A-Z0-9]{4,16}$`),
+		snippet: func(_, siteId string) string {
+			return fmt.Sprintf(`<script async src="https://www.googletagmanager.com/gtag/js?id=%s"></script>`+
+				`<script>window.dataLayer=window.dataLayer||[];function gtag(){dataLayer.push(arguments);}gtag('js',new Date());gtag('config','%s');</script>`,
+				siteId, siteId)
+		},
+	},
+}
+
+var hostRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+func New() Analytics {
+	return new(analytics)
+}
+
+type Analytics interface {
+	// Set validates and saves the analytics config of the identity
+	Set(ctx context.Context, config domain.AnalyticsConfig) (err error)
+	// Get returns the analytics config of the identity or publishapi.ErrNotFound
+	Get(ctx context.Context, identity string) (config domain.AnalyticsConfig, err error)
+	// Delete removes the analytics config of the identity
+	Delete(ctx context.Context, identity string) (err error)
+	// Snippet returns the analytics snippet of the identity, empty if the identity has no config
+	Snippet(ctx context.Context, identity string) (snippet string, err error)
+	app.Component
+}
+
+type cacheEntry struct {
+	snippet   string
+	expiresAt time.Time
+}
+
+type analytics struct {
+	coll *mongo.Collection
+
+	cache map[string]cacheEntry
+	// sweptAt is the time of the last removal of expired entries, the cache keeps only recently served identities
+	sweptAt time.Time
+	mu      sync.Mutex
+}
+
+func (a *analytics) Name() (name string) {
+	return CName
+}
+
+func (a *analytics) Init(ap *app.App) (err error) {
+	a.coll = ap.MustComponent(db.CName).(db.Database).Db().Collection("analytics")
+	a.cache = make(map[string]cacheEntry)
+	return
+}
+
+func (a *analytics) Set(ctx context.Context, config domain.AnalyticsConfig) (err error) {
+	if config, err = Normalize(config); err != nil {
+		return
+	}
+	config.Timestamp = time.Now().Unix()
+	if _, err = a.coll.ReplaceOne(ctx, bson.D{{"_id", config.Identity}}, config, options.Replace().SetUpsert(true)); err != nil {
+		return
+	}
+	a.dropCache(config.Identity)
+	return
+}
+
+func (a *analytics) Get(ctx context.Context, identity string) (config domain.AnalyticsConfig, err error) {
+	if err = a.coll.FindOne(ctx, bson.D{{"_id", identity}}).Decode(&config); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.AnalyticsConfig{}, err
+	}
+	return
+}
+
+func (a *analytics) Delete(ctx context.Context, identity string) (err error) {
+	if _, err = a.coll.DeleteOne(ctx, bson.D{{"_id", identity}}); err != nil {
+		return
+	}
+	a.dropCache(identity)
+	return
+}
+
+func (a *analytics) Snippet(ctx context.Context, identity string) (snippet string, err error) {
+	a.mu.Lock()
+	entry, ok := a.cache[identity]
+	a.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.snippet, nil
+	}
+	config, err := a.Get(ctx, identity)
+	if err == nil {
+		if snippet, err = Snippet(config); err != nil {
+			return
+		}
+	} else if !errors.Is(err, publishapi.ErrNotFound) {
+		return
+	}
+	// identities without config are cached too
+	now := time.Now()
+	a.mu.Lock()
+	if now.Sub(a.sweptAt) > configCacheTTL {
+		a.sweep(now)
+	}
+	a.cache[identity] = cacheEntry{snippet: snippet, expiresAt: now.Add(configCacheTTL)}
+	a.mu.Unlock()
+	return snippet, nil
+}
+
+// sweep removes expired entries, it's called under the lock
+func (a *analytics) sweep(now time.Time) {
+	for identity, entry := range a.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(a.cache, identity)
+		}
+	}
+	a.sweptAt = now
+}
+
+func (a *analytics) dropCache(identity string) {
+	a.mu.Lock()
+	delete(a.cache, identity)
+	a.mu.Unlock()
+}
+
+// Normalize validates the config against the provider allowlist and returns it in the canonical form
+func Normalize(config domain.AnalyticsConfig) (domain.AnalyticsConfig, error) {
+	config.Provider = strings.ToLower(strings.TrimSpace(config.Provider))
+	config.SiteId = strings.TrimSpace(config.SiteId)
+	config.Host = strings.ToLower(strings.TrimSpace(config.Host))
+	p, ok := providers[config.Provider]
+	if !ok {
+		return config, fmt.Errorf("%w: unknown provider %q", publishapi.ErrInvalidAnalytics, config.Provider)
+	}
+	if config.Provider != ProviderGoogle {
+		config.SiteId = strings.ToLower(config.SiteId)
+	}
+	if !p.siteId.MatchString(config.SiteId) {
+		return config, fmt.Errorf("%w: invalid site id %q", publishapi.ErrInvalidAnalytics, config.SiteId)
+	}
+	if config.Host != "" {
+		if p.defaultHost == "" {
+			return config, fmt.Errorf("%w: provider %q doesn't support custom host", publishapi.ErrInvalidAnalytics, config.Provider)
+		}
+		if len(config.Host) > 253 || !hostRe.MatchString(config.Host) {
+			return config, fmt.Errorf("%w: invalid host %q", publishapi.ErrInvalidAnalytics, config.Host)
+		}
+	}
+	return config, nil
+}
+
+// Snippet renders the html snippet of the config
+func Snippet(config domain.AnalyticsConfig) (string, error) {
+	config, err := Normalize(config)
+	if err != nil {
+		return "", err
+	}
+	p := providers[config.Provider]
+	host := config.Host
+	if host == "" {
+		host = p.defaultHost
+	}
+	return p.snippet(html.EscapeString(host), html.EscapeString(config.SiteId)), nil
+}
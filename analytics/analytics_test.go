@@ -0,0 +1,73 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func TestSnippet(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config  domain.AnalyticsConfig
+		snippet string
+	}{
+		"plausible": {
+			config:  domain.AnalyticsConfig{Provider: "Plausible", SiteId: "Blog.Example.com"},
+			snippet: `<script defer data-domain="blog.example.com" src="https://plausible.io/js/script.js"></script>`,
+		},
+		"plausible self-hosted": {
+			config:  domain.AnalyticsConfig{Provider: "plausible", SiteId: "example.com", Host: "stats.example.com"},
+			snippet: `<script defer data-domain="example.com" src="https://stats.example.com/js/script.js"></script>`,
+		},
+		"umami": {
+			config:  domain.AnalyticsConfig{Provider: "umami", SiteId: "94db1cb1-74f4-4a40-ad6c-962362670409"},
+			snippet: `<script defer src="https://cloud.umami.is/script.js" data-website-id="94db1cb1-74f4-4a40-ad6c-962362670409"></script>`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			snippet, err := Snippet(tc.config)
+			require.NoError(t, err)
+			assert.Equal(t, tc.snippet, snippet)
+		})
+	}
+	t.Run("google", func(t *testing.T) {
+		snippet, err := Snippet(domain.AnalyticsConfig{Provider: "google", SiteId: "G-ABC123XYZ"})
+		require.NoError(t, err)
+		assert.Contains(t, snippet, `gtag/js?id=G-ABC123XYZ"`)
+		assert.Contains(t, snippet, `gtag('config','G-ABC123XYZ')`)
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	for name, config := range map[string]domain.AnalyticsConfig{
+		"unknown provider":   {Provider: "custom", SiteId: "example.com"},
+		"empty site id":      {Provider: "plausible"},
+		"html in site id":    {Provider: "plausible", SiteId: `a.com"><script>alert(1)</script>`},
+		"invalid umami id":   {Provider: "umami", SiteId: "123"},
+		"invalid google id":  {Provider: "google", SiteId: "UA-1234-1"},
+		"google custom host": {Provider: "google", SiteId: "G-ABC123XYZ", Host: "example.com"},
+		"invalid host":       {Provider: "umami", SiteId: "94db1cb1-74f4-4a40-ad6c-962362670409", Host: "evil.com/x"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Normalize(config)
+			require.ErrorIs(t, err, publishapi.ErrInvalidAnalytics)
+		})
+	}
+}
+
+func TestAnalytics_sweep(t *testing.T) {
+	now := time.Now()
+	a := &analytics{cache: map[string]cacheEntry{
+		"expired": {expiresAt: now.Add(-time.Second)},
+		"active":  {expiresAt: now.Add(time.Second)},
+	}}
+	a.sweep(now)
+	assert.NotContains(t, a.cache, "expired")
+	assert.Contains(t, a.cache, "active")
+	assert.Equal(t, now, a.sweptAt)
+}
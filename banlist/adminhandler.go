@@ -0,0 +1,55 @@
+package banlist
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func (b *banList) registerAdminHandlers(a admin.Admin) {
+	a.Handle("GET /admin/bans", http.HandlerFunc(b.handleList))
+	a.Handle("POST /admin/bans", http.HandlerFunc(b.handleBan))
+	a.Handle("DELETE /admin/bans/{kind}/{value}", http.HandlerFunc(b.handleUnban))
+}
+
+func (b *banList) handleList(w http.ResponseWriter, r *http.Request) {
+	bans, err := b.List(r.Context())
+	if err != nil {
+		admin.WriteErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, bans)
+}
+
+func (b *banList) handleBan(w http.ResponseWriter, r *http.Request) {
+	var ban domain.Ban
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&ban); err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := b.Ban(r.Context(), ban); err != nil {
+		if errors.Is(err, ErrInvalidBan) {
+			admin.WriteErr(w, http.StatusBadRequest, err)
+		} else {
+			admin.WriteErr(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (b *banList) handleUnban(w http.ResponseWriter, r *http.Request) {
+	if err := b.Unban(r.Context(), domain.BanKind(r.PathValue("kind")), r.PathValue("value")); err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			admin.WriteErr(w, http.StatusNotFound, err)
+		} else {
+			admin.WriteErr(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,256 @@
+// Package banlist keeps the operator ban list of identities, names and publishes.
+//
+// Bans are stored in mongo and kept in memory by every instance, so checks never hit the database.
+// Changes are announced over redis pub/sub and every instance reloads the list right away,
+// the periodic reload covers missed messages.
+package banlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/util/periodicsync"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/nameservice"
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/redisprovider"
+)
+
+const CName = "publish.banlist"
+
+var log = logger.NewNamed(CName)
+
+const (
+	changesChannel   = "banlist:changes"
+	reloadPeriodSec  = 60
+	reloadTimeoutSec = 30
+)
+
+var ErrInvalidBan = errors.New("invalid ban")
+
+func New() BanList {
+	return new(banList)
+}
+
+type BanList interface {
+	// Ban adds the ban, banned names are resolved to the owner identity
+	Ban(ctx context.Context, ban domain.Ban) (err error)
+	Unban(ctx context.Context, kind domain.BanKind, value string) (err error)
+	List(ctx context.Context) (bans []domain.Ban, err error)
+	// IsBanned checks the in-memory list, the identity is banned by its own ban or the ban of its name
+	IsBanned(kind domain.BanKind, value string) bool
+	// SetInvalidateCacheCallback sets the cache invalidation, withVersions drops the cached version permalinks too
+	SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool))
+	app.ComponentRunnable
+}
+
+type banList struct {
+	coll           *mongo.Collection
+	redisClient    redis.UniversalClient
+	nameService    nameservice.NameService
+	repo           publishrepo.PublishRepo
+	invalidateFunc func(identity, uri string, withVersions bool)
+	ticker         periodicsync.PeriodicSync
+	sub            *redis.PubSub
+
+	bans banSet
+	mu   sync.RWMutex
+}
+
+type banSet map[domain.BanKind]map[string]struct{}
+
+func (b *banList) Name() (name string) {
+	return CName
+}
+
+func (b *banList) Init(a *app.App) (err error) {
+	b.coll = a.MustComponent(db.CName).(db.Database).Db().Collection("bans")
+	b.redisClient = a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
+	b.nameService = a.MustComponent(nameservice.CName).(nameservice.NameService)
+	b.repo = a.MustComponent(publishrepo.CName).(publishrepo.PublishRepo)
+	b.registerAdminHandlers(a.MustComponent(admin.CName).(admin.Admin))
+	return
+}
+
+func (b *banList) Run(ctx context.Context) (err error) {
+	if err = b.reload(ctx); err != nil {
+		return
+	}
+	b.sub = b.redisClient.Subscribe(ctx, changesChannel)
+	// wait for the subscription, so changes made after Run are never missed
+	if _, err = b.sub.Receive(ctx); err != nil {
+		return
+	}
+	go b.listenChanges(b.sub.Channel())
+	b.ticker = periodicsync.NewPeriodicSync(reloadPeriodSec, reloadTimeoutSec*time.Second, b.reload, log)
+	b.ticker.Run()
+	return
+}
+
+func (b *banList) SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool)) {
+	b.invalidateFunc = f
+}
+
+func (b *banList) Ban(ctx context.Context, ban domain.Ban) (err error) {
+	if ban.Value == "" {
+		return fmt.Errorf("%w: empty value", ErrInvalidBan)
+	}
+	switch ban.Kind {
+	case domain.BanKindIdentity:
+	case domain.BanKindName:
+		resp, err := b.nameService.ResolveName(ctx, ban.Value)
+		if err != nil {
+			return err
+		}
+		if resp.OwnerAnyAddress == "" {
+			return fmt.Errorf("%w: name %q is not registered", ErrInvalidBan, ban.Value)
+		}
+		ban.Identity = resp.OwnerAnyAddress
+	case domain.BanKindPublish:
+		if _, err = primitive.ObjectIDFromHex(ban.Value); err != nil {
+			return fmt.Errorf("%w: invalid publish id", ErrInvalidBan)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidBan, ban.Kind)
+	}
+	ban.Id = banId(ban.Kind, ban.Value)
+	ban.Timestamp = time.Now().Unix()
+	if _, err = b.coll.ReplaceOne(ctx, bson.D{{"_id", ban.Id}}, ban, options.Replace().SetUpsert(true)); err != nil {
+		return
+	}
+	b.changed(ctx, ban.Kind, ban.Value)
+	return
+}
+
+func (b *banList) Unban(ctx context.Context, kind domain.BanKind, value string) (err error) {
+	res, err := b.coll.DeleteOne(ctx, bson.D{{"_id", banId(kind, value)}})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	b.changed(ctx, kind, value)
+	return
+}
+
+func (b *banList) List(ctx context.Context) (bans []domain.Ban, err error) {
+	cur, err := b.coll.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"timestamp", -1}}))
+	if err != nil {
+		return
+	}
+	bans = make([]domain.Ban, 0)
+	err = cur.All(ctx, &bans)
+	return
+}
+
+func (b *banList) IsBanned(kind domain.BanKind, value string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.bans.contains(kind, value)
+}
+
+// changed applies the change locally and announces it to other instances
+func (b *banList) changed(ctx context.Context, kind domain.BanKind, value string) {
+	if err := b.reload(ctx); err != nil {
+		log.Warn("reload error", zap.Error(err))
+	}
+	if err := b.redisClient.Publish(ctx, changesChannel, string(kind)).Err(); err != nil {
+		log.Warn("publish changes error", zap.Error(err))
+	}
+	// identities and names are checked before the page cache, banned publishes are rendered as the 451 page
+	if kind == domain.BanKindPublish {
+		b.invalidatePublish(ctx, value)
+	}
+}
+
+func (b *banList) invalidatePublish(ctx context.Context, publishId string) {
+	if b.invalidateFunc == nil {
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(publishId)
+	if err != nil {
+		return
+	}
+	pub, err := b.repo.GetPublish(ctx, id)
+	if err != nil {
+		if !errors.Is(err, publishapi.ErrNotFound) {
+			log.Warn("get publish error", zap.Error(err))
+		}
+		return
+	}
+	b.invalidateFunc(pub.Identity, pub.Uri, true)
+}
+
+func (b *banList) listenChanges(ch <-chan *redis.Message) {
+	for range ch {
+		ctx, cancel := context.WithTimeout(context.Background(), reloadTimeoutSec*time.Second)
+		if err := b.reload(ctx); err != nil {
+			log.Warn("reload error", zap.Error(err))
+		}
+		cancel()
+	}
+}
+
+func (b *banList) reload(ctx context.Context) (err error) {
+	bans, err := b.List(ctx)
+	if err != nil {
+		return
+	}
+	set := newBanSet(bans)
+	b.mu.Lock()
+	b.bans = set
+	b.mu.Unlock()
+	return
+}
+
+func (b *banList) Close(ctx context.Context) (err error) {
+	if b.ticker != nil {
+		b.ticker.Close()
+	}
+	if b.sub != nil {
+		err = b.sub.Close()
+	}
+	return
+}
+
+func newBanSet(bans []domain.Ban) banSet {
+	set := banSet{}
+	add := func(kind domain.BanKind, value string) {
+		if set[kind] == nil {
+			set[kind] = map[string]struct{}{}
+		}
+		set[kind][value] = struct{}{}
+	}
+	for _, ban := range bans {
+		add(ban.Kind, ban.Value)
+		if ban.Kind == domain.BanKindName && ban.Identity != "" {
+			add(domain.BanKindIdentity, ban.Identity)
+		}
+	}
+	return set
+}
+
+func (s banSet) contains(kind domain.BanKind, value string) bool {
+	_, ok := s[kind][value]
+	return ok
+}
+
+func banId(kind domain.BanKind, value string) string {
+	return string(kind) + ":" + value
+}
@@ -0,0 +1,25 @@
+package banlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func TestBanSet(t *testing.T) {
+	set := newBanSet([]domain.Ban{
+		{Kind: domain.BanKindIdentity, Value: "a1"},
+		{Kind: domain.BanKindName, Value: "bob", Identity: "a2"},
+		{Kind: domain.BanKindPublish, Value: "p1"},
+	})
+	assert.True(t, set.contains(domain.BanKindIdentity, "a1"))
+	assert.True(t, set.contains(domain.BanKindName, "bob"))
+	// the owner of the banned name is banned too
+	assert.True(t, set.contains(domain.BanKindIdentity, "a2"))
+	assert.True(t, set.contains(domain.BanKindPublish, "p1"))
+	assert.False(t, set.contains(domain.BanKindIdentity, "a3"))
+	assert.False(t, set.contains(domain.BanKindPublish, "a1"))
+	assert.False(t, banSet(nil).contains(domain.BanKindIdentity, "a1"))
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anyproto/anytype-publish-server/publishclient"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+type env struct {
+	client publishclient.Client
+	out    *printer
+}
+
+type command struct {
+	name  string
+	usage string
+	// init defines the command flags and returns the command func
+	init func(fs *flag.FlagSet) func(ctx context.Context, e *env) error
+}
+
+var commands = []command{
+	{"publish", "publish the directory as the object page", publishCmd},
+	{"status", "print the publish status of the object", statusCmd},
+	{"list", "list publishes of the account", listCmd},
+	{"unpublish", "remove the publish of the object", unpublishCmd},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func objectFlags(fs *flag.FlagSet) func() (spaceId, objectId string, err error) {
+	spaceId := fs.String("space", "", "space id")
+	objectId := fs.String("object", "", "object id")
+	return func() (string, string, error) {
+		if *spaceId == "" || *objectId == "" {
+			return "", "", errors.New("space and object are required")
+		}
+		return *spaceId, *objectId, nil
+	}
+}
+
+func publishCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	object := objectFlags(fs)
+	dir := fs.String("dir", "", "directory to upload")
+	uri := fs.String("uri", "", "page uri")
+	version := fs.String("version", "", "publish version, the current time by default")
+	static := fs.Bool("static", false, "publish the directory as a static site")
+	spa := fs.Bool("spa", false, "serve index.html for unknown paths of the static site")
+	visibility := fs.String("visibility", "public", "public, unlisted or private")
+	members := fs.String("members", "", "comma separated identities allowed to see the private page")
+	preview := fs.Bool("preview", false, "upload the preview instead of publishing")
+	unique := fs.Bool("unique", false, "add a numeric suffix to the uri taken by another object")
+	retries := fs.Int("retries", 3, "upload attempts, every attempt gets a fresh upload url")
+	uploadTimeout := fs.Duration("upload-timeout", 0, "timeout of a single upload, unlimited by default")
+	compress := fs.Bool("compress", true, "gzip the upload archive")
+	quiet := fs.Bool("q", false, "don't print the upload progress")
+	return func(ctx context.Context, e *env) error {
+		spaceId, objectId, err := object()
+		if err != nil {
+			return err
+		}
+		if *dir == "" || *uri == "" {
+			return errors.New("dir and uri are required")
+		}
+		opts := publishclient.PublishOptions{
+			SpaceId:        spaceId,
+			ObjectId:       objectId,
+			Uri:            *uri,
+			Version:        *version,
+			SpaFallback:    *spa,
+			Preview:        *preview,
+			Dir:            *dir,
+			UniqueUri:      *unique,
+			UploadAttempts: *retries,
+		}
+		if !*compress {
+			opts.UploadOptions = append(opts.UploadOptions, publishclient.WithCompression(publishclient.CompressionNone))
+		}
+		if *uploadTimeout > 0 {
+			opts.UploadOptions = append(opts.UploadOptions, publishclient.WithTimeout(*uploadTimeout))
+		}
+		if opts.Version == "" {
+			opts.Version = time.Now().UTC().Format("20060102150405")
+		}
+		if *static {
+			opts.Type = publishapi.PublishType_PublishTypeStatic
+		}
+		if opts.Visibility, err = parseVisibility(*visibility); err != nil {
+			return err
+		}
+		if *members != "" {
+			opts.Members = strings.Split(*members, ",")
+		}
+		if !*quiet {
+			progress := newProgressPrinter(e.out.progress)
+			defer progress.done()
+			opts.UploadOptions = append(opts.UploadOptions, publishclient.WithProgress(progress.report))
+		}
+		res, err := e.client.PublishDir(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return e.out.result(res)
+	}
+}
+
+func statusCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	object := objectFlags(fs)
+	return func(ctx context.Context, e *env) error {
+		spaceId, objectId, err := object()
+		if err != nil {
+			return err
+		}
+		pub, err := e.client.GetPublishStatus(ctx, spaceId, objectId)
+		if err != nil {
+			return err
+		}
+		return e.out.publish(pub)
+	}
+}
+
+func listCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	spaceId := fs.String("space", "", "space id, all spaces by default")
+	return func(ctx context.Context, e *env) error {
+		list, err := e.client.ListPublishes(ctx, *spaceId)
+		if err != nil {
+			return err
+		}
+		return e.out.publishes(list)
+	}
+}
+
+func unpublishCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	object := objectFlags(fs)
+	return func(ctx context.Context, e *env) error {
+		spaceId, objectId, err := object()
+		if err != nil {
+			return err
+		}
+		return e.client.UnPublish(ctx, &publishapi.UnPublishRequest{SpaceId: spaceId, ObjectId: objectId})
+	}
+}
+
+func parseVisibility(s string) (publishapi.Visibility, error) {
+	switch s {
+	case "public":
+		return publishapi.Visibility_VisibilityPublic, nil
+	case "unlisted":
+		return publishapi.Visibility_VisibilityUnlisted, nil
+	case "private":
+		return publishapi.Visibility_VisibilityPrivate, nil
+	}
+	return 0, fmt.Errorf("unknown visibility %q", s)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/anyproto/any-sync/accountservice"
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/anyproto/any-sync/net/rpc"
+	"github.com/anyproto/any-sync/net/secureservice"
+	"github.com/anyproto/any-sync/net/transport/quic"
+	"github.com/anyproto/any-sync/net/transport/yamux"
+	"github.com/anyproto/any-sync/nodeconf"
+	"github.com/anyproto/any-sync/util/crypto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/anyproto/anytype-publish-server/publishclient"
+)
+
+// keyEnv holds the account signing key when the key file is not set
+const keyEnv = "ANYPUBLISH_KEY"
+
+// Config is the client config, the account is not stored in the file and is made from the signing key
+type Config struct {
+	Account          accountservice.Config  `yaml:"-"`
+	Drpc             rpc.Config             `yaml:"drpc"`
+	Yamux            yamux.Config           `yaml:"yamux"`
+	Quic             quic.Config            `yaml:"quic"`
+	Network          nodeconf.Configuration `yaml:"network"`
+	NetworkStorePath string                 `yaml:"networkStorePath"`
+	PublishServer    publishclient.Config   `yaml:",inline"`
+}
+
+func newConfigFromFile(path string) (c *Config, err error) {
+	c = &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// setAccount makes the account from the signing key, the peer key is generated for every run
+func (c *Config) setAccount(signingKey string) (err error) {
+	signingKey = strings.TrimSpace(signingKey)
+	if signingKey == "" {
+		return errors.New("account key is not set")
+	}
+	if _, err = crypto.DecodeKeyFromString(signingKey, crypto.UnmarshalEd25519PrivateKey, nil); err != nil {
+		return fmt.Errorf("invalid account key: %w", err)
+	}
+	peerKey, peerPubKey, err := crypto.GenerateRandomEd25519KeyPair()
+	if err != nil {
+		return err
+	}
+	encodedPeerKey, err := crypto.EncodeKeyToString(peerKey)
+	if err != nil {
+		return err
+	}
+	c.Account = accountservice.Config{
+		PeerId:     peerPubKey.PeerId(),
+		PeerKey:    encodedPeerKey,
+		SigningKey: signingKey,
+	}
+	return nil
+}
+
+// readKey reads the signing key from the file or from the environment
+func readKey(path string) (string, error) {
+	if path == "" {
+		return os.Getenv(keyEnv), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *Config) Init(a *app.App) (err error) {
+	return nil
+}
+
+func (c *Config) Name() (name string) {
+	return "config"
+}
+
+func (c *Config) GetAccount() accountservice.Config {
+	return c.Account
+}
+
+func (c *Config) GetDrpc() rpc.Config {
+	return c.Drpc
+}
+
+func (c *Config) GetYamux() yamux.Config {
+	return c.Yamux
+}
+
+func (c *Config) GetQuic() quic.Config {
+	return c.Quic
+}
+
+func (c *Config) GetNodeConf() nodeconf.Configuration {
+	return c.Network
+}
+
+func (c *Config) GetNodeConfStorePath() string {
+	return c.NetworkStorePath
+}
+
+func (c *Config) GetSecureService() secureservice.Config {
+	return secureservice.Config{}
+}
+
+func (c *Config) GetMetric() metric.Config {
+	return metric.Config{}
+}
+
+func (c *Config) GetPublishServer() publishclient.Config {
+	return c.PublishServer
+}
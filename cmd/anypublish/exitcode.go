@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/anyproto/anytype-publish-server/publishclient"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+const (
+	exitOk      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// exitCodes maps the publish api errors to exit codes, the first matching error wins
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{publishapi.ErrNotFound, 3},
+	{publishapi.ErrAccessDenied, 4},
+	{publishapi.ErrUriNotUnique, 5},
+	{publishapi.ErrDomainNotUnique, 6},
+	{publishapi.ErrDomainNotVerified, 7},
+	{publishapi.ErrInvalidDomain, 8},
+	{publishapi.ErrInvalidAnalytics, 9},
+	{publishclient.ErrVerifyFailed, 10},
+	{context.DeadlineExceeded, 11},
+	{publishclient.ErrUploadLimitExceeded, 12},
+	{publishclient.ErrInvalidUpload, 13},
+	{publishclient.ErrUploadUrlExpired, 14},
+	{publishclient.ErrUploadUrlUsed, 15},
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOk
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return exitFailure
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/anyproto/anytype-publish-server/publishclient"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitOk, exitCode(nil))
+	assert.Equal(t, exitFailure, exitCode(errors.New("some error")))
+	assert.Equal(t, 3, exitCode(publishapi.ErrNotFound))
+	assert.Equal(t, 4, exitCode(fmt.Errorf("publish: %w", publishapi.ErrAccessDenied)))
+	assert.Equal(t, 5, exitCode(publishapi.ErrUriNotUnique))
+	assert.Equal(t, 10, exitCode(fmt.Errorf("%w: size 1, uploaded 2", publishclient.ErrVerifyFailed)))
+	assert.Equal(t, 11, exitCode(context.DeadlineExceeded))
+	assert.Equal(t, 12, exitCode(&publishclient.UploadError{StatusCode: http.StatusRequestEntityTooLarge}))
+	assert.Equal(t, 14, exitCode(&publishclient.UploadError{StatusCode: http.StatusGone}))
+	assert.Equal(t, 15, exitCode(&publishclient.UploadError{StatusCode: http.StatusConflict}))
+}
+
+func TestFormatProgress(t *testing.T) {
+	assert.Equal(t, "uploading 1/4 files, 512 B/2.0 KiB (25%)", formatProgress(publishclient.UploadProgress{Files: 1, TotalFiles: 4, Bytes: 512, TotalBytes: 2048}))
+	assert.Equal(t, "uploading 0/0 files, 0 B/0 B (100%)", formatProgress(publishclient.UploadProgress{}))
+}
@@ -0,0 +1,139 @@
+// Command anypublish publishes directories to the publish server with the anytype account key.
+//
+// The account signing key is read from the -key file or from the ANYPUBLISH_KEY environment variable,
+// the exit code of the failed command tells the publish api error, see exitCode.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/coordinator/coordinatorclient"
+	"github.com/anyproto/any-sync/coordinator/nodeconfsource"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/anyproto/any-sync/net/peerservice"
+	"github.com/anyproto/any-sync/net/pool"
+	"github.com/anyproto/any-sync/net/rpc/server"
+	"github.com/anyproto/any-sync/net/secureservice"
+	"github.com/anyproto/any-sync/net/transport/quic"
+	"github.com/anyproto/any-sync/net/transport/yamux"
+	"github.com/anyproto/any-sync/nodeconf"
+	"github.com/anyproto/any-sync/nodeconf/nodeconfstore"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/account"
+	"github.com/anyproto/anytype-publish-server/publishclient"
+)
+
+var log = logger.NewNamed("anypublish")
+
+var (
+	flagConfigFile = flag.String("c", "etc/anypublish.yml", "path to config file")
+	flagKeyFile    = flag.String("key", "", "path to the file with the account signing key, "+keyEnv+" is used by default")
+	flagJSON       = flag.Bool("json", false, "print json instead of tables")
+	flagVerbose    = flag.Bool("v", false, "print info logs")
+	flagTimeout    = flag.Duration("timeout", 30*time.Minute, "command timeout")
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage: anypublish [flags] <command> [command flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	_, _ = fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+	_, _ = fmt.Fprintf(out, "\nExit codes:\n")
+	for _, c := range exitCodes {
+		_, _ = fmt.Fprintf(out, "  %d  %s\n", c.code, c.err)
+	}
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		return exitUsage
+	}
+	cmd := findCommand(flag.Arg(0))
+	if cmd == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		usage()
+		return exitUsage
+	}
+	cmdFlags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	runCmd := cmd.init(cmdFlags)
+	_ = cmdFlags.Parse(flag.Args()[1:])
+
+	logLevel := "warn"
+	if *flagVerbose {
+		logLevel = "info"
+	}
+	logger.Config{DefaultLevel: logLevel, Format: logger.PlaintextOutput}.ApplyGlobal()
+
+	conf, err := newConfigFromFile(*flagConfigFile)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "can't open config file:", err)
+		return exitUsage
+	}
+	key, err := readKey(*flagKeyFile)
+	if err == nil {
+		err = conf.setAccount(key)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "can't load the account key:", err)
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flagTimeout)
+	defer cancel()
+	a := new(app.App)
+	a.Register(conf)
+	Bootstrap(a)
+	if err = a.Start(ctx); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "can't start app:", err)
+		return exitFailure
+	}
+
+	cmdErr := runCmd(ctx, &env{
+		client: a.MustComponent(publishclient.CName).(publishclient.Client),
+		out:    newPrinter(os.Stdout, *flagJSON),
+	})
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer closeCancel()
+	if err = a.Close(closeCtx); err != nil {
+		log.Warn("close error", zap.Error(err))
+	}
+	if cmdErr != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", cmdErr)
+	}
+	return exitCode(cmdErr)
+}
+
+// Bootstrap registers the network components and the publish client
+func Bootstrap(a *app.App) {
+	a.Register(metric.New()).
+		Register(server.New()).
+		Register(account.New()).
+		Register(pool.New()).
+		Register(peerservice.New()).
+		Register(coordinatorclient.New()).
+		Register(nodeconfsource.New()).
+		Register(nodeconfstore.New()).
+		Register(nodeconf.New()).
+		Register(secureservice.New()).
+		Register(publishclient.New()).
+		Register(quic.New()).
+		Register(yamux.New())
+}
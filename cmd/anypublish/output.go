@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/anyproto/anytype-publish-server/publishclient"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+// printer writes results as aligned tables or as json, the progress goes to a separate writer
+type printer struct {
+	w        io.Writer
+	progress io.Writer
+	json     bool
+}
+
+func newPrinter(w io.Writer, json bool) *printer {
+	return &printer{w: w, progress: os.Stderr, json: json}
+}
+
+func (p *printer) publishes(list []*publishapi.Publish) error {
+	if p.json {
+		return p.writeJSON(list)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(publishHeader, "\t"))
+	for _, pub := range list {
+		_, _ = fmt.Fprintln(tw, strings.Join(publishRow(pub), "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) publish(pub *publishapi.Publish) error {
+	if p.json {
+		return p.writeJSON(pub)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	row := publishRow(pub)
+	for i := range publishHeader {
+		_, _ = fmt.Fprintf(tw, "%s:\t%s\n", strings.ToLower(publishHeader[i]), row[i])
+	}
+	if len(pub.Members) > 0 {
+		_, _ = fmt.Fprintf(tw, "members:\t%s\n", strings.Join(pub.Members, ", "))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) result(res publishclient.PublishResult) error {
+	if p.json {
+		return p.writeJSON(res)
+	}
+	_, _ = fmt.Fprintf(p.w, "url: %s\n", res.Url)
+	if res.Publish == nil {
+		return nil
+	}
+	return p.publish(res.Publish)
+}
+
+func (p *printer) writeJSON(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+var publishHeader = []string{"URI", "SPACE", "OBJECT", "VISIBILITY", "STATUS", "VERSION", "SIZE", "UPDATED"}
+
+func publishRow(pub *publishapi.Publish) []string {
+	return []string{
+		pub.Uri,
+		pub.SpaceId,
+		pub.ObjectId,
+		strings.TrimPrefix(pub.Visibility.String(), "Visibility"),
+		strings.TrimPrefix(pub.Status.String(), "PublishStatus"),
+		pub.Version,
+		formatSize(pub.Size),
+		formatTime(pub.Timestamp),
+	}
+}
+
+// progressInterval limits how often the progress line is redrawn
+const progressInterval = 200 * time.Millisecond
+
+// progressPrinter redraws the single progress line
+type progressPrinter struct {
+	w       io.Writer
+	printed time.Time
+}
+
+func newProgressPrinter(w io.Writer) *progressPrinter {
+	return &progressPrinter{w: w}
+}
+
+func (p *progressPrinter) report(progress publishclient.UploadProgress) {
+	complete := progress.Files == progress.TotalFiles && progress.Bytes == progress.TotalBytes
+	if !complete && time.Since(p.printed) < progressInterval {
+		return
+	}
+	p.printed = time.Now()
+	_, _ = fmt.Fprintf(p.w, "\r%s", formatProgress(progress))
+}
+
+// done ends the progress line
+func (p *progressPrinter) done() {
+	if !p.printed.IsZero() {
+		_, _ = fmt.Fprintln(p.w)
+	}
+}
+
+func formatProgress(p publishclient.UploadProgress) string {
+	percent := 100
+	if p.TotalBytes > 0 {
+		percent = int(p.Bytes * 100 / p.TotalBytes)
+	}
+	line := fmt.Sprintf("uploading %d/%d files, %s/%s (%d%%)", p.Files, p.TotalFiles, formatSize(p.Bytes), formatSize(p.TotalBytes), percent)
+	if p.Attempt > 1 {
+		line += fmt.Sprintf(", attempt %d", p.Attempt)
+	}
+	return line
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func formatTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
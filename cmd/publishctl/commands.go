@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/moderation"
+	"github.com/anyproto/anytype-publish-server/nameservice"
+	"github.com/anyproto/anytype-publish-server/publish"
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/store"
+)
+
+type env struct {
+	a   *app.App
+	out *printer
+}
+
+func (e *env) repo() publishrepo.PublishRepo {
+	return e.a.MustComponent(publishrepo.CName).(publishrepo.PublishRepo)
+}
+
+// resolveIdentity returns the identity flag value or the owner of the name
+func (e *env) resolveIdentity(ctx context.Context, identity, name string) (string, error) {
+	if identity != "" {
+		return identity, nil
+	}
+	if name == "" {
+		return "", errors.New("identity or name is required")
+	}
+	resp, err := e.a.MustComponent(nameservice.CName).(nameservice.NameService).ResolveName(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolve name: %w", err)
+	}
+	if resp.OwnerAnyAddress == "" {
+		return "", fmt.Errorf("name %q is not registered", name)
+	}
+	return resp.OwnerAnyAddress, nil
+}
+
+type command struct {
+	name  string
+	usage string
+	// init defines the command flags and returns the command func
+	init func(fs *flag.FlagSet) func(ctx context.Context, e *env) error
+}
+
+var commands = []command{
+	{"list", "list publishes of the identity or the name", listCmd},
+	{"get", "inspect the publish by id or by identity/name and uri", getCmd},
+	{"cleanup", "run the cleanup once, -dry-run only counts what would be deleted", cleanupCmd},
+	{"reconcile", "find storage paths without publishes, -delete removes them", reconcileCmd},
+	{"takedown", "take the object down", takedownCmd},
+	{"restore", "remove the takedown of the object", restoreCmd},
+	{"export", "export objects and active publishes as json lines", exportCmd},
+	{"usage", "print storage usage of the identity or the whole server", usageCmd},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func listCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	identity := fs.String("identity", "", "owner identity")
+	name := fs.String("name", "", "owner name")
+	spaceId := fs.String("space", "", "space id, all spaces by default")
+	return func(ctx context.Context, e *env) error {
+		id, err := e.resolveIdentity(ctx, *identity, *name)
+		if err != nil {
+			return err
+		}
+		list, err := e.repo().ListPublishes(ctx, id, *spaceId)
+		if err != nil {
+			return err
+		}
+		return e.out.publishes(list)
+	}
+}
+
+func getCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	publishId := fs.String("publish", "", "publish id")
+	identity := fs.String("identity", "", "owner identity")
+	name := fs.String("name", "", "owner name")
+	uri := fs.String("uri", "", "page uri")
+	return func(ctx context.Context, e *env) error {
+		if *publishId != "" {
+			id, err := primitive.ObjectIDFromHex(*publishId)
+			if err != nil {
+				return fmt.Errorf("invalid publish id: %w", err)
+			}
+			pub, err := e.repo().GetPublish(ctx, id)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return publishapi.ErrNotFound
+			} else if err != nil {
+				return err
+			}
+			return e.out.publish(pub)
+		}
+		if *uri == "" {
+			return errors.New("publish id or uri is required")
+		}
+		id, err := e.resolveIdentity(ctx, *identity, *name)
+		if err != nil {
+			return err
+		}
+		pub, err := e.repo().ResolveUri(ctx, id, *uri)
+		if err != nil {
+			return err
+		}
+		return e.out.publish(pub)
+	}
+}
+
+func cleanupCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	dryRun := fs.Bool("dry-run", false, "count documents without deleting them")
+	return func(ctx context.Context, e *env) error {
+		report, err := e.a.MustComponent(publish.CName).(publish.Service).RunCleanup(ctx, *dryRun)
+		if err != nil {
+			return err
+		}
+		return e.out.keyValues(report, [][2]string{
+			{"outdated publishes", strconv.Itoa(report.OutdatedPublishes)},
+			{"stale uploads", strconv.Itoa(report.StaleUploads)},
+			{"outdated objects", strconv.Itoa(report.OutdatedObjects)},
+			{"outdated versions", strconv.Itoa(report.OutdatedVersions)},
+			{"expired previews", strconv.Itoa(report.ExpiredPreviews)},
+			{"deleted publishes", strconv.Itoa(report.DeletedPublishes)},
+		})
+	}
+}
+
+type orphanPath struct {
+	Path    string `json:"path"`
+	Deleted bool   `json:"deleted"`
+}
+
+func reconcileCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	deleteOrphans := fs.Bool("delete", false, "delete files of paths without publishes")
+	return func(ctx context.Context, e *env) error {
+		st := e.a.MustComponent(store.CName).(store.Store)
+		repo := e.repo()
+		var orphans []orphanPath
+		err := st.ListPrefixes(ctx, func(prefix string) error {
+			id, err := primitive.ObjectIDFromHex(prefix)
+			if err != nil {
+				// not a publish path, never deleted
+				return nil
+			}
+			ok, err := repo.HasPublish(ctx, id)
+			if err != nil || ok {
+				return err
+			}
+			orphan := orphanPath{Path: prefix}
+			if *deleteOrphans {
+				if err = st.DeletePath(ctx, prefix+"/"); err != nil {
+					return fmt.Errorf("delete %s: %w", prefix, err)
+				}
+				orphan.Deleted = true
+			}
+			orphans = append(orphans, orphan)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(orphans))
+		for i, orphan := range orphans {
+			rows[i] = []string{orphan.Path, strconv.FormatBool(orphan.Deleted)}
+		}
+		return e.out.table(orphans, []string{"PATH", "DELETED"}, rows)
+	}
+}
+
+func objectFlags(fs *flag.FlagSet) func() (domain.Object, error) {
+	identity := fs.String("identity", "", "owner identity")
+	spaceId := fs.String("space", "", "space id")
+	objectId := fs.String("object", "", "object id")
+	return func() (domain.Object, error) {
+		if *identity == "" || *spaceId == "" || *objectId == "" {
+			return domain.Object{}, errors.New("identity, space and object are required")
+		}
+		return domain.Object{Identity: *identity, SpaceId: *spaceId, ObjectId: *objectId}, nil
+	}
+}
+
+func takedownCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	object := objectFlags(fs)
+	status := fs.Int("status", http.StatusUnavailableForLegalReasons, "http status of the page: 451 or 410")
+	reason := fs.String("reason", "", "takedown reason")
+	return func(ctx context.Context, e *env) error {
+		obj, err := object()
+		if err != nil {
+			return err
+		}
+		return e.a.MustComponent(moderation.CName).(moderation.Moderation).Takedown(ctx, domain.Takedown{
+			Identity: obj.Identity,
+			SpaceId:  obj.SpaceId,
+			ObjectId: obj.ObjectId,
+			Status:   *status,
+			Reason:   *reason,
+		})
+	}
+}
+
+func restoreCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	object := objectFlags(fs)
+	return func(ctx context.Context, e *env) error {
+		obj, err := object()
+		if err != nil {
+			return err
+		}
+		return e.a.MustComponent(moderation.CName).(moderation.Moderation).RemoveTakedown(ctx, obj)
+	}
+}
+
+func exportCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	identity := fs.String("identity", "", "owner identity, all identities by default")
+	name := fs.String("name", "", "owner name")
+	return func(ctx context.Context, e *env) error {
+		id := *identity
+		if *name != "" {
+			var err error
+			if id, err = e.resolveIdentity(ctx, "", *name); err != nil {
+				return err
+			}
+		}
+		return e.repo().IterateObjects(ctx, id, e.out.jsonLine)
+	}
+}
+
+func usageCmd(fs *flag.FlagSet) func(ctx context.Context, e *env) error {
+	identity := fs.String("identity", "", "owner identity, the whole server by default")
+	name := fs.String("name", "", "owner name")
+	return func(ctx context.Context, e *env) error {
+		id := *identity
+		if *name != "" {
+			var err error
+			if id, err = e.resolveIdentity(ctx, "", *name); err != nil {
+				return err
+			}
+		}
+		usage, err := e.repo().StorageUsage(ctx, id)
+		if err != nil {
+			return err
+		}
+		return e.out.keyValues(usage, [][2]string{
+			{"objects", strconv.FormatInt(usage.Objects, 10)},
+			{"publishes", strconv.FormatInt(usage.Publishes, 10)},
+			{"size", formatSize(usage.Size)},
+		})
+	}
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func formatTime(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
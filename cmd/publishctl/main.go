@@ -0,0 +1,168 @@
+// Command publishctl is the operators tool working with the publish server database and storage.
+//
+// It loads the server config and starts the same components with all listeners disabled,
+// so it may run next to the server. Cache invalidations are delivered to running gateways via redis.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/coordinator/coordinatorclient"
+	"github.com/anyproto/any-sync/coordinator/nodeconfsource"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/anyproto/any-sync/nameservice/nameserviceclient"
+	"github.com/anyproto/any-sync/net/peerservice"
+	"github.com/anyproto/any-sync/net/pool"
+	"github.com/anyproto/any-sync/net/rpc/server"
+	"github.com/anyproto/any-sync/net/secureservice"
+	"github.com/anyproto/any-sync/net/transport/quic"
+	"github.com/anyproto/any-sync/net/transport/yamux"
+	"github.com/anyproto/any-sync/nodeconf"
+	"github.com/anyproto/any-sync/nodeconf/nodeconfstore"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/account"
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/analytics"
+	"github.com/anyproto/anytype-publish-server/banlist"
+	"github.com/anyproto/anytype-publish-server/config"
+	"github.com/anyproto/anytype-publish-server/customdomain"
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/gateway"
+	"github.com/anyproto/anytype-publish-server/moderation"
+	"github.com/anyproto/anytype-publish-server/nameservice"
+	"github.com/anyproto/anytype-publish-server/publish"
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/redisprovider"
+	"github.com/anyproto/anytype-publish-server/stats"
+	"github.com/anyproto/anytype-publish-server/store"
+)
+
+var log = logger.NewNamed("publish.ctl")
+
+var (
+	flagConfigFile = flag.String("c", "etc/anytype-publish-server.yml", "path to config file")
+	flagJSON       = flag.Bool("json", false, "print json instead of tables")
+	flagVerbose    = flag.Bool("v", false, "print info logs")
+	flagTimeout    = flag.Duration("timeout", 10*time.Minute, "command timeout")
+)
+
+func usage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage: publishctl [flags] <command> [command flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
+	_, _ = fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd := findCommand(flag.Arg(0))
+	if cmd == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	cmdFlags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	run := cmd.init(cmdFlags)
+	_ = cmdFlags.Parse(flag.Args()[1:])
+
+	logLevel := "warn"
+	if *flagVerbose {
+		logLevel = "info"
+	}
+	logger.Config{DefaultLevel: logLevel, Format: logger.PlaintextOutput}.ApplyGlobal()
+
+	conf, err := config.NewFromFile(*flagConfigFile)
+	if err != nil {
+		log.Fatal("can't open config file", zap.Error(err))
+	}
+	disableListeners(conf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flagTimeout)
+	defer cancel()
+	a := new(app.App)
+	a.Register(conf)
+	Bootstrap(a)
+	if err = a.Start(ctx); err != nil {
+		log.Fatal("can't start app", zap.Error(err))
+	}
+	setInvalidateCallbacks(ctx, a)
+
+	cmdErr := run(ctx, &env{a: a, out: newPrinter(os.Stdout, *flagJSON)})
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Minute)
+	defer closeCancel()
+	if err = a.Close(closeCtx); err != nil {
+		log.Warn("close error", zap.Error(err))
+	}
+	if cmdErr != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "error:", cmdErr)
+		os.Exit(1)
+	}
+}
+
+// disableListeners keeps the tool from binding the server ports and running the periodic cleanup
+func disableListeners(conf *config.Config) {
+	conf.Yamux.ListenAddrs = nil
+	conf.Quic.ListenAddrs = nil
+	conf.Metric.Addr = ""
+	conf.Admin.Addr = ""
+	conf.Publish.HttpApiAddr = ""
+	conf.Publish.CleanupOn = false
+}
+
+// setInvalidateCallbacks forwards cache invalidations to running gateways
+func setInvalidateCallbacks(ctx context.Context, a *app.App) {
+	redisClient := a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
+	invalidate := func(identity, uri string, withVersions bool) {
+		if err := gateway.NotifyInvalidate(ctx, redisClient, identity, uri, withVersions); err != nil {
+			log.Warn("can't notify gateways to invalidate the cache", zap.Error(err))
+		}
+	}
+	a.MustComponent(publish.CName).(publish.Service).SetInvalidateCacheCallback(invalidate)
+	a.MustComponent(moderation.CName).(moderation.Moderation).SetInvalidateCacheCallback(invalidate)
+	a.MustComponent(banlist.CName).(banlist.BanList).SetInvalidateCacheCallback(invalidate)
+}
+
+// Bootstrap registers the server components except the gateway
+func Bootstrap(a *app.App) {
+	a.Register(db.New()).
+		Register(metric.New()).
+		Register(server.New()).
+		Register(account.New()).
+		Register(pool.New()).
+		Register(redisprovider.New()).
+		Register(peerservice.New()).
+		Register(coordinatorclient.New()).
+		Register(nameserviceclient.New()).
+		Register(nameservice.New()).
+		Register(nodeconfsource.New()).
+		Register(nodeconfstore.New()).
+		Register(nodeconf.New()).
+		Register(secureservice.New()).
+		Register(store.New()).
+		Register(publishrepo.New()).
+		Register(customdomain.New()).
+		Register(stats.New()).
+		Register(analytics.New()).
+		Register(admin.New()).
+		Register(moderation.New()).
+		Register(banlist.New()).
+		Register(publish.New()).
+		Register(quic.New()).
+		Register(yamux.New())
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+// printer writes results as aligned tables or as json
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, json bool) *printer {
+	return &printer{w: w, json: json}
+}
+
+// table prints rows under the header, v is printed instead in the json mode
+func (p *printer) table(v any, header []string, rows [][]string) error {
+	if p.json {
+		return p.writeJSON(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// keyValues prints the key-value pairs one per line, v is printed instead in the json mode
+func (p *printer) keyValues(v any, pairs [][2]string) error {
+	if p.json {
+		return p.writeJSON(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, pair := range pairs {
+		_, _ = fmt.Fprintf(tw, "%s:\t%s\n", pair[0], pair[1])
+	}
+	return tw.Flush()
+}
+
+func (p *printer) publishes(list []domain.ObjectWithPublish) error {
+	rows := make([][]string, len(list))
+	for i, pub := range list {
+		rows[i] = publishRow(pub)
+	}
+	return p.table(list, publishHeader, rows)
+}
+
+func (p *printer) publish(pub domain.ObjectWithPublish) error {
+	row := publishRow(pub)
+	pairs := make([][2]string, len(publishHeader))
+	for i := range publishHeader {
+		pairs[i] = [2]string{strings.ToLower(publishHeader[i]), row[i]}
+	}
+	if pub.Publish != nil && len(pub.Publish.Members) > 0 {
+		pairs = append(pairs, [2]string{"members", strings.Join(pub.Publish.Members, ", ")})
+	}
+	return p.keyValues(pub, pairs)
+}
+
+// jsonLine writes the value as a single json line regardless of the mode
+func (p *printer) jsonLine(v domain.ObjectWithPublish) error {
+	return json.NewEncoder(p.w).Encode(v)
+}
+
+func (p *printer) writeJSON(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+var publishHeader = []string{"URI", "IDENTITY", "SPACE", "OBJECT", "VISIBILITY", "PUBLISH", "STATUS", "VERSION", "SIZE", "UPDATED"}
+
+func publishRow(pub domain.ObjectWithPublish) []string {
+	row := []string{pub.Uri, pub.Identity, pub.SpaceId, pub.ObjectId, visibilityName(pub.Visibility), "", "", "", "", formatTime(pub.Timestamp)}
+	if pub.Publish != nil {
+		row[5] = pub.Publish.Id.Hex()
+		row[6] = statusName(pub.Publish.Status)
+		row[7] = pub.Publish.Version
+		row[8] = formatSize(pub.Publish.Size)
+	}
+	return row
+}
+
+func visibilityName(v domain.Visibility) string {
+	switch v {
+	case domain.VisibilityPublic:
+		return "public"
+	case domain.VisibilityUnlisted:
+		return "unlisted"
+	case domain.VisibilityPrivate:
+		return "private"
+	}
+	return strconv.Itoa(int(v))
+}
+
+func statusName(s domain.PublishStatus) string {
+	switch s {
+	case domain.PublishStatusCreated:
+		return "created"
+	case domain.PublishStatusPublished:
+		return "published"
+	case domain.PublishStatusReadyToDelete:
+		return "readyToDelete"
+	case domain.PublishStatusArchived:
+		return "archived"
+	case domain.PublishStatusPreview:
+		return "preview"
+	case domain.PublishStatusUploading:
+		return "uploading"
+	}
+	return strconv.Itoa(int(s))
+}
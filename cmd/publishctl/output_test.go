@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.0 KiB", formatSize(1024))
+	assert.Equal(t, "1.5 MiB", formatSize(3<<19))
+	assert.Equal(t, "2.0 GiB", formatSize(2<<30))
+}
+
+func TestPrinter(t *testing.T) {
+	list := []domain.ObjectWithPublish{{
+		Object:  domain.Object{Identity: "a1", SpaceId: "s1", ObjectId: "o1", Uri: "u1"},
+		Publish: &domain.Publish{Version: "v1", Status: domain.PublishStatusPublished, Size: 2048},
+	}}
+	var buf bytes.Buffer
+	require.NoError(t, newPrinter(&buf, false).publishes(list))
+	assert.Contains(t, buf.String(), "URI")
+	assert.Contains(t, buf.String(), "published")
+	assert.Contains(t, buf.String(), "2.0 KiB")
+
+	buf.Reset()
+	require.NoError(t, newPrinter(&buf, true).publishes(list))
+	assert.Contains(t, buf.String(), `"uri": "u1"`)
+}
@@ -27,13 +27,20 @@ import (
 	"github.com/anyproto/any-sync/nodeconf/nodeconfstore"
 
 	"github.com/anyproto/anytype-publish-server/account"
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/analytics"
+	"github.com/anyproto/anytype-publish-server/banlist"
 	"github.com/anyproto/anytype-publish-server/config"
+	"github.com/anyproto/anytype-publish-server/customdomain"
 	"github.com/anyproto/anytype-publish-server/db"
 	"github.com/anyproto/anytype-publish-server/gateway"
+	"github.com/anyproto/anytype-publish-server/gateway/certstore"
+	"github.com/anyproto/anytype-publish-server/moderation"
 	"github.com/anyproto/anytype-publish-server/nameservice"
 	"github.com/anyproto/anytype-publish-server/publish"
 	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
 	"github.com/anyproto/anytype-publish-server/redisprovider"
+	"github.com/anyproto/anytype-publish-server/stats"
 	"github.com/anyproto/anytype-publish-server/store"
 
 	// import this to keep govvv in go.mod on mod tidy
@@ -123,6 +130,13 @@ func Bootstrap(a *app.App) {
 		Register(secureservice.New()).
 		Register(store.New()).
 		Register(publishrepo.New()).
+		Register(customdomain.New()).
+		Register(stats.New()).
+		Register(analytics.New()).
+		Register(admin.New()).
+		Register(moderation.New()).
+		Register(banlist.New()).
+		Register(certstore.New()).
 		Register(publish.New()).
 		Register(gateway.New()).
 		Register(quic.New()).
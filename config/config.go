@@ -13,10 +13,13 @@ import (
 	"github.com/anyproto/any-sync/nodeconf"
 	"gopkg.in/yaml.v3"
 
+	"github.com/anyproto/anytype-publish-server/admin"
 	"github.com/anyproto/anytype-publish-server/db"
 	"github.com/anyproto/anytype-publish-server/gateway/gatewayconfig"
+	"github.com/anyproto/anytype-publish-server/moderation"
 	"github.com/anyproto/anytype-publish-server/publish"
 	"github.com/anyproto/anytype-publish-server/redisprovider"
+	"github.com/anyproto/anytype-publish-server/stats"
 	"github.com/anyproto/anytype-publish-server/store"
 )
 
@@ -48,6 +51,9 @@ type Config struct {
 	NetworkUpdateIntervalSec int                    `yaml:"networkUpdateIntervalSec"`
 	Metric                   metric.Config          `yaml:"metric"`
 	Redis                    redisprovider.Config   `yaml:"redis"`
+	Admin                    admin.Config           `yaml:"admin"`
+	Stats                    stats.Config           `yaml:"stats"`
+	Moderation               moderation.Config      `yaml:"moderation"`
 }
 
 func (c *Config) Init(a *app.App) (err error) {
@@ -113,3 +119,15 @@ func (c *Config) GetMetric() metric.Config {
 func (c *Config) GetRedis() redisprovider.Config {
 	return c.Redis
 }
+
+func (c *Config) GetAdmin() admin.Config {
+	return c.Admin
+}
+
+func (c *Config) GetStats() stats.Config {
+	return c.Stats
+}
+
+func (c *Config) GetModeration() moderation.Config {
+	return c.Moderation
+}
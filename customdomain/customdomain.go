@@ -0,0 +1,320 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/gateway/gatewayconfig"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/redisprovider"
+)
+
+const CName = "publish.customdomain"
+
+var log = logger.NewNamed(CName)
+
+const (
+	// TxtRecordPrefix is prepended to the custom domain to get the name of the verification TXT record
+	TxtRecordPrefix = "_anytype-publish."
+	// TxtValuePrefix is prepended to the verification token in the TXT record value
+	TxtValuePrefix = "anytype-publish-verification="
+
+	hostCacheTTL = time.Minute
+	// changesChannel gets the changed hosts, so all instances drop them from the host cache
+	changesChannel = "customdomain:changes"
+)
+
+func New() CustomDomain {
+	return new(customDomain)
+}
+
+// Resolver looks up DNS TXT records, net.DefaultResolver is used by default
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type CustomDomain interface {
+	// Add registers the host for the identity and returns the verification token
+	Add(ctx context.Context, identity, host string) (customDomain domain.CustomDomain, err error)
+	// Verify checks the TXT record of the host and marks it as verified
+	Verify(ctx context.Context, identity, host string) (customDomain domain.CustomDomain, err error)
+	Remove(ctx context.Context, identity, host string) (err error)
+	List(ctx context.Context, identity string) (customDomains []domain.CustomDomain, err error)
+	// ResolveHost returns an identity of the verified host
+	ResolveHost(ctx context.Context, host string) (identity string, err error)
+	app.ComponentRunnable
+}
+
+type hostCacheEntry struct {
+	identity  string
+	expiresAt time.Time
+}
+
+type customDomain struct {
+	db            db.Database
+	coll          *mongo.Collection
+	resolver      Resolver
+	gatewayDomain string
+	redisClient   redis.UniversalClient
+	sub           *redis.PubSub
+
+	hostCache map[string]hostCacheEntry
+	// sweptAt is the time of the last removal of expired hosts, unknown hosts come from any request
+	sweptAt time.Time
+	mu      sync.Mutex
+}
+
+func (c *customDomain) Name() (name string) {
+	return CName
+}
+
+func (c *customDomain) Init(a *app.App) (err error) {
+	c.db = a.MustComponent(db.CName).(db.Database)
+	c.coll = c.db.Db().Collection("customDomain")
+	c.gatewayDomain = strings.ToLower(a.MustComponent("config").(gatewayconfig.ConfigGetter).GetGateway().Domain)
+	c.redisClient = a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
+	if c.resolver == nil {
+		c.resolver = net.DefaultResolver
+	}
+	c.hostCache = make(map[string]hostCacheEntry)
+	return
+}
+
+func (c *customDomain) Run(ctx context.Context) (err error) {
+	if _, err = c.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{"identity", 1}},
+	}); err != nil {
+		return
+	}
+	c.sub = c.redisClient.Subscribe(ctx, changesChannel)
+	// wait for the subscription, so changes made after Run are never missed
+	if _, err = c.sub.Receive(ctx); err != nil {
+		return
+	}
+	go c.listenChanges(c.sub.Channel())
+	return
+}
+
+func (c *customDomain) Add(ctx context.Context, identity, host string) (customDomain domain.CustomDomain, err error) {
+	if host, err = c.normalizeHost(host); err != nil {
+		return
+	}
+	var existing domain.CustomDomain
+	err = c.coll.FindOne(ctx, bson.D{{"_id", host}}).Decode(&existing)
+	if err == nil {
+		if existing.Identity == identity {
+			return existing, nil
+		}
+		// unverified domains can be claimed by another identity
+		if existing.Verified {
+			return domain.CustomDomain{}, publishapi.ErrDomainNotUnique
+		}
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	customDomain = domain.CustomDomain{
+		Host:      host,
+		Identity:  identity,
+		Token:     uuid.New().String(),
+		Timestamp: time.Now().Unix(),
+	}
+	if _, err = c.coll.ReplaceOne(ctx, bson.D{{"_id", host}}, customDomain, options.Replace().SetUpsert(true)); err != nil {
+		return domain.CustomDomain{}, err
+	}
+	return customDomain, nil
+}
+
+func (c *customDomain) Verify(ctx context.Context, identity, host string) (customDomain domain.CustomDomain, err error) {
+	if customDomain, err = c.get(ctx, identity, host); err != nil {
+		return
+	}
+	if customDomain.Verified {
+		return
+	}
+	if err = verifyTXT(ctx, c.resolver, customDomain.Host, customDomain.Token); err != nil {
+		return domain.CustomDomain{}, err
+	}
+	if _, err = c.coll.UpdateOne(
+		ctx,
+		bson.D{{"_id", customDomain.Host}, {"identity", identity}},
+		bson.D{{"$set", bson.D{{"verified", true}}}},
+	); err != nil {
+		return domain.CustomDomain{}, err
+	}
+	customDomain.Verified = true
+	c.hostChanged(ctx, customDomain.Host)
+	return
+}
+
+func (c *customDomain) Remove(ctx context.Context, identity, host string) (err error) {
+	if host, err = c.normalizeHost(host); err != nil {
+		return
+	}
+	res, err := c.coll.DeleteOne(ctx, bson.D{{"_id", host}, {"identity", identity}})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	c.hostChanged(ctx, host)
+	return
+}
+
+func (c *customDomain) List(ctx context.Context, identity string) (customDomains []domain.CustomDomain, err error) {
+	cur, err := c.coll.Find(ctx, bson.D{{"identity", identity}})
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	err = cur.All(ctx, &customDomains)
+	return
+}
+
+func (c *customDomain) ResolveHost(ctx context.Context, host string) (identity string, err error) {
+	host = strings.ToLower(host)
+	c.mu.Lock()
+	entry, ok := c.hostCache[host]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		if entry.identity == "" {
+			return "", publishapi.ErrNotFound
+		}
+		return entry.identity, nil
+	}
+
+	var customDomain domain.CustomDomain
+	err = c.coll.FindOne(ctx, bson.D{{"_id", host}, {"verified", true}}).Decode(&customDomain)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
+	// unknown hosts are cached too
+	now := time.Now()
+	c.mu.Lock()
+	if now.Sub(c.sweptAt) > hostCacheTTL {
+		c.sweep(now)
+	}
+	c.hostCache[host] = hostCacheEntry{identity: customDomain.Identity, expiresAt: now.Add(hostCacheTTL)}
+	c.mu.Unlock()
+	if customDomain.Identity == "" {
+		return "", publishapi.ErrNotFound
+	}
+	return customDomain.Identity, nil
+}
+
+func (c *customDomain) get(ctx context.Context, identity, host string) (customDomain domain.CustomDomain, err error) {
+	if host, err = c.normalizeHost(host); err != nil {
+		return
+	}
+	if err = c.coll.FindOne(ctx, bson.D{{"_id", host}, {"identity", identity}}).Decode(&customDomain); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.CustomDomain{}, publishapi.ErrNotFound
+		}
+	}
+	return
+}
+
+// sweep removes expired hosts, it's called under the lock
+func (c *customDomain) sweep(now time.Time) {
+	for host, entry := range c.hostCache {
+		if !now.Before(entry.expiresAt) {
+			delete(c.hostCache, host)
+		}
+	}
+	c.sweptAt = now
+}
+
+// hostChanged drops the host from the cache of this and other instances
+func (c *customDomain) hostChanged(ctx context.Context, host string) {
+	c.dropHostCache(host)
+	if err := c.redisClient.Publish(ctx, changesChannel, host).Err(); err != nil {
+		log.Warn("publish changes error", zap.Error(err))
+	}
+}
+
+func (c *customDomain) listenChanges(ch <-chan *redis.Message) {
+	for msg := range ch {
+		c.dropHostCache(msg.Payload)
+	}
+}
+
+func (c *customDomain) dropHostCache(host string) {
+	c.mu.Lock()
+	delete(c.hostCache, host)
+	c.mu.Unlock()
+}
+
+// normalizeHost validates the host and returns it in lowercase, hosts under the gateway domain are not allowed
+func (c *customDomain) normalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if !isValidHost(host) {
+		return "", publishapi.ErrInvalidDomain
+	}
+	if c.gatewayDomain != "" && (host == c.gatewayDomain || strings.HasSuffix(host, "."+c.gatewayDomain)) {
+		return "", publishapi.ErrInvalidDomain
+	}
+	return host, nil
+}
+
+func (c *customDomain) Close(ctx context.Context) (err error) {
+	if c.sub != nil {
+		err = c.sub.Close()
+	}
+	return
+}
+
+func isValidHost(host string) bool {
+	if len(host) == 0 || len(host) > 253 || net.ParseIP(host) != nil {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func verifyTXT(ctx context.Context, resolver Resolver, host, token string) error {
+	records, err := resolver.LookupTXT(ctx, TxtRecordPrefix+host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return publishapi.ErrDomainNotVerified
+		}
+		log.Warn("lookup txt error", zap.String("host", host), zap.Error(err))
+		return err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == TxtValuePrefix+token {
+			return nil
+		}
+	}
+	return publishapi.ErrDomainNotVerified
+}
@@ -0,0 +1,83 @@
+package customdomain
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+var ctx = context.Background()
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestVerifyTXT(t *testing.T) {
+	resolver := fakeResolver{
+		"_anytype-publish.example.com": {"v=spf1 -all", "anytype-publish-verification=token1"},
+		"_anytype-publish.other.com":   {"anytype-publish-verification=token2"},
+	}
+	t.Run("matched", func(t *testing.T) {
+		require.NoError(t, verifyTXT(ctx, resolver, "example.com", "token1"))
+	})
+	t.Run("other token", func(t *testing.T) {
+		require.ErrorIs(t, verifyTXT(ctx, resolver, "other.com", "token1"), publishapi.ErrDomainNotVerified)
+	})
+	t.Run("no record", func(t *testing.T) {
+		require.ErrorIs(t, verifyTXT(ctx, resolver, "unknown.com", "token1"), publishapi.ErrDomainNotVerified)
+	})
+}
+
+func TestCustomDomain_normalizeHost(t *testing.T) {
+	c := &customDomain{gatewayDomain: "any.coop"}
+	for host, exp := range map[string]string{
+		"Example.COM":      "example.com",
+		"blog.example.com": "blog.example.com",
+		"example.com.":     "example.com",
+	} {
+		res, err := c.normalizeHost(host)
+		require.NoError(t, err, host)
+		assert.Equal(t, exp, res)
+	}
+	for _, host := range []string{"", "localhost", "127.0.0.1", "any.coop", "name.any.coop", "-a.com", "a_b.com", "a..com"} {
+		_, err := c.normalizeHost(host)
+		assert.ErrorIs(t, err, publishapi.ErrInvalidDomain, host)
+	}
+}
+
+func TestCustomDomain_listenChanges(t *testing.T) {
+	c := &customDomain{hostCache: map[string]hostCacheEntry{
+		"example.com": {identity: "a1", expiresAt: time.Now().Add(hostCacheTTL)},
+		"other.com":   {identity: "a2", expiresAt: time.Now().Add(hostCacheTTL)},
+	}}
+	ch := make(chan *redis.Message, 1)
+	ch <- &redis.Message{Channel: changesChannel, Payload: "example.com"}
+	close(ch)
+	c.listenChanges(ch)
+	assert.NotContains(t, c.hostCache, "example.com")
+	assert.Contains(t, c.hostCache, "other.com")
+}
+
+func TestCustomDomain_sweep(t *testing.T) {
+	now := time.Now()
+	c := &customDomain{hostCache: map[string]hostCacheEntry{
+		"expired.com": {expiresAt: now.Add(-time.Second)},
+		"active.com":  {identity: "a1", expiresAt: now.Add(time.Second)},
+	}}
+	c.sweep(now)
+	assert.NotContains(t, c.hostCache, "expired.com")
+	assert.Contains(t, c.hostCache, "active.com")
+}
@@ -0,0 +1,13 @@
+package domain
+
+// AnalyticsConfig is the analytics integration of the identity pages
+type AnalyticsConfig struct {
+	Identity string `json:"identity" bson:"_id"`
+	// Provider is one of the supported analytics providers
+	Provider string `json:"provider" bson:"provider"`
+	// SiteId is the site domain, website id or measurement id depending on the provider
+	SiteId string `json:"siteId" bson:"siteId"`
+	// Host of the self-hosted provider script, the provider default when empty
+	Host      string `json:"host,omitempty" bson:"host,omitempty"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+type BanKind string
+
+const (
+	BanKindIdentity BanKind = "identity"
+	BanKindName     BanKind = "name"
+	BanKindPublish  BanKind = "publish"
+)
+
+// Ban blocks publishing and serving of the identity, the name or the single publish
+type Ban struct {
+	// {Kind:Value}
+	Id    string  `json:"-" bson:"_id"`
+	Kind  BanKind `json:"kind" bson:"kind"`
+	Value string  `json:"value" bson:"value"`
+	// Identity is the owner of the banned name at the ban time
+	Identity  string `json:"identity,omitempty" bson:"identity,omitempty"`
+	Reason    string `json:"reason,omitempty" bson:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+// CleanupReport counts documents deleted by the cleanup, or to be deleted on the dry run
+type CleanupReport struct {
+	// OutdatedPublishes are created but never uploaded publishes
+	OutdatedPublishes int `json:"outdatedPublishes"`
+	// StaleUploads are publishes whose upload never finished
+	StaleUploads    int `json:"staleUploads"`
+	OutdatedObjects int `json:"outdatedObjects"`
+	// OutdatedVersions are archived versions older than the retention period
+	OutdatedVersions int `json:"outdatedVersions"`
+	ExpiredPreviews  int `json:"expiredPreviews"`
+	// DeletedPublishes are publishes deleted together with their files
+	DeletedPublishes int `json:"deletedPublishes"`
+}
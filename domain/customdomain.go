@@ -0,0 +1,10 @@
+package domain
+
+type CustomDomain struct {
+	// lowercase host without port
+	Host      string `json:"host" bson:"_id"`
+	Identity  string `json:"identity" bson:"identity"`
+	Token     string `json:"token" bson:"token"`
+	Verified  bool   `json:"verified" bson:"verified"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
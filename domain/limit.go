@@ -0,0 +1,15 @@
+package domain
+
+// LimitOverride is the upload size limit of the identity set by an operator
+type LimitOverride struct {
+	Identity  string `json:"identity" bson:"_id"`
+	Limit     int64  `json:"limit" bson:"limit"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
+
+// StorageUsage is the stored size of the identity publishes
+type StorageUsage struct {
+	Size      int64 `json:"size" bson:"size"`
+	Publishes int64 `json:"publishes" bson:"publishes"`
+	Objects   int64 `json:"objects" bson:"objects"`
+}
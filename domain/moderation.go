@@ -0,0 +1,39 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type ReportStatus uint8
+
+const (
+	ReportStatusOpen ReportStatus = iota
+	ReportStatusDismissed
+	// ReportStatusActioned means the reported object is taken down
+	ReportStatusActioned
+)
+
+// Report is the abuse report of the published page
+type Report struct {
+	Id       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Identity string             `json:"identity" bson:"identity"`
+	SpaceId  string             `json:"spaceId" bson:"spaceId"`
+	ObjectId string             `json:"objectId" bson:"objectId"`
+	Uri      string             `json:"uri" bson:"uri"`
+	Reason   string             `json:"reason" bson:"reason"`
+	Comment  string             `json:"comment,omitempty" bson:"comment,omitempty"`
+	// Reporter is the hash of the reporter ip
+	Reporter  string       `json:"-" bson:"reporter"`
+	Status    ReportStatus `json:"status" bson:"status"`
+	Timestamp int64        `json:"timestamp" bson:"timestamp"`
+}
+
+// Takedown blocks the object of the identity regardless of its publishes
+type Takedown struct {
+	Id       string `json:"-" bson:"_id"`
+	Identity string `json:"identity" bson:"identity"`
+	SpaceId  string `json:"spaceId" bson:"spaceId"`
+	ObjectId string `json:"objectId" bson:"objectId"`
+	// Status is the http status of the page: 451 or 410
+	Status    int    `json:"status" bson:"status"`
+	Reason    string `json:"reason,omitempty" bson:"reason,omitempty"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
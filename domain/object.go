@@ -2,6 +2,16 @@ package domain
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+type Visibility uint8
+
+const (
+	VisibilityPublic Visibility = iota
+	// VisibilityUnlisted objects are served by the uri with the random UriSuffix and not indexed
+	VisibilityUnlisted
+	// VisibilityPrivate objects are served only to the owner, publish members and by share links
+	VisibilityPrivate
+)
+
 type Object struct {
 	// {Identity/Uri}
 	Id              string              `json:"id" bson:"_id,omitempty"`
@@ -11,6 +21,10 @@ type Object struct {
 	ObjectId        string              `json:"objectId" bson:"objectId"`
 	Uri             string              `json:"uri" bson:"uri"`
 	Timestamp       int64               `json:"timestamp" bson:"timestamp"`
+	NotFoundPage    bool                `json:"notFoundPage" bson:"notFoundPage,omitempty"`
+	Visibility      Visibility          `json:"visibility" bson:"visibility,omitempty"`
+	UriSuffix       string              `json:"uriSuffix" bson:"uriSuffix,omitempty"`
+	ShareNonce      string              `json:"-" bson:"shareNonce,omitempty"`
 }
 
 type ObjectWithPublish struct {
@@ -8,13 +8,45 @@ const (
 	PublishStatusCreated PublishStatus = iota
 	PublishStatusPublished
 	PublishStatusReadyToDelete
+	// PublishStatusArchived means publish is replaced by the newer one but still available by the version permalink
+	PublishStatusArchived
+	// PublishStatusPreview is the uploaded preview publish, it's served only by the secret preview url
+	PublishStatusPreview
+	// PublishStatusUploading means the upload url is used by the running or failed upload
+	PublishStatusUploading
 )
 
+type PublishType uint8
+
+const (
+	PublishTypeRendered PublishType = iota
+	PublishTypeStatic
+)
+
+// PublishParams are the parameters of the new publish chosen by the owner
+type PublishParams struct {
+	Version     string
+	Type        PublishType
+	SpaFallback bool
+	// Members are identities allowed to read the private publish
+	Members []string
+	// Preview publish doesn't replace the live version until promoted
+	Preview bool
+}
+
 type Publish struct {
-	Id        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	ObjectId  string             `json:"objectId" bson:"objectId"`
-	Status    PublishStatus      `json:"status" bson:"status"`
-	Version   string             `json:"version" bson:"version"`
-	UploadKey string             `json:"uploadKey" bson:"uploadKey"`
-	Size      int64              `json:"size" bson:"size"`
+	Id                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ObjectId          string             `json:"objectId" bson:"objectId"`
+	Status            PublishStatus      `json:"status" bson:"status"`
+	Version           string             `json:"version" bson:"version"`
+	UploadKey         string             `json:"uploadKey" bson:"uploadKey"`
+	Size              int64              `json:"size" bson:"size"`
+	NotFoundHtml      bool               `json:"notFoundHtml" bson:"notFoundHtml,omitempty"`
+	Rules             *PublishRules      `json:"rules,omitempty" bson:"rules,omitempty"`
+	Type              PublishType        `json:"type" bson:"type,omitempty"`
+	SpaFallback       bool               `json:"spaFallback" bson:"spaFallback,omitempty"`
+	ArchivedTimestamp int64              `json:"archivedTimestamp" bson:"archivedTimestamp,omitempty"`
+	Members           []string           `json:"members,omitempty" bson:"members,omitempty"`
+	PreviewKey        string             `json:"-" bson:"previewKey,omitempty"`
+	PreviewExpire     int64              `json:"previewExpire,omitempty" bson:"previewExpire,omitempty"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+// PublishRules are redirect and header rules uploaded with the publish in the _redirects and _headers files
+type PublishRules struct {
+	Redirects []RedirectRule `json:"redirects,omitempty" bson:"redirects,omitempty"`
+	Headers   []HeaderRule   `json:"headers,omitempty" bson:"headers,omitempty"`
+}
+
+// RedirectRule redirects requests matching From to To, paths are relative to the publish uri
+type RedirectRule struct {
+	From   string `json:"from" bson:"from"`
+	To     string `json:"to" bson:"to"`
+	Status int    `json:"status" bson:"status"`
+}
+
+// HeaderRule adds headers to responses for paths matching Path
+type HeaderRule struct {
+	Path    string   `json:"path" bson:"path"`
+	Headers []Header `json:"headers" bson:"headers"`
+}
+
+type Header struct {
+	Name  string `json:"name" bson:"name"`
+	Value string `json:"value" bson:"value"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+// Redirect points the previous uri of the object to the current one
+type Redirect struct {
+	// {Identity/Uri}
+	Id        string `json:"id" bson:"_id"`
+	Identity  string `json:"identity" bson:"identity"`
+	Uri       string `json:"uri" bson:"uri"`
+	SpaceId   string `json:"spaceId" bson:"spaceId"`
+	ObjectId  string `json:"objectId" bson:"objectId"`
+	TargetUri string `json:"targetUri" bson:"targetUri"`
+	Timestamp int64  `json:"timestamp" bson:"timestamp"`
+}
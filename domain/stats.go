@@ -0,0 +1,12 @@
+package domain
+
+// PageStats are the view counters of the uri for one day
+type PageStats struct {
+	Identity string `json:"identity" bson:"identity"`
+	Uri      string `json:"uri" bson:"uri"`
+	// Date is the UTC day in the YYYY-MM-DD format
+	Date  string `json:"date" bson:"date"`
+	Views int64  `json:"views" bson:"views"`
+	// Uniques is the approximate number of unique visitors of the day
+	Uniques int64 `json:"uniques" bson:"uniques"`
+}
@@ -0,0 +1,242 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+const (
+	// authPathPrefix is reserved on every host, so members can sign in on custom domains too
+	authPathPrefix = "/.well-known/anytype-publish/auth/"
+	// the __Host- prefix keeps the session cookie on the issuing host, name subdomains can't set it for the gateway domain
+	sessionCookieName = "__Host-anytype_publish_session"
+
+	challengeTTL = 5 * time.Minute
+	sessionTTL   = 7 * 24 * time.Hour
+
+	maxVerifyRequestSize = 4 << 10
+
+	// authMessagePrefix binds the signed challenge to the gateway sign-in, so signatures made for other purposes are useless here
+	authMessagePrefix = "anytype-publish-auth\x00"
+)
+
+type challengeResponse struct {
+	Challenge string `json:"challenge"`
+}
+
+type verifyRequest struct {
+	Identity  string `json:"identity"`
+	Challenge string `json:"challenge"`
+	// Signature of "anytype-publish-auth\x00{host}\x00{challenge}" by the account key, base64 encoded
+	Signature string `json:"signature"`
+}
+
+type verifyResponse struct {
+	Identity string `json:"identity"`
+}
+
+// handleAuth serves the members sign-in: the client gets a challenge, signs it with the account key and exchanges it for the session cookie
+func (g *gateway) handleAuth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	switch strings.TrimPrefix(r.URL.Path, authPathPrefix) {
+	case "challenge":
+		g.authChallenge(w, r)
+	case "verify":
+		g.authVerify(w, r)
+	case "logout":
+		g.authLogout(w, r)
+	default:
+		http.NotFound(w, nil)
+	}
+}
+
+func (g *gateway) authChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	challenge := randomToken()
+	// the challenge is bound to the host the session cookie will be issued for
+	if err := g.redisClient.SetEx(r.Context(), challengeKey(challenge), requestHost(r), challengeTTL).Err(); err != nil {
+		log.Error("auth challenge set error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, challengeResponse{Challenge: challenge})
+}
+
+func (g *gateway) authVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req verifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVerifyRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	// the challenge is single-use
+	host, err := g.redisClient.GetDel(ctx, challengeKey(req.Challenge)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			http.Error(w, "unknown or expired challenge", http.StatusUnauthorized)
+		} else {
+			log.Error("auth challenge get error", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	if host != requestHost(r) {
+		http.Error(w, "unknown or expired challenge", http.StatusUnauthorized)
+		return
+	}
+	if err = verifyChallenge(req, host); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	session := randomToken()
+	if err = g.redisClient.SetEx(ctx, sessionKey(session), req.Identity, sessionTTL).Err(); err != nil {
+		log.Error("auth session set error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(sessionTTL / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	writeJSON(w, verifyResponse{Identity: req.Identity})
+}
+
+func (g *gateway) authLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		if err = g.redisClient.Del(r.Context(), sessionKey(cookie.Value)).Err(); err != nil {
+			log.Warn("auth session delete error", zap.Error(err))
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionIdentity returns the identity of the signed-in visitor, empty if there is no valid session
+func (g *gateway) sessionIdentity(r *http.Request) string {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return ""
+	}
+	identity, err := g.redisClient.Get(r.Context(), sessionKey(cookie.Value)).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Warn("auth session get error", zap.Error(err))
+		}
+		return ""
+	}
+	return identity
+}
+
+// handleMembersOnlyPage renders the private page for the signed-in owner or member, such pages are never cached
+func (g *gateway) handleMembersOnlyPage(w http.ResponseWriter, r *http.Request, id cacheId, basePath string) {
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	viewer := g.sessionIdentity(r)
+	if viewer == "" {
+		http.Error(w, "sign in required", http.StatusUnauthorized)
+		return
+	}
+	ctx := r.Context()
+	pageObj, err := g.renderPageFor(ctx, id, viewer)
+	if err != nil {
+		log.Error("page render error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer pageObj.close()
+	if pageObj.MembersOnly {
+		http.Error(w, "access denied", http.StatusForbidden)
+		return
+	}
+	if g.writePage(ctx, w, pageObj, basePath) && pageObj.isView() {
+		g.stats.CountView(id.Identity(), id.Uri(), visitorIp(r, g.proxies))
+	}
+}
+
+// canView checks whether the viewer is the owner or a member of the publish
+func canView(pub domain.ObjectWithPublish, viewer string) bool {
+	if pub.Visibility != domain.VisibilityPrivate {
+		return true
+	}
+	if viewer == "" {
+		return false
+	}
+	return viewer == pub.Identity || slices.Contains(pub.Publish.Members, viewer)
+}
+
+// verifyChallenge checks the signature of the challenge message for the host
+func verifyChallenge(req verifyRequest, host string) error {
+	pubKey, err := crypto.DecodeAccountAddress(req.Identity)
+	if err != nil {
+		return errors.New("invalid identity")
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	ok, err := pubKey.Verify(challengeMessage(host, req.Challenge), sig)
+	if err != nil || !ok {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func challengeMessage(host, challenge string) []byte {
+	return []byte(authMessagePrefix + host + "\x00" + challenge)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn("json write error", zap.Error(err))
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func challengeKey(challenge string) string {
+	return "auth-challenge:" + challenge
+}
+
+func sessionKey(session string) string {
+	return "auth-session:" + session
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func Test_verifyChallenge(t *testing.T) {
+	privKey, pubKey, err := crypto.GenerateRandomEd25519KeyPair()
+	require.NoError(t, err)
+	sig, err := privKey.Sign([]byte("anytype-publish-auth\x00example.org\x00challenge"))
+	require.NoError(t, err)
+	req := verifyRequest{
+		Identity:  pubKey.Account(),
+		Challenge: "challenge",
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}
+	require.NoError(t, verifyChallenge(req, "example.org"))
+	assert.Error(t, verifyChallenge(req, "other.org"))
+
+	other := req
+	other.Challenge = "other"
+	assert.Error(t, verifyChallenge(other, "example.org"))
+
+	// the raw challenge signature is not accepted
+	rawSig, err := privKey.Sign([]byte("challenge"))
+	require.NoError(t, err)
+	other = req
+	other.Signature = base64.StdEncoding.EncodeToString(rawSig)
+	assert.Error(t, verifyChallenge(other, "example.org"))
+
+	_, otherKey, err := crypto.GenerateRandomEd25519KeyPair()
+	require.NoError(t, err)
+	other = req
+	other.Identity = otherKey.Account()
+	assert.Error(t, verifyChallenge(other, "example.org"))
+
+	other = req
+	other.Identity = "invalid"
+	assert.Error(t, verifyChallenge(other, "example.org"))
+}
+
+func Test_canView(t *testing.T) {
+	pub := domain.ObjectWithPublish{
+		Object:  domain.Object{Identity: "owner", Visibility: domain.VisibilityPrivate},
+		Publish: &domain.Publish{Members: []string{"member"}},
+	}
+	assert.True(t, canView(pub, "owner"))
+	assert.True(t, canView(pub, "member"))
+	assert.False(t, canView(pub, "other"))
+	assert.False(t, canView(pub, ""))
+
+	pub.Visibility = domain.VisibilityUnlisted
+	assert.True(t, canView(pub, ""))
+}
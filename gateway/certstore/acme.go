@@ -0,0 +1,190 @@
+package certstore
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/acme"
+
+	"github.com/anyproto/anytype-publish-server/gateway/gatewayconfig"
+)
+
+const (
+	acmeChallengePath = "/.well-known/acme-challenge/"
+	acmeChallengeTTL  = 10 * time.Minute
+)
+
+// AccountKeyStore keeps the ACME account key, so the account is reused after restarts and by all instances
+type AccountKeyStore interface {
+	AccountKey(ctx context.Context, directoryURL string) (key crypto.Signer, err error)
+}
+
+// AcmeIssuer issues certificates via an ACME server with the http-01 challenge.
+// Challenge responses are kept in redis, so any gateway instance can answer them via HTTPHandler
+type AcmeIssuer struct {
+	client      *acme.Client
+	email       string
+	redisClient redis.UniversalClient
+	keyStore    AccountKeyStore
+
+	registered bool
+	mu         sync.Mutex
+}
+
+func NewAcmeIssuer(conf gatewayconfig.Acme, redisClient redis.UniversalClient, keyStore AccountKeyStore) *AcmeIssuer {
+	return &AcmeIssuer{
+		client:      &acme.Client{DirectoryURL: conf.DirectoryURL},
+		email:       conf.Email,
+		redisClient: redisClient,
+		keyStore:    keyStore,
+	}
+}
+
+// LoadAccountKey loads the stored account key, it's loaded by the first issue when not called
+func (a *AcmeIssuer) LoadAccountKey(ctx context.Context) (err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.loadAccountKey(ctx)
+}
+
+func (a *AcmeIssuer) loadAccountKey(ctx context.Context) (err error) {
+	if a.client.Key != nil {
+		return
+	}
+	key, err := a.keyStore.AccountKey(ctx, a.client.DirectoryURL)
+	if err != nil {
+		return fmt.Errorf("acme account key: %w", err)
+	}
+	a.client.Key = key
+	return
+}
+
+func (a *AcmeIssuer) Issue(ctx context.Context, host string) (certPem, keyPem []byte, err error) {
+	if err = a.register(ctx); err != nil {
+		return
+	}
+	order, err := a.client.AuthorizeOrder(ctx, acme.DomainIDs(host))
+	if err != nil {
+		return
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err = a.authorize(ctx, authzURL); err != nil {
+			return
+		}
+	}
+	if order, err = a.client.WaitOrder(ctx, order.URI); err != nil {
+		return
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{host}}, certKey)
+	if err != nil {
+		return
+	}
+	chain, _, err := a.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return
+	}
+	for _, der := range chain {
+		certPem = append(certPem, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return
+	}
+	keyPem = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return
+}
+
+func (a *AcmeIssuer) authorize(ctx context.Context, authzURL string) (err error) {
+	authz, err := a.client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return
+	}
+	if authz.Status == acme.StatusValid {
+		return
+	}
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return errors.New("acme: http-01 challenge is not offered")
+	}
+	response, err := a.client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return
+	}
+	key := acmeChallengeKey(challenge.Token)
+	if err = a.redisClient.Set(ctx, key, response, acmeChallengeTTL).Err(); err != nil {
+		return
+	}
+	defer func() {
+		_ = a.redisClient.Del(context.Background(), key).Err()
+	}()
+	if _, err = a.client.Accept(ctx, challenge); err != nil {
+		return
+	}
+	_, err = a.client.WaitAuthorization(ctx, authz.URI)
+	return
+}
+
+func (a *AcmeIssuer) register(ctx context.Context) (err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.registered {
+		return
+	}
+	if err = a.loadAccountKey(ctx); err != nil {
+		return
+	}
+	account := &acme.Account{}
+	if a.email != "" {
+		account.Contact = []string{"mailto:" + a.email}
+	}
+	if _, err = a.client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return fmt.Errorf("acme register: %w", err)
+	}
+	a.registered = true
+	return nil
+}
+
+// HTTPHandler answers http-01 challenges and passes other requests to the next handler
+func (a *AcmeIssuer) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.URL.Path, acmeChallengePath)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		response, err := a.redisClient.Get(r.Context(), acmeChallengeKey(token)).Result()
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(response))
+	})
+}
+
+func acmeChallengeKey(token string) string {
+	return "acme-challenge:" + token
+}
@@ -0,0 +1,69 @@
+package certstore
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func (c *certStore) registerAdminHandlers(a admin.Admin) {
+	a.Handle("GET /admin/certificates", http.HandlerFunc(c.handleList))
+	a.Handle("PUT /admin/certificates/{host}", http.HandlerFunc(c.handlePut))
+	a.Handle("DELETE /admin/certificates/{host}", http.HandlerFunc(c.handleDelete))
+	a.Handle("POST /admin/certificates/{host}/issue", http.HandlerFunc(c.handleIssue))
+}
+
+func (c *certStore) handleList(w http.ResponseWriter, r *http.Request) {
+	infos, err := c.List(r.Context())
+	if err != nil {
+		admin.WriteErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, infos)
+}
+
+// handlePut uploads or rotates the certificate of the host
+func (c *certStore) handlePut(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Cert string `json:"cert"`
+		Key  string `json:"key"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	info, err := c.Put(r.Context(), r.PathValue("host"), []byte(req.Cert), []byte(req.Key))
+	if err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, info)
+}
+
+func (c *certStore) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := c.Delete(r.Context(), r.PathValue("host")); err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			admin.WriteErr(w, http.StatusNotFound, err)
+		} else {
+			admin.WriteErr(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *certStore) handleIssue(w http.ResponseWriter, r *http.Request) {
+	info, err := c.Issue(r.Context(), r.PathValue("host"))
+	if err != nil {
+		if errors.Is(err, ErrIssueInProgress) {
+			admin.WriteErr(w, http.StatusConflict, err)
+		} else {
+			admin.WriteErr(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, info)
+}
@@ -0,0 +1,478 @@
+package certstore
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/metric"
+	"github.com/anyproto/any-sync/util/periodicsync"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/customdomain"
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+const CName = "publish.certstore"
+
+var log = logger.NewNamed(CName)
+
+const (
+	// issued certificates are renewed when they expire sooner than renewBefore
+	renewBefore = 30 * 24 * time.Hour
+	// certificates which expire sooner than expiringBefore are reported by the metric
+	expiringBefore = 14 * 24 * time.Hour
+	issueLockTTL   = 10 * time.Minute
+)
+
+var (
+	ErrNoCertificate   = errors.New("no certificate for the host")
+	ErrIssueInProgress = errors.New("certificate issue is in progress")
+	ErrHostNotMatched  = errors.New("certificate doesn't match the host")
+)
+
+func New() CertStore {
+	return new(certStore)
+}
+
+// Issuer obtains a new certificate for the host, e.g. from an ACME server
+type Issuer interface {
+	Issue(ctx context.Context, host string) (certPem, keyPem []byte, err error)
+}
+
+// CertStore keeps tls certificates in mongo and picks them by SNI
+type CertStore interface {
+	// GetCertificate is a tls.Config.GetCertificate callback
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+	// Put validates and stores the certificate, existing certificate of the host is replaced
+	Put(ctx context.Context, host string, certPem, keyPem []byte) (info CertificateInfo, err error)
+	Delete(ctx context.Context, host string) (err error)
+	List(ctx context.Context) (infos []CertificateInfo, err error)
+	// Issue obtains the certificate for the host via the issuer
+	Issue(ctx context.Context, host string) (info CertificateInfo, err error)
+	SetIssuer(issuer Issuer)
+	// AccountKey returns the ACME account key of the directory, the key is generated and stored on the first call
+	AccountKey(ctx context.Context, directoryURL string) (key crypto.Signer, err error)
+	app.ComponentRunnable
+}
+
+type CertificateInfo struct {
+	Host     string    `json:"host"`
+	NotAfter time.Time `json:"notAfter"`
+	// Issued is true when the certificate was obtained via the issuer and will be renewed automatically
+	Issued bool `json:"issued"`
+	// OnDemand is true when the certificate was issued for a custom domain, it's deleted with the domain
+	OnDemand  bool  `json:"onDemand"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+type certificateDoc struct {
+	Host      string `bson:"_id"`
+	CertPem   []byte `bson:"certPem"`
+	KeyPem    []byte `bson:"keyPem"`
+	NotAfter  int64  `bson:"notAfter"`
+	Issued    bool   `bson:"issued"`
+	OnDemand  bool   `bson:"onDemand"`
+	Timestamp int64  `bson:"timestamp"`
+}
+
+func (d certificateDoc) info() CertificateInfo {
+	return CertificateInfo{
+		Host:      d.Host,
+		NotAfter:  time.Unix(d.NotAfter, 0),
+		Issued:    d.Issued,
+		OnDemand:  d.OnDemand,
+		Timestamp: d.Timestamp,
+	}
+}
+
+type acmeAccountDoc struct {
+	DirectoryURL string `bson:"_id"`
+	KeyPem       []byte `bson:"keyPem"`
+	Timestamp    int64  `bson:"timestamp"`
+}
+
+type loadedCert struct {
+	cert *tls.Certificate
+	info CertificateInfo
+}
+
+type certStore struct {
+	db           db.Database
+	coll         *mongo.Collection
+	lockColl     *mongo.Collection
+	accountColl  *mongo.Collection
+	customDomain customdomain.CustomDomain
+	metric       metric.Metric
+	ticker       periodicsync.PeriodicSync
+
+	issuer Issuer
+	certs  map[string]loadedCert
+	mu     sync.RWMutex
+}
+
+func (c *certStore) Name() (name string) {
+	return CName
+}
+
+func (c *certStore) Init(a *app.App) (err error) {
+	c.db = a.MustComponent(db.CName).(db.Database)
+	c.coll = c.db.Db().Collection("certificate")
+	c.lockColl = c.db.Db().Collection("certificateLock")
+	c.accountColl = c.db.Db().Collection("acmeAccount")
+	c.customDomain = a.MustComponent(customdomain.CName).(customdomain.CustomDomain)
+	c.metric = a.MustComponent(metric.CName).(metric.Metric)
+	c.certs = make(map[string]loadedCert)
+	c.registerAdminHandlers(a.MustComponent(admin.CName).(admin.Admin))
+	return
+}
+
+func (c *certStore) Run(ctx context.Context) (err error) {
+	if err = c.reload(ctx); err != nil {
+		return
+	}
+	c.registerMetrics()
+	c.ticker = periodicsync.NewPeriodicSync(60, time.Minute*5, c.sync, log)
+	c.ticker.Run()
+	return
+}
+
+func (c *certStore) SetIssuer(issuer Issuer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issuer = issuer
+}
+
+func (c *certStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if host == "" {
+		return nil, ErrNoCertificate
+	}
+	if cert := c.lookup(host); cert != nil {
+		return cert, nil
+	}
+	ctx := hello.Context()
+	c.mu.RLock()
+	issuer := c.issuer
+	c.mu.RUnlock()
+	if issuer == nil {
+		return nil, ErrNoCertificate
+	}
+	// certificates are issued on demand only for verified custom domains
+	if _, err := c.customDomain.ResolveHost(ctx, host); err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			return nil, ErrNoCertificate
+		}
+		return nil, err
+	}
+	if _, err := c.issue(ctx, host, true); err != nil {
+		log.Warn("can't issue certificate", zap.String("host", host), zap.Error(err))
+		return nil, err
+	}
+	if cert := c.lookup(host); cert != nil {
+		return cert, nil
+	}
+	return nil, ErrNoCertificate
+}
+
+// lookup finds the certificate for the host or the wildcard certificate of the parent domain
+func (c *certStore) lookup(host string) *tls.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if lc, ok := c.certs[host]; ok {
+		return lc.cert
+	}
+	if _, parent, ok := strings.Cut(host, "."); ok {
+		if lc, ok := c.certs["*."+parent]; ok {
+			return lc.cert
+		}
+	}
+	return nil
+}
+
+func (c *certStore) Put(ctx context.Context, host string, certPem, keyPem []byte) (info CertificateInfo, err error) {
+	return c.put(ctx, host, certPem, keyPem, false, false)
+}
+
+func (c *certStore) put(ctx context.Context, host string, certPem, keyPem []byte, issued, onDemand bool) (info CertificateInfo, err error) {
+	host = strings.ToLower(host)
+	cert, err := parseCertificate(host, certPem, keyPem)
+	if err != nil {
+		return
+	}
+	doc := certificateDoc{
+		Host:      host,
+		CertPem:   certPem,
+		KeyPem:    keyPem,
+		NotAfter:  cert.Leaf.NotAfter.Unix(),
+		Issued:    issued,
+		OnDemand:  onDemand,
+		Timestamp: time.Now().Unix(),
+	}
+	if _, err = c.coll.ReplaceOne(ctx, bson.D{{"_id", host}}, doc, options.Replace().SetUpsert(true)); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.certs[host] = loadedCert{cert: cert, info: doc.info()}
+	c.mu.Unlock()
+	return doc.info(), nil
+}
+
+func (c *certStore) Delete(ctx context.Context, host string) (err error) {
+	host = strings.ToLower(host)
+	res, err := c.coll.DeleteOne(ctx, bson.D{{"_id", host}})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	c.mu.Lock()
+	delete(c.certs, host)
+	c.mu.Unlock()
+	return
+}
+
+func (c *certStore) List(ctx context.Context) (infos []CertificateInfo, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	infos = make([]CertificateInfo, 0, len(c.certs))
+	for _, lc := range c.certs {
+		infos = append(infos, lc.info)
+	}
+	return
+}
+
+func (c *certStore) Issue(ctx context.Context, host string) (info CertificateInfo, err error) {
+	return c.issue(ctx, host, false)
+}
+
+func (c *certStore) issue(ctx context.Context, host string, onDemand bool) (info CertificateInfo, err error) {
+	c.mu.RLock()
+	issuer := c.issuer
+	c.mu.RUnlock()
+	if issuer == nil {
+		return info, errors.New("certificate issuer is not configured")
+	}
+	host = strings.ToLower(host)
+	// the lock prevents concurrent issues of the same certificate on different instances
+	if err = c.lock(ctx, host); err != nil {
+		return
+	}
+	defer func() {
+		_, _ = c.lockColl.DeleteOne(context.Background(), bson.D{{"_id", host}})
+	}()
+	certPem, keyPem, err := issuer.Issue(ctx, host)
+	if err != nil {
+		return
+	}
+	return c.put(ctx, host, certPem, keyPem, true, onDemand)
+}
+
+func (c *certStore) AccountKey(ctx context.Context, directoryURL string) (key crypto.Signer, err error) {
+	var doc acmeAccountDoc
+	err = c.accountColl.FindOne(ctx, bson.D{{"_id", directoryURL}}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if doc, err = c.createAccountKey(ctx, directoryURL); mongo.IsDuplicateKeyError(err) {
+			// the key was stored by another instance
+			err = c.accountColl.FindOne(ctx, bson.D{{"_id", directoryURL}}).Decode(&doc)
+		}
+	}
+	if err != nil {
+		return
+	}
+	block, _ := pem.Decode(doc.KeyPem)
+	if block == nil {
+		return nil, errors.New("invalid acme account key")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+func (c *certStore) createAccountKey(ctx context.Context, directoryURL string) (doc acmeAccountDoc, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return
+	}
+	doc = acmeAccountDoc{
+		DirectoryURL: directoryURL,
+		KeyPem:       pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		Timestamp:    time.Now().Unix(),
+	}
+	_, err = c.accountColl.InsertOne(ctx, doc)
+	return
+}
+
+func (c *certStore) lock(ctx context.Context, host string) (err error) {
+	now := time.Now()
+	_, err = c.lockColl.UpdateOne(
+		ctx,
+		bson.D{{"_id", host}, {"until", bson.D{{"$lt", now.Unix()}}}},
+		bson.D{{"$set", bson.D{{"until", now.Add(issueLockTTL).Unix()}}}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrIssueInProgress
+	}
+	return
+}
+
+func (c *certStore) sync(ctx context.Context) error {
+	if err := c.reload(ctx); err != nil {
+		log.Warn("can't reload certificates", zap.Error(err))
+		return nil
+	}
+	c.renew(ctx)
+	return nil
+}
+
+// reload loads all certificates from the db to pick up changes made on other instances
+func (c *certStore) reload(ctx context.Context) (err error) {
+	cur, err := c.coll.Find(ctx, bson.D{})
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	var certs = make(map[string]loadedCert)
+	for cur.Next(ctx) {
+		var doc certificateDoc
+		if err = cur.Decode(&doc); err != nil {
+			return
+		}
+		cert, parseErr := parseCertificate(doc.Host, doc.CertPem, doc.KeyPem)
+		if parseErr != nil {
+			log.Warn("can't parse stored certificate", zap.String("host", doc.Host), zap.Error(parseErr))
+			continue
+		}
+		certs[doc.Host] = loadedCert{cert: cert, info: doc.info()}
+	}
+	if err = cur.Err(); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.certs = certs
+	c.mu.Unlock()
+	return
+}
+
+func (c *certStore) renew(ctx context.Context) {
+	c.mu.RLock()
+	if c.issuer == nil {
+		c.mu.RUnlock()
+		return
+	}
+	var infos []CertificateInfo
+	for _, lc := range c.certs {
+		if lc.info.Issued && time.Until(lc.info.NotAfter) < renewBefore {
+			infos = append(infos, lc.info)
+		}
+	}
+	c.mu.RUnlock()
+	for _, info := range infos {
+		host := info.Host
+		if info.OnDemand {
+			// the custom domain could be removed or unverified since the issue
+			if _, err := c.customDomain.ResolveHost(ctx, host); err != nil {
+				if !errors.Is(err, publishapi.ErrNotFound) {
+					log.Warn("can't resolve host", zap.String("host", host), zap.Error(err))
+				} else if err = c.Delete(ctx, host); err != nil && !errors.Is(err, publishapi.ErrNotFound) {
+					log.Warn("can't delete orphaned certificate", zap.String("host", host), zap.Error(err))
+				} else {
+					log.Info("orphaned certificate deleted", zap.String("host", host))
+				}
+				continue
+			}
+		}
+		if _, err := c.issue(ctx, host, info.OnDemand); err != nil && !errors.Is(err, ErrIssueInProgress) {
+			log.Warn("can't renew certificate", zap.String("host", host), zap.Error(err))
+		} else if err == nil {
+			log.Info("certificate renewed", zap.String("host", host))
+		}
+	}
+}
+
+func (c *certStore) registerMetrics() {
+	c.metric.Registry().MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "publish",
+			Subsystem: "certstore",
+			Name:      "certificates",
+			Help:      "number of stored tls certificates",
+		}, func() float64 {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return float64(len(c.certs))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "publish",
+			Subsystem: "certstore",
+			Name:      "expiring_certificates",
+			Help:      "number of tls certificates which expire in 14 days or already expired",
+		}, func() float64 {
+			return float64(c.expiringCount(time.Now()))
+		}),
+	)
+}
+
+func (c *certStore) expiringCount(now time.Time) (count int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lc := range c.certs {
+		if lc.info.NotAfter.Sub(now) < expiringBefore {
+			count++
+		}
+	}
+	return
+}
+
+func (c *certStore) Close(ctx context.Context) (err error) {
+	if c.ticker != nil {
+		c.ticker.Close()
+	}
+	return
+}
+
+// parseCertificate checks that the key matches the certificate and the certificate covers the host
+func parseCertificate(host string, certPem, keyPem []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasPrefix(host, "*.") {
+		if !slices.Contains(cert.Leaf.DNSNames, host) {
+			return nil, ErrHostNotMatched
+		}
+	} else if err = cert.Leaf.VerifyHostname(host); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrHostNotMatched, err)
+	}
+	return &cert, nil
+}
@@ -0,0 +1,217 @@
+package certstore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/anyproto/anytype-publish-server/customdomain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+var ctx = context.Background()
+
+func TestParseCertificate(t *testing.T) {
+	certPem, keyPem := newTestCert(t, time.Now().Add(time.Hour), "example.com", "*.example.com")
+	t.Run("host", func(t *testing.T) {
+		cert, err := parseCertificate("example.com", certPem, keyPem)
+		require.NoError(t, err)
+		assert.NotNil(t, cert.Leaf)
+	})
+	t.Run("wildcard", func(t *testing.T) {
+		_, err := parseCertificate("*.example.com", certPem, keyPem)
+		require.NoError(t, err)
+	})
+	t.Run("other host", func(t *testing.T) {
+		_, err := parseCertificate("other.com", certPem, keyPem)
+		require.ErrorIs(t, err, ErrHostNotMatched)
+	})
+	t.Run("other key", func(t *testing.T) {
+		_, otherKeyPem := newTestCert(t, time.Now().Add(time.Hour), "example.com")
+		_, err := parseCertificate("example.com", certPem, otherKeyPem)
+		require.Error(t, err)
+	})
+}
+
+func TestCertStore_lookup(t *testing.T) {
+	c := &certStore{certs: map[string]loadedCert{}}
+	for _, host := range []string{"example.com", "*.any.coop"} {
+		certPem, keyPem := newTestCert(t, time.Now().Add(time.Hour*24*60), host)
+		cert, err := parseCertificate(host, certPem, keyPem)
+		require.NoError(t, err)
+		c.certs[host] = loadedCert{cert: cert, info: CertificateInfo{Host: host, NotAfter: cert.Leaf.NotAfter}}
+	}
+	assert.NotNil(t, c.lookup("example.com"))
+	assert.NotNil(t, c.lookup("name.any.coop"))
+	assert.Nil(t, c.lookup("a.name.any.coop"))
+	assert.Nil(t, c.lookup("other.com"))
+
+	assert.Equal(t, 0, c.expiringCount(time.Now()))
+	assert.Equal(t, 2, c.expiringCount(time.Now().Add(time.Hour*24*50)))
+}
+
+func TestCertStore_Issue(t *testing.T) {
+	fx := newFixture(t)
+	fx.SetIssuer(nil)
+	_, err := fx.Issue(ctx, "example.com")
+	require.Error(t, err)
+
+	fx.SetIssuer(fx.issuer)
+	info, err := fx.Issue(ctx, "Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", info.Host)
+	assert.True(t, info.Issued)
+	assert.False(t, info.OnDemand)
+	assert.Equal(t, 1, fx.issuer.count("example.com"))
+	assert.NotNil(t, fx.lookup("example.com"))
+
+	// the issued certificate is loaded by other instances
+	fx.certs = map[string]loadedCert{}
+	require.NoError(t, fx.reload(ctx))
+	assert.NotNil(t, fx.lookup("example.com"))
+}
+
+func TestCertStore_GetCertificate(t *testing.T) {
+	fx := newFixture(t)
+	fx.domains.hosts["verified.com"] = "identity"
+
+	_, err := fx.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"})
+	require.ErrorIs(t, err, ErrNoCertificate)
+	assert.Equal(t, 0, fx.issuer.count("other.com"))
+
+	for range 2 {
+		cert, err := fx.GetCertificate(&tls.ClientHelloInfo{ServerName: "Verified.com."})
+		require.NoError(t, err)
+		assert.Contains(t, cert.Leaf.DNSNames, "verified.com")
+	}
+	assert.Equal(t, 1, fx.issuer.count("verified.com"))
+	infos, err := fx.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.True(t, infos[0].OnDemand)
+}
+
+func TestCertStore_renew(t *testing.T) {
+	fx := newFixture(t)
+	fx.domains.hosts["kept.com"] = "identity"
+	fx.domains.hosts["removed.com"] = "identity"
+	for _, host := range []string{"kept.com", "removed.com"} {
+		_, err := fx.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		require.NoError(t, err)
+	}
+	_, err := fx.Issue(ctx, "admin.com")
+	require.NoError(t, err)
+	certPem, keyPem := newTestCert(t, time.Now().Add(time.Hour), "uploaded.com")
+	_, err = fx.Put(ctx, "uploaded.com", certPem, keyPem)
+	require.NoError(t, err)
+	delete(fx.domains.hosts, "removed.com")
+
+	fx.renew(ctx)
+	assert.Equal(t, 2, fx.issuer.count("kept.com"))
+	assert.Equal(t, 2, fx.issuer.count("admin.com"))
+	assert.Equal(t, 1, fx.issuer.count("removed.com"))
+	assert.Equal(t, 0, fx.issuer.count("uploaded.com"))
+	assert.NotNil(t, fx.lookup("kept.com"))
+	assert.Nil(t, fx.lookup("removed.com"))
+	assert.NotNil(t, fx.lookup("uploaded.com"))
+	require.NoError(t, fx.reload(ctx))
+	assert.Nil(t, fx.lookup("removed.com"))
+}
+
+func newFixture(t *testing.T) *fixture {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	database := client.Database("publish_unittest")
+	fx := &fixture{
+		certStore: &certStore{
+			coll:        database.Collection("certificate"),
+			lockColl:    database.Collection("certificateLock"),
+			accountColl: database.Collection("acmeAccount"),
+			certs:       map[string]loadedCert{},
+		},
+		// issued certificates expire soon to be renewed on every sync
+		issuer:  &testIssuer{t: t, notAfter: time.Now().Add(renewBefore / 2), issued: map[string]int{}},
+		domains: &testCustomDomain{hosts: map[string]string{}},
+	}
+	fx.customDomain = fx.domains
+	fx.SetIssuer(fx.issuer)
+	t.Cleanup(func() {
+		_ = fx.coll.Drop(ctx)
+		_ = fx.lockColl.Drop(ctx)
+		_ = fx.accountColl.Drop(ctx)
+		require.NoError(t, client.Disconnect(ctx))
+	})
+	return fx
+}
+
+type fixture struct {
+	*certStore
+	issuer  *testIssuer
+	domains *testCustomDomain
+}
+
+type testIssuer struct {
+	t        *testing.T
+	notAfter time.Time
+	issued   map[string]int
+	mu       sync.Mutex
+}
+
+func (i *testIssuer) Issue(ctx context.Context, host string) (certPem, keyPem []byte, err error) {
+	i.mu.Lock()
+	i.issued[host]++
+	i.mu.Unlock()
+	certPem, keyPem = newTestCert(i.t, i.notAfter, host)
+	return
+}
+
+func (i *testIssuer) count(host string) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.issued[host]
+}
+
+type testCustomDomain struct {
+	customdomain.CustomDomain
+	hosts map[string]string
+}
+
+func (d *testCustomDomain) ResolveHost(ctx context.Context, host string) (identity string, err error) {
+	if identity = d.hosts[host]; identity == "" {
+		return "", publishapi.ErrNotFound
+	}
+	return
+}
+
+func newTestCert(t *testing.T, notAfter time.Time, hosts ...string) (certPem, keyPem []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPem = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return
+}
@@ -3,28 +3,49 @@ package gateway
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"path"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 	"unsafe"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/app/ocache"
 	"github.com/anyproto/anytype-publish-renderer/renderer"
 	"github.com/golang/snappy"
 	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/anytype-publish-server/analytics"
+	"github.com/anyproto/anytype-publish-server/banlist"
+	"github.com/anyproto/anytype-publish-server/customdomain"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/gateway/certstore"
 	"github.com/anyproto/anytype-publish-server/gateway/gatewayconfig"
+	"github.com/anyproto/anytype-publish-server/moderation"
 	"github.com/anyproto/anytype-publish-server/nameservice"
 	"github.com/anyproto/anytype-publish-server/publish"
+	"github.com/anyproto/anytype-publish-server/publish/publishrules"
+	"github.com/anyproto/anytype-publish-server/publish/sharelink"
 	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
 	"github.com/anyproto/anytype-publish-server/redisprovider"
+	"github.com/anyproto/anytype-publish-server/stats"
+	"github.com/anyproto/anytype-publish-server/store"
 )
 
 func New() Gateway {
@@ -42,11 +63,24 @@ type Gateway interface {
 type gateway struct {
 	mux           *http.ServeMux
 	server        *http.Server
+	tlsServer     *http.Server
+	certStore     certstore.CertStore
+	acmeIssuer    *certstore.AcmeIssuer
 	publish       publish.Service
+	store         store.Store
 	config        gatewayconfig.Config
 	nameService   nameservice.NameService
+	customDomain  customdomain.CustomDomain
 	renderVersion string
 	redisClient   redis.UniversalClient
+	domain        string
+	shareLinkKey  []byte
+	proxies       []netip.Prefix
+	stats         stats.Stats
+	analytics     analytics.Analytics
+	moderation    moderation.Moderation
+	banList       banlist.BanList
+	invalidateSub *redis.PubSub
 }
 
 func (g *gateway) Name() (name string) {
@@ -56,7 +90,19 @@ func (g *gateway) Name() (name string) {
 func (g *gateway) Init(a *app.App) (err error) {
 	g.publish = a.MustComponent(publish.CName).(publish.Service)
 	g.nameService = a.MustComponent(nameservice.CName).(nameservice.NameService)
+	g.store = a.MustComponent(store.CName).(store.Store)
+	g.customDomain = a.MustComponent(customdomain.CName).(customdomain.CustomDomain)
+	g.certStore = a.MustComponent(certstore.CName).(certstore.CertStore)
+	g.stats = a.MustComponent(stats.CName).(stats.Stats)
+	g.analytics = a.MustComponent(analytics.CName).(analytics.Analytics)
+	g.moderation = a.MustComponent(moderation.CName).(moderation.Moderation)
+	g.banList = a.MustComponent(banlist.CName).(banlist.BanList)
 	g.config = a.MustComponent("config").(gatewayconfig.ConfigGetter).GetGateway()
+	g.domain = strings.ToLower(g.config.Domain)
+	g.shareLinkKey = []byte(g.config.ShareLinkKey)
+	if g.proxies, err = parseProxies(g.config.TrustedProxies); err != nil {
+		return
+	}
 	g.mux = http.NewServeMux()
 
 	g.redisClient = a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
@@ -72,43 +118,128 @@ func (g *gateway) Init(a *app.App) (err error) {
 		g.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
 	}
 	g.mux.HandleFunc(`/name/{name}/{uri...}`, g.renderPageWithNameHandler)
+	g.mux.HandleFunc(`/preview/{publishId}/{previewKey}/{path...}`, g.renderPreviewHandler)
 	g.mux.HandleFunc("/{identity}/{uri...}", g.renderPageHandler)
-	g.server = &http.Server{Addr: g.config.Addr, Handler: g.mux}
+	var handler http.Handler = http.HandlerFunc(g.serveHTTP)
+	if g.config.TLS.Addr != "" {
+		g.tlsServer = &http.Server{
+			Addr:      g.config.TLS.Addr,
+			Handler:   handler,
+			TLSConfig: &tls.Config{GetCertificate: g.certStore.GetCertificate},
+		}
+	}
+	if g.config.TLS.Acme.DirectoryURL != "" {
+		g.acmeIssuer = certstore.NewAcmeIssuer(g.config.TLS.Acme, g.redisClient, g.certStore)
+		g.certStore.SetIssuer(g.acmeIssuer)
+		// http-01 challenges are answered by the plain http server
+		handler = g.acmeIssuer.HTTPHandler(handler)
+	}
+	g.server = &http.Server{Addr: g.config.Addr, Handler: handler}
 	return
 }
 
 func (g *gateway) Run(ctx context.Context) (err error) {
 	g.publish.SetInvalidateCacheCallback(g.invalidateCache)
-	var errCh = make(chan error)
+	g.moderation.SetInvalidateCacheCallback(g.invalidateCache)
+	g.banList.SetInvalidateCacheCallback(g.invalidateCache)
+	if g.acmeIssuer != nil {
+		if err = g.acmeIssuer.LoadAccountKey(ctx); err != nil {
+			return
+		}
+	}
+	g.invalidateSub = g.redisClient.Subscribe(ctx, invalidateChannel, invalidateVersionsChannel)
+	go g.listenInvalidate(g.invalidateSub.Channel())
+	var errCh = make(chan error, 2)
 	go func() {
 		errCh <- g.server.ListenAndServe()
 	}()
+	if g.tlsServer != nil {
+		go func() {
+			errCh <- g.tlsServer.ListenAndServeTLS("", "")
+		}()
+	}
 	select {
 	case err = <-errCh:
 		return err
 	case <-time.After(200 * time.Millisecond):
-		log.Info("gateway server started", zap.String("addr", g.config.Addr))
+		log.Info("gateway server started", zap.String("addr", g.config.Addr), zap.String("tlsAddr", g.config.TLS.Addr))
 		return
 	}
 }
 
+// serveHTTP routes requests by the Host header: {name}.{domain} subdomains and verified custom domains
+// serve pages from the root path, all other hosts fall back to the path-based routes
+func (g *gateway) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, authPathPrefix) {
+		g.handleAuth(w, r)
+		return
+	}
+	if r.URL.Path == reportPath && r.Method == http.MethodPost {
+		g.handleReport(w, r)
+		return
+	}
+	host := requestHost(r)
+	if host == "" || host == g.domain || (g.config.ServeStatic && strings.HasPrefix(r.URL.Path, "/static/")) {
+		g.mux.ServeHTTP(w, r)
+		return
+	}
+	uri := strings.TrimPrefix(r.URL.Path, "/")
+	if g.domain != "" {
+		if name, ok := strings.CutSuffix(host, "."+g.domain); ok && name != "" && !strings.Contains(name, ".") {
+			g.renderPageWithName(w, r, name, uri, host)
+			return
+		}
+	}
+	identity, err := g.customDomain.ResolveHost(r.Context(), host)
+	if err == nil {
+		g.handlePage(w, r, newCacheId(identity, uri, false, host), "/")
+		return
+	}
+	if !errors.Is(err, publishapi.ErrNotFound) {
+		log.Warn("resolve host error", zap.String("host", host), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	g.mux.ServeHTTP(w, r)
+}
+
 func (g *gateway) renderPageWithNameHandler(w http.ResponseWriter, r *http.Request) {
-	name := r.PathValue("name")
+	g.renderPageWithName(w, r, r.PathValue("name"), r.PathValue("uri"), "")
+}
+
+func (g *gateway) renderPageWithName(w http.ResponseWriter, r *http.Request, name, uri, host string) {
+	if g.banList.IsBanned(domain.BanKindName, name) {
+		writeBanned(w)
+		return
+	}
 	identity, err := g.getIdentity(r.Context(), name)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	g.handlePage(r.Context(), w, identity, r.PathValue("uri"), true)
+	basePath := "/"
+	if host == "" {
+		basePath = "/name/" + name + "/"
+	}
+	g.handlePage(w, r, newCacheId(identity, uri, true, host), basePath)
 }
 
 func (g *gateway) renderPageHandler(w http.ResponseWriter, r *http.Request) {
-	g.handlePage(r.Context(), w, r.PathValue("identity"), r.PathValue("uri"), false)
+	identity := r.PathValue("identity")
+	g.handlePage(w, r, newCacheId(identity, r.PathValue("uri"), false, ""), "/"+identity+"/")
 }
 
-func (g *gateway) handlePage(ctx context.Context, w http.ResponseWriter, identity, uri string, withName bool) {
-	id := newCacheId(identity, uri, withName)
-
+// handlePage serves the page from the cache or renders it, basePath is the path prefix of the page uri in the current route
+func (g *gateway) handlePage(w http.ResponseWriter, r *http.Request, id cacheId, basePath string) {
+	// banned identities are checked before the cache, so the ban takes effect right away
+	if g.banList.IsBanned(domain.BanKindIdentity, id.Identity()) {
+		writeBanned(w)
+		return
+	}
+	if share, fromQuery := shareParams(r); share != nil && g.handleSharedPage(w, r, id, basePath, share, fromQuery) {
+		return
+	}
+	ctx := r.Context()
 	pageObj, cacheErr := g.cacheGet(ctx, id)
 	if cacheErr != nil {
 		if errors.Is(cacheErr, redis.Nil) {
@@ -124,27 +255,32 @@ func (g *gateway) handlePage(ctx context.Context, w http.ResponseWriter, identit
 	)
 
 	// we ignore cache if render version mismatch
-	if !isCacheMissed && !pageObj.IsNotFound && pageObj.RenderVer != g.renderVersion {
+	if !isCacheMissed && pageObj.Body != "" && pageObj.RenderVer != g.renderVersion {
 		isCacheMissed = true
 	}
+	// and if the analytics config of the identity has changed
+	if !isCacheMissed && pageObj.Analytics != "" {
+		if code, err := g.analyticsCode(ctx, id.Identity(), id.WithName()); err != nil || analyticsHash(code) != pageObj.Analytics {
+			isCacheMissed = true
+		}
+	}
 
 	if isCacheMissed {
-		if pageObj, err = g.renderPage(ctx, id); err != nil {
+		if pageObj, err = g.renderPageFor(ctx, id, ""); err != nil {
 			log.Error("page render error", zap.Error(err))
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 	}
+	defer pageObj.close()
 
-	if pageObj.IsNotFound {
-		http.NotFound(w, nil)
-	} else {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		_, err = io.Copy(w, strings.NewReader(pageObj.Body))
-		if err != nil {
-			log.Error("page write error", zap.Error(err))
-		}
+	// the cache holds only the members-only marker of private pages, the content is rendered per visitor
+	if pageObj.MembersOnly {
+		g.handleMembersOnlyPage(w, r, id, basePath)
+	} else if !g.writePage(ctx, w, pageObj, basePath) {
+		return
+	} else if pageObj.isView() {
+		g.stats.CountView(id.Identity(), id.Uri(), visitorIp(r, g.proxies))
 	}
 
 	if isCacheMissed {
@@ -154,13 +290,229 @@ func (g *gateway) handlePage(ctx context.Context, w http.ResponseWriter, identit
 	}
 }
 
+// handleSharedPage serves the page or the static site sub path by the signed share link, such pages are never cached.
+// Returns false if the share cookie isn't valid for the page, so the page is served as usual
+func (g *gateway) handleSharedPage(w http.ResponseWriter, r *http.Request, id cacheId, basePath string, share url.Values, fromQuery bool) (handled bool) {
+	ctx := r.Context()
+	uri := id.Uri()
+	sharedUri := uri
+	pub, err := g.resolveUri(ctx, id.Identity(), uri)
+	if errors.Is(err, publishapi.ErrNotFound) {
+		pub, sharedUri, err = g.resolveParent(ctx, id.Identity(), uri)
+	}
+	if err == nil && pub.Publish == nil {
+		err = publishapi.ErrNotFound
+	}
+	if err == nil {
+		err = sharelink.Verify(g.shareLinkKey, pub.Object, share, time.Now())
+	}
+	if err != nil && !fromQuery {
+		return false
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			http.NotFound(w, nil)
+		} else if errors.Is(err, sharelink.ErrExpired) {
+			http.Error(w, err.Error(), http.StatusGone)
+		} else if errors.Is(err, sharelink.ErrInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		} else {
+			log.Error("resolve shared page error", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return true
+	}
+	pageObj, err := g.renderBlocked(ctx, pub)
+	if err == nil && pageObj == nil {
+		if sharedUri == uri {
+			pageObj, err = g.renderObject(ctx, id, pub)
+		} else if pageObj, err = g.renderPublishSubPath(ctx, pub, uri, uri[len(sharedUri):]); pageObj != nil {
+			setVisibilityHeaders(pageObj, pub.Object)
+		}
+	}
+	if err != nil {
+		log.Error("page render error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return true
+	}
+	if pageObj == nil {
+		http.NotFound(w, nil)
+		return true
+	}
+	defer pageObj.close()
+	if fromQuery && pub.Publish.Type == domain.PublishTypeStatic {
+		// files of the static site are requested by relative links without the signature
+		setShareCookie(w, basePath+sharedUri+"/", share)
+	}
+	pageObj.redirectQuery = url.Values{sharelink.ExpParam: share[sharelink.ExpParam], sharelink.SigParam: share[sharelink.SigParam]}.Encode()
+	g.writePage(ctx, w, pageObj, basePath)
+	return true
+}
+
+// shareParams returns the share link params of the query or of the share cookie of the static site
+func shareParams(r *http.Request) (share url.Values, fromQuery bool) {
+	if query := r.URL.Query(); sharelink.IsSigned(query) {
+		return query, true
+	}
+	// the browser sends the cookie of the nearest shared path first
+	for _, cookie := range r.Cookies() {
+		if cookie.Name != shareCookieName {
+			continue
+		}
+		if exp, sig, ok := strings.Cut(cookie.Value, "."); ok {
+			return url.Values{sharelink.ExpParam: {exp}, sharelink.SigParam: {sig}}, false
+		}
+	}
+	return nil, false
+}
+
+// setShareCookie keeps the share link params for the paths of the shared static site until the link expires
+func setShareCookie(w http.ResponseWriter, path string, share url.Values) {
+	exp, err := strconv.ParseInt(share.Get(sharelink.ExpParam), 10, 64)
+	if err != nil {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     shareCookieName,
+		Value:    share.Get(sharelink.ExpParam) + "." + share.Get(sharelink.SigParam),
+		Path:     (&url.URL{Path: path}).EscapedPath(),
+		MaxAge:   int(time.Until(time.Unix(exp, 0)) / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// renderPreviewHandler serves the preview publish by the secret url, previews are never cached
+func (g *gateway) renderPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	publishId, previewKey := r.PathValue("publishId"), r.PathValue("previewKey")
+	pub, err := g.publish.ResolvePreview(ctx, publishId, previewKey)
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			http.NotFound(w, nil)
+		} else {
+			log.Error("resolve preview error", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	pageObj, err := g.renderBlocked(ctx, pub)
+	if err == nil && pageObj == nil {
+		pageObj, err = g.renderPreview(ctx, pub, r.PathValue("path"))
+	}
+	if err != nil {
+		log.Error("preview render error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if pageObj == nil {
+		http.NotFound(w, nil)
+		return
+	}
+	defer pageObj.close()
+	g.writePage(ctx, w, pageObj, "/preview/"+publishId+"/"+previewKey+"/")
+}
+
+// renderPreview renders the preview page or the static site file, returns nil if nothing found
+func (g *gateway) renderPreview(ctx context.Context, pub domain.ObjectWithPublish, name string) (pageObj *pageObject, err error) {
+	if pub.Publish.Type == domain.PublishTypeStatic {
+		pageObj, err = g.renderStaticFile(ctx, name, pub.Publish, name)
+	} else if name == "" {
+		pageObj, err = g.renderPublish(ctx, pub.Publish.Id, pub.Identity, false)
+	}
+	if err != nil || pageObj == nil {
+		return nil, err
+	}
+	if pageObj.RedirectUri == "" && pub.Publish.Rules != nil {
+		pageObj.Headers = publishrules.MatchHeaders(pub.Publish.Rules.Headers, "/"+name)
+	}
+	return pageObj, nil
+}
+
+// writePage writes the page response, returns false if the page can't be served and must not be cached
+func (g *gateway) writePage(ctx context.Context, w http.ResponseWriter, pageObj *pageObject, basePath string) (ok bool) {
+	if pageObj.TakedownStatus != 0 {
+		http.Error(w, http.StatusText(pageObj.TakedownStatus), pageObj.TakedownStatus)
+		return true
+	}
+	if pageObj.Sandbox {
+		// scripts of the uploaded files run in the opaque origin and can't read other pages with the visitor's cookies
+		w.Header().Set("Content-Security-Policy", sandboxPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}
+	// rule headers go first: security and cache headers set by the gateway can't be overridden
+	for name, values := range pageObj.Headers {
+		if _, ok := w.Header()[name]; !ok {
+			w.Header()[name] = values
+		}
+	}
+	if pageObj.RedirectUri != "" || pageObj.RedirectUrl != "" {
+		location := pageObj.RedirectUrl
+		if location == "" {
+			location = (&url.URL{Path: basePath + pageObj.RedirectUri}).EscapedPath()
+			if pageObj.redirectQuery != "" {
+				location += "?" + pageObj.redirectQuery
+			}
+		}
+		status := pageObj.RedirectStatus
+		if status == 0 {
+			status = http.StatusMovedPermanently
+		}
+		w.Header().Set("Location", location)
+		w.WriteHeader(status)
+		return true
+	}
+	if pageObj.IsNotFound && pageObj.Body == "" {
+		http.NotFound(w, nil)
+		return true
+	}
+	var body io.Reader = strings.NewReader(pageObj.Body)
+	if pageObj.StaticFile != "" && pageObj.stream == nil {
+		var err error
+		if pageObj.stream, err = g.store.Get(ctx, pageObj.StaticFile); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				http.NotFound(w, nil)
+			} else {
+				log.Error("static file get error", zap.Error(err))
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return false
+		}
+	}
+	if pageObj.stream != nil {
+		body = pageObj.stream
+	}
+	status := http.StatusOK
+	if pageObj.IsNotFound {
+		status = http.StatusNotFound
+	}
+	contentType := pageObj.ContentType
+	if contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Error("page write error", zap.Error(err))
+	}
+	return true
+}
+
+// cacheGet returns the cached page, hits don't extend the ttl, so the page never outlives the
+// set tracking it and the invalidation on the visibility change or the takedown always reaches it
 func (g *gateway) cacheGet(ctx context.Context, key cacheId) (res *pageObject, err error) {
-	var results = make([]*redis.StringCmd, 3)
+	var results = make([]*redis.StringCmd, 4)
 	_, err = g.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		redisKey := "{" + string(key) + "}"
-		results[0] = pipe.GetEx(ctx, redisKey+":rver", time.Hour)
-		results[1] = pipe.GetEx(ctx, redisKey+":notfound", time.Hour)
-		results[2] = pipe.GetEx(ctx, redisKey+":body", time.Hour)
+		results[0] = pipe.Get(ctx, redisKey+":rver")
+		results[1] = pipe.Get(ctx, redisKey+":notfound")
+		results[2] = pipe.Get(ctx, redisKey+":body")
+		results[3] = pipe.Get(ctx, redisKey+":meta")
 		return nil
 	})
 
@@ -198,11 +550,22 @@ func (g *gateway) cacheGet(ctx context.Context, key cacheId) (res *pageObject, e
 		IsNotFound: results[1].Val() == "1",
 		RenderVer:  results[0].Val(),
 	}
+	if meta := results[3].Val(); meta != "" {
+		if err = json.Unmarshal([]byte(meta), &obj.pageMeta); err != nil {
+			return nil, err
+		}
+	}
 
 	return obj, nil
 }
 
 func (g *gateway) cacheSet(ctx context.Context, key cacheId, data *pageObject) (err error) {
+	var meta []byte
+	if !data.pageMeta.isEmpty() {
+		if meta, err = json.Marshal(data.pageMeta); err != nil {
+			return
+		}
+	}
 	results, err := g.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		isNotFound := "0"
 		if data.IsNotFound {
@@ -211,11 +574,23 @@ func (g *gateway) cacheSet(ctx context.Context, key cacheId, data *pageObject) (
 		redisKey := "{" + string(key) + "}"
 		pipe.SetEx(ctx, redisKey+":rver", data.RenderVer, time.Hour)
 		pipe.SetEx(ctx, redisKey+":notfound", isNotFound, time.Hour)
+		pipe.SetEx(ctx, redisKey+":meta", meta, time.Hour)
 
 		bodyBytes := unsafe.Slice(unsafe.StringData(data.Body), len(data.Body))
 		sBody := snappy.Encode(nil, bodyBytes)
 		log.Debug("body size", zap.Int("before", len(data.Body)), zap.Int("after", len(sBody)))
 		pipe.SetEx(ctx, redisKey+":body", sBody, time.Hour)
+		trackKeys := []string{invalidationKey(key.Identity(), key.Uri(), data.parentUri)}
+		if data.IsNotFound {
+			// not found pages render the 404 page of the identity, they're dropped by the invalidation of the root uri
+			trackKeys = append(trackKeys, "{"+string(newCacheId(key.Identity(), "", false, ""))+"}:subpaths")
+		}
+		for _, trackKey := range trackKeys {
+			if trackKey != "" {
+				pipe.SAdd(ctx, trackKey, string(key))
+				pipe.Expire(ctx, trackKey, 2*time.Hour)
+			}
+		}
 		return nil
 	})
 
@@ -239,32 +614,324 @@ func (g *gateway) getIdentity(ctx context.Context, name string) (identity string
 	return obj.OwnerAnyAddress, nil
 }
 
-func (g *gateway) renderPage(ctx context.Context, id cacheId) (*pageObject, error) {
+// renderPageFor renders the page for the signed-in viewer, private pages the viewer can't read are rendered as the members-only marker
+func (g *gateway) renderPageFor(ctx context.Context, id cacheId, viewer string) (*pageObject, error) {
 	cId := id
 	identity := cId.Identity()
 	uri := cId.Uri()
-	pub, err := g.publish.ResolveUriWithIdentity(ctx, identity, uri)
+	pub, err := g.resolveUri(ctx, identity, uri)
 	if err != nil {
 		if errors.Is(err, publishapi.ErrNotFound) {
-			return &pageObject{IsNotFound: true}, nil
+			return g.renderSubPath(ctx, id, viewer)
 		} else {
 			return nil, err
 		}
 	}
-	if pub.ActivePublishId == nil {
+	if pub.Publish == nil {
+		return g.renderNotFoundPage(ctx, id)
+	}
+	if pageObj, err := g.renderBlocked(ctx, pub); pageObj != nil || err != nil {
+		return pageObj, err
+	}
+	if !canView(pub, viewer) {
+		return membersOnlyPage(), nil
+	}
+	return g.renderObject(ctx, id, pub)
+}
+
+// renderBlocked returns the page of the banned or taken down publish, nil if the publish can be served
+func (g *gateway) renderBlocked(ctx context.Context, pub domain.ObjectWithPublish) (*pageObject, error) {
+	if g.banList.IsBanned(domain.BanKindIdentity, pub.Identity) || g.banList.IsBanned(domain.BanKindPublish, pub.Publish.Id.Hex()) {
+		return &pageObject{pageMeta: pageMeta{TakedownStatus: http.StatusUnavailableForLegalReasons}}, nil
+	}
+	takedown, err := g.moderation.GetTakedown(ctx, pub.Object)
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pageObject{pageMeta: pageMeta{TakedownStatus: takedown.Status}}, nil
+}
+
+// renderObject renders the resolved publish of the uri
+func (g *gateway) renderObject(ctx context.Context, id cacheId, pub domain.ObjectWithPublish) (pageObj *pageObject, err error) {
+	uri := id.Uri()
+	pageObj = matchRedirectRule(pub, uri, "/")
+	if pageObj == nil && pub.Publish.Type == domain.PublishTypeStatic {
+		// relative links of the static site require the trailing slash
+		pageObj = &pageObject{pageMeta: pageMeta{RedirectUri: uri + "/", RedirectStatus: http.StatusFound}}
+	}
+	if pageObj == nil {
+		if pageObj, err = g.renderPublish(ctx, pub.Publish.Id, id.Identity(), id.WithName()); err != nil {
+			return nil, err
+		}
+		if pub.Publish.Rules != nil {
+			pageObj.Headers = publishrules.MatchHeaders(pub.Publish.Rules.Headers, "/")
+		}
+	}
+	setVisibilityHeaders(pageObj, pub.Object)
+	return pageObj, nil
+}
+
+// setVisibilityHeaders hides unlisted pages from search engines
+func setVisibilityHeaders(pageObj *pageObject, obj domain.Object) {
+	if obj.Visibility != domain.VisibilityUnlisted {
+		return
+	}
+	if pageObj.Headers == nil {
+		pageObj.Headers = make(http.Header)
+	}
+	pageObj.Headers.Set("X-Robots-Tag", "noindex")
+}
+
+// resolveUri returns the live publish of the uri or the publish version for the {uri}@{version} permalink
+func (g *gateway) resolveUri(ctx context.Context, identity, uri string) (domain.ObjectWithPublish, error) {
+	pub, err := g.publish.ResolveUriWithIdentity(ctx, identity, uri)
+	if !errors.Is(err, publishapi.ErrNotFound) {
+		return pub, err
+	}
+	if base, version, ok := cutVersion(uri); ok {
+		return g.publish.ResolveVersion(ctx, identity, base, version)
+	}
+	return pub, err
+}
+
+// renderSubPath serves the uri under the nearest published parent uri: applies its redirect rules or serves a static site file
+func (g *gateway) renderSubPath(ctx context.Context, id cacheId, viewer string) (*pageObject, error) {
+	uri := id.Uri()
+	pub, parentUri, err := g.resolveParent(ctx, id.Identity(), uri)
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			return g.renderRedirectOrNotFound(ctx, id)
+		}
+		return nil, err
+	}
+	pageObj, err := g.renderBlocked(ctx, pub)
+	if err != nil {
+		return nil, err
+	}
+	if pageObj == nil {
+		if !canView(pub, viewer) {
+			pageObj = membersOnlyPage()
+		} else if pageObj, err = g.renderPublishSubPath(ctx, pub, uri, uri[len(parentUri):]); err != nil {
+			return nil, err
+		}
+	}
+	if pageObj == nil {
+		if pageObj, err = g.renderRedirectOrNotFound(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	setVisibilityHeaders(pageObj, pub.Object)
+	// the cached sub path is invalidated together with the parent uri
+	pageObj.parentUri = parentUri
+	return pageObj, nil
+}
+
+// resolveParent returns the live publish of the nearest published parent uri
+func (g *gateway) resolveParent(ctx context.Context, identity, uri string) (pub domain.ObjectWithPublish, parentUri string, err error) {
+	parentUri = uri
+	for range maxSubPathDepth {
+		idx := strings.LastIndex(parentUri, "/")
+		if idx <= 0 {
+			break
+		}
+		parentUri = parentUri[:idx]
+		if pub, err = g.resolveUri(ctx, identity, parentUri); err != nil {
+			if errors.Is(err, publishapi.ErrNotFound) {
+				continue
+			}
+			return
+		}
+		if pub.Publish == nil {
+			break
+		}
+		return pub, parentUri, nil
+	}
+	return pub, "", publishapi.ErrNotFound
+}
+
+// renderPublishSubPath applies redirect rules of the publish to the sub path or serves the static site file, returns nil if nothing matched
+func (g *gateway) renderPublishSubPath(ctx context.Context, pub domain.ObjectWithPublish, uri, subPath string) (*pageObject, error) {
+	if pageObj := matchRedirectRule(pub, uri, subPath); pageObj != nil {
+		return pageObj, nil
+	}
+	if pub.Publish.Type != domain.PublishTypeStatic {
+		return nil, nil
+	}
+	pageObj, err := g.renderStaticFile(ctx, uri, pub.Publish, strings.TrimPrefix(subPath, "/"))
+	if err != nil || pageObj == nil {
+		return nil, err
+	}
+	if pageObj.RedirectUri == "" && pub.Publish.Rules != nil {
+		pageObj.Headers = publishrules.MatchHeaders(pub.Publish.Rules.Headers, subPath)
+	}
+	return pageObj, nil
+}
+
+// renderStaticFile serves the file of the static site with the directory index and the optional SPA fallback
+func (g *gateway) renderStaticFile(ctx context.Context, uri string, pub *domain.Publish, name string) (*pageObject, error) {
+	if name == "" || strings.HasSuffix(name, "/") {
+		name += staticIndexName
+	}
+	if path.Clean("/"+name) != "/"+name {
+		return nil, nil
+	}
+	pageObj, err := g.openStaticFile(ctx, pub.Id, name)
+	if pageObj != nil || err != nil {
+		return pageObj, err
+	}
+	if path.Base(name) != staticIndexName {
+		// redirect the directory to the trailing slash if it has the index
+		if pageObj, err = g.openStaticFile(ctx, pub.Id, name+"/"+staticIndexName); err != nil {
+			return nil, err
+		}
+		if pageObj != nil {
+			pageObj.close()
+			return &pageObject{pageMeta: pageMeta{RedirectUri: uri + "/"}}, nil
+		}
+	}
+	if pub.SpaFallback && (path.Ext(name) == "" || path.Ext(name) == ".html") {
+		if pageObj, err = g.openStaticFile(ctx, pub.Id, staticIndexName); pageObj != nil || err != nil {
+			return pageObj, err
+		}
+	}
+	if pageObj, err = g.openStaticFile(ctx, pub.Id, publish.NotFoundHtmlName); pageObj != nil {
+		pageObj.IsNotFound = true
+	}
+	return pageObj, err
+}
+
+// openStaticFile reads the small file to the page body or keeps the reader to stream the big one, returns nil if the file doesn't exist
+func (g *gateway) openStaticFile(ctx context.Context, publishId primitive.ObjectID, name string) (*pageObject, error) {
+	key := publishId.Hex() + "/" + name
+	rd, err := g.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	pageObj := &pageObject{
+		RenderVer: g.renderVersion,
+		pageMeta:  pageMeta{ContentType: contentType, Sandbox: true},
+	}
+	body, err := io.ReadAll(io.LimitReader(rd, maxStaticBodySize+1))
+	if err != nil {
+		_ = rd.Close()
+		return nil, err
+	}
+	if len(body) <= maxStaticBodySize {
+		_ = rd.Close()
+		pageObj.Body = string(body)
+		return pageObj, nil
+	}
+	pageObj.StaticFile = key
+	pageObj.stream = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rd), rd}
+	return pageObj, nil
+}
+
+// matchRedirectRule returns the redirect page object if the path relative to the publish uri matches a redirect rule
+func matchRedirectRule(pub domain.ObjectWithPublish, uri, rulePath string) *pageObject {
+	if pub.Publish.Rules == nil {
+		return nil
+	}
+	rule, to, ok := publishrules.MatchRedirect(pub.Publish.Rules.Redirects, rulePath)
+	if !ok {
+		return nil
+	}
+	pageObj := &pageObject{pageMeta: pageMeta{RedirectStatus: rule.Status}}
+	if !strings.HasPrefix(to, "/") {
+		pageObj.RedirectUrl = to
+		return pageObj
+	}
+	pageObj.RedirectUri = strings.TrimPrefix(path.Join(pub.Uri, to), "/")
+	if pageObj.RedirectUri == uri {
+		// the rule points to itself
+		return nil
+	}
+	return pageObj
+}
+
+// renderRedirectOrNotFound redirects the previous uri of the object to the current one or renders the 404 page
+func (g *gateway) renderRedirectOrNotFound(ctx context.Context, id cacheId) (*pageObject, error) {
+	redirect, err := g.publish.ResolveRedirect(ctx, id.Identity(), id.Uri())
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			return g.renderNotFoundPage(ctx, id)
+		}
+		return nil, err
+	}
+	return &pageObject{pageMeta: pageMeta{RedirectUri: redirect.TargetUri}}, nil
+}
+
+// renderNotFoundPage renders the 404 page chosen by the identity owner or returns an empty not found object
+func (g *gateway) renderNotFoundPage(ctx context.Context, id cacheId) (*pageObject, error) {
+	pub, err := g.publish.ResolveNotFoundPage(ctx, id.Identity())
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			return &pageObject{IsNotFound: true}, nil
+		}
+		return nil, err
+	}
+	if pub.Publish == nil || pub.Visibility == domain.VisibilityPrivate {
 		return &pageObject{IsNotFound: true}, nil
 	}
+	// the banned or taken down 404 page falls back to the plain one
+	blocked, err := g.renderBlocked(ctx, pub)
+	if err != nil {
+		return nil, err
+	}
+	if blocked != nil {
+		return &pageObject{IsNotFound: true}, nil
+	}
+	if !pub.Publish.NotFoundHtml {
+		pageObj, err := g.renderPublish(ctx, pub.Publish.Id, id.Identity(), id.WithName())
+		if err != nil {
+			return nil, err
+		}
+		pageObj.IsNotFound = true
+		return pageObj, nil
+	}
+	rd, err := g.store.Get(ctx, pub.Publish.Id.Hex()+"/"+publish.NotFoundHtmlName)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return &pageObject{IsNotFound: true}, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = rd.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(rd, maxNotFoundHtmlSize))
+	if err != nil {
+		return nil, err
+	}
+	return &pageObject{
+		Body:       string(body),
+		RenderVer:  g.renderVersion,
+		IsNotFound: true,
+		pageMeta:   pageMeta{Sandbox: true},
+	}, nil
+}
 
-	publicFilesPath, err := url.JoinPath(g.config.PublishFilesURL, pub.ActivePublishId.Hex())
+func (g *gateway) renderPublish(ctx context.Context, publishId primitive.ObjectID, identity string, withName bool) (*pageObject, error) {
+	publicFilesPath, err := url.JoinPath(g.config.PublishFilesURL, publishId.Hex())
 	if err != nil {
 		return nil, err
 	}
 
-	var analyticsCode string
-	if cId.WithName() {
-		analyticsCode = g.config.AnalyticsCodeMembers
-	} else {
-		analyticsCode = g.config.AnalyticsCode
+	analyticsCode, err := g.analyticsCode(ctx, identity, withName)
+	if err != nil {
+		return nil, err
 	}
 
 	config := renderer.RenderConfig{
@@ -287,34 +954,141 @@ func (g *gateway) renderPage(ctx context.Context, id cacheId) (*pageObject, erro
 	return &pageObject{
 		Body:      buf.String(),
 		RenderVer: g.renderVersion,
+		pageMeta:  pageMeta{Analytics: analyticsHash(analyticsCode)},
 	}, nil
 }
 
-func (g *gateway) invalidateCache(identity, uri string) {
-	withName := "{" + string(newCacheId(identity, uri, true)) + "}"
-	withoutName := "{" + string(newCacheId(identity, uri, false)) + "}"
-	for _, key := range []string{withName, withoutName} {
-		err := g.redisClient.Del(
-			context.Background(),
+// analyticsCode returns the analytics snippet of the identity or the global one
+func (g *gateway) analyticsCode(ctx context.Context, identity string, withName bool) (string, error) {
+	snippet, err := g.analytics.Snippet(ctx, identity)
+	if err != nil || snippet != "" {
+		return snippet, err
+	}
+	if withName {
+		return g.config.AnalyticsCodeMembers, nil
+	}
+	return g.config.AnalyticsCode, nil
+}
+
+// analyticsHash identifies the analytics snippet of the cached page
+func analyticsHash(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:8])
+}
+
+// invalidateCache drops the cached page with its sub paths, withVersions drops the version permalinks of the uri too
+func (g *gateway) invalidateCache(identity, uri string, withVersions bool) {
+	ids := append([]cacheId{
+		newCacheId(identity, uri, true, ""),
+		newCacheId(identity, uri, false, ""),
+	}, g.hostCacheIds(identity, uri)...)
+	ctx := context.Background()
+	trackKeys := []string{"{" + string(newCacheId(identity, uri, false, "")) + "}:subpaths"}
+	if withVersions {
+		trackKeys = append(trackKeys, "{"+string(newCacheId(identity, uri, false, ""))+"}:versions")
+	}
+	for _, trackKey := range trackKeys {
+		tracked, err := g.redisClient.SMembers(ctx, trackKey).Result()
+		if err != nil {
+			log.Error("cache get tracked pages error", zap.Error(err))
+		}
+		for _, id := range tracked {
+			ids = append(ids, cacheId(id))
+		}
+	}
+	var err error
+	for _, id := range ids {
+		key := "{" + string(id) + "}"
+		err = g.redisClient.Del(
+			ctx,
 			key+":rver",
 			key+":notfound",
 			key+":body",
+			key+":meta",
 		).Err()
 		if err != nil {
 			log.Error("cache invalidate error", zap.Error(err))
 		}
 	}
+	if err = g.redisClient.Del(ctx, trackKeys...).Err(); err != nil {
+		log.Error("cache invalidate error", zap.Error(err))
+	}
+}
+
+// NotifyInvalidate asks running gateways to invalidate the cached page, it's for tools working outside the gateway process
+func NotifyInvalidate(ctx context.Context, redisClient redis.UniversalClient, identity, uri string, withVersions bool) error {
+	channel := invalidateChannel
+	if withVersions {
+		channel = invalidateVersionsChannel
+	}
+	return redisClient.Publish(ctx, channel, identity+"/"+uri).Err()
+}
+
+func (g *gateway) listenInvalidate(ch <-chan *redis.Message) {
+	for msg := range ch {
+		if identity, uri, ok := strings.Cut(msg.Payload, "/"); ok {
+			g.invalidateCache(identity, uri, msg.Channel == invalidateVersionsChannel)
+		}
+	}
+}
+
+// hostCacheIds returns cache ids of the page served via the name subdomain and verified custom domains
+func (g *gateway) hostCacheIds(identity, uri string) (ids []cacheId) {
+	ctx := context.Background()
+	if g.domain != "" {
+		name, err := g.nameService.ResolveIdentity(ctx, identity)
+		if err == nil {
+			ids = append(ids, newCacheId(identity, uri, true, strings.TrimSuffix(name, ".any")+"."+g.domain))
+		} else if !errors.Is(err, ocache.ErrNotExists) {
+			log.Warn("can't resolve name for cache invalidation", zap.Error(err))
+		}
+	}
+	customDomains, err := g.customDomain.List(ctx, identity)
+	if err != nil {
+		log.Warn("can't list custom domains for cache invalidation", zap.Error(err))
+	}
+	for _, customDomain := range customDomains {
+		if customDomain.Verified {
+			ids = append(ids, newCacheId(identity, uri, false, customDomain.Host))
+		}
+	}
+	return
 }
 
 func (g *gateway) Close(ctx context.Context) (err error) {
+	if g.invalidateSub != nil {
+		_ = g.invalidateSub.Close()
+	}
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
+	if g.tlsServer != nil {
+		if err = g.tlsServer.Shutdown(ctx); err != nil {
+			log.Warn("tls server shutdown error", zap.Error(err))
+		}
+	}
 	return g.server.Shutdown(ctx)
 }
 
+const (
+	maxNotFoundHtmlSize = 1 << 20
+	// maxSubPathDepth limits the parent uris checked for redirect rules and static sites
+	maxSubPathDepth = 8
+	// maxStaticBodySize is the max size of the static file kept in the cache, bigger files are streamed from the store
+	maxStaticBodySize = 1 << 20
+	staticIndexName   = "index.html"
+	// sandboxPolicy isolates the uploaded files from the gateway origin, scripts and forms still work
+	sandboxPolicy = "sandbox allow-scripts allow-forms allow-popups allow-popups-to-escape-sandbox allow-modals allow-downloads"
+	// shareCookieName keeps the share link signature for the sub paths of the shared static site
+	shareCookieName = "anytype_publish_share"
+	// invalidateChannel delivers cache invalidations from tools working outside the gateway process
+	invalidateChannel = "gateway:invalidate"
+	// invalidateVersionsChannel delivers invalidations dropping the version permalinks too
+	invalidateVersionsChannel = "gateway:invalidateVersions"
+)
+
 var cacheIdSep = string([]byte{0})
 
-func newCacheId(identity, uri string, withName bool) cacheId {
+func newCacheId(identity, uri string, withName bool, host string) cacheId {
 	var res strings.Builder
 	res.WriteString(identity)
 	res.WriteString(cacheIdSep)
@@ -325,6 +1099,8 @@ func newCacheId(identity, uri string, withName bool) cacheId {
 	} else {
 		res.WriteString("0")
 	}
+	res.WriteString(cacheIdSep)
+	res.WriteString(host)
 	return cacheId(res.String())
 }
 
@@ -342,7 +1118,7 @@ func (c cacheId) WithName() bool {
 	return c.getElement(3) == "1"
 }
 
-func (c cacheId) RenderVersion() string {
+func (c cacheId) Host() string {
 	return c.getElement(4)
 }
 
@@ -372,6 +1148,153 @@ type pageObject struct {
 	Body       string
 	RenderVer  string
 	IsNotFound bool
+	pageMeta
+
+	// parentUri is the published uri the sub path page depends on, not cached
+	parentUri string
+	// stream is the opened static file bigger than maxStaticBodySize, not cached
+	stream io.ReadCloser
+	// redirectQuery is kept on the redirect to the page uri, e.g. the share link signature
+	redirectQuery string
+}
+
+func (p *pageObject) close() {
+	if p.stream != nil {
+		_ = p.stream.Close()
+	}
+}
+
+// pageMeta holds the response parameters besides the body, it's cached as json
+type pageMeta struct {
+	// RedirectUri is the uri of the same identity relative to the route base path
+	RedirectUri    string      `json:"redirectUri,omitempty"`
+	RedirectUrl    string      `json:"redirectUrl,omitempty"`
+	RedirectStatus int         `json:"redirectStatus,omitempty"`
+	Headers        http.Header `json:"headers,omitempty"`
+	// ContentType of the static site file, text/html when empty
+	ContentType string `json:"contentType,omitempty"`
+	// StaticFile is the store key of the static site file streamed on every request
+	StaticFile string `json:"staticFile,omitempty"`
+	// MembersOnly marks the private page, it has no content and is served only to signed-in members
+	MembersOnly bool `json:"membersOnly,omitempty"`
+	// Analytics is the hash of the analytics snippet of the rendered page
+	Analytics string `json:"analytics,omitempty"`
+	// TakedownStatus is the http status of the taken down page
+	TakedownStatus int `json:"takedownStatus,omitempty"`
+	// Sandbox marks the uploaded file served as is, e.g. the static site file or the raw 404.html
+	Sandbox bool `json:"sandbox,omitempty"`
+}
+
+// isView checks whether the page is counted as a page view: html pages, not redirects, errors or assets
+func (p *pageObject) isView() bool {
+	return !p.IsNotFound && p.RedirectUri == "" && p.RedirectUrl == "" && p.TakedownStatus == 0 &&
+		(p.ContentType == "" || strings.HasPrefix(p.ContentType, "text/html"))
+}
+
+func (m pageMeta) isEmpty() bool {
+	return m.RedirectUri == "" && m.RedirectUrl == "" && m.RedirectStatus == 0 && len(m.Headers) == 0 &&
+		m.ContentType == "" && m.StaticFile == "" && !m.MembersOnly && m.Analytics == "" && m.TakedownStatus == 0 && !m.Sandbox
+}
+
+func membersOnlyPage() *pageObject {
+	return &pageObject{pageMeta: pageMeta{MembersOnly: true}}
+}
+
+// cutVersion splits the {uri}@{version} permalink, the version is a publish version or a publish id
+func cutVersion(uri string) (base, version string, ok bool) {
+	idx := strings.LastIndex(uri, "@")
+	if idx <= 0 || idx == len(uri)-1 || strings.Contains(uri[idx:], "/") {
+		return "", "", false
+	}
+	return uri[:idx], uri[idx+1:], true
+}
+
+// invalidationKey returns the set tracking the cached page for the invalidation, empty if the page has only its own key.
+// Sub paths are tracked in the subpaths set of the parent uri. Version permalinks and their sub paths are tracked
+// in the versions set of the base uri, so republishing keeps them and only the invalidation with versions drops them
+func invalidationKey(identity, uri, parentUri string) string {
+	if parentUri != "" {
+		uri = parentUri
+	}
+	if base, _, ok := cutVersion(uri); ok {
+		return "{" + string(newCacheId(identity, base, false, "")) + "}:versions"
+	}
+	if parentUri == "" {
+		return ""
+	}
+	return "{" + string(newCacheId(identity, parentUri, false, "")) + "}:subpaths"
+}
+
+// requestHost returns the lowercase request host without port
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+func writeBanned(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Error(w, http.StatusText(http.StatusUnavailableForLegalReasons), http.StatusUnavailableForLegalReasons)
+}
+
+// visitorIp returns the client ip, X-Forwarded-For is honored only when the request comes from the trusted proxy
+func visitorIp(r *http.Request, proxies []netip.Prefix) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip, proxies) {
+		return ip
+	}
+	// the rightmost address not added by the trusted proxies is the client
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrustedProxy(ip, proxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(ip string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxies parses the trusted proxies, the single ip is the prefix of its full length
+func parseProxies(proxies []string) (prefixes []netip.Prefix, err error) {
+	for _, proxy := range proxies {
+		var prefix netip.Prefix
+		if strings.Contains(proxy, "/") {
+			prefix, err = netip.ParsePrefix(proxy)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(proxy); err == nil {
+				prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return
 }
 
 func renderVersion() string {
@@ -1,15 +1,306 @@
 package gateway
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/anyproto/any-sync/app"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publish/sharelink"
+	"github.com/anyproto/anytype-publish-server/store"
 )
 
 func Test_cacheId_getElement(t *testing.T) {
-	id := newCacheId("identity", "uri/a/b", true)
+	id := newCacheId("identity", "uri/a/b", true, "")
 	assert.Equal(t, "identity", id.Identity())
 	assert.Equal(t, "uri/a/b", id.Uri())
 	assert.True(t, id.WithName())
-	assert.Equal(t, "identity/uri/a/b/1", id.String())
+	assert.Empty(t, id.Host())
+	assert.Equal(t, "identity/uri/a/b/1/", id.String())
+
+	id = newCacheId("identity", "uri", false, "example.com")
+	assert.Equal(t, "uri", id.Uri())
+	assert.False(t, id.WithName())
+	assert.Equal(t, "example.com", id.Host())
+}
+
+func Test_requestHost(t *testing.T) {
+	for host, exp := range map[string]string{
+		"Example.com":      "example.com",
+		"example.com:8080": "example.com",
+		"127.0.0.1:8380":   "127.0.0.1",
+		"[::1]:8380":       "::1",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		assert.Equal(t, exp, requestHost(r))
+	}
+}
+
+func Test_matchRedirectRule(t *testing.T) {
+	pub := domain.ObjectWithPublish{
+		Object: domain.Object{Uri: "blog"},
+		Publish: &domain.Publish{Rules: &domain.PublishRules{Redirects: []domain.RedirectRule{
+			{From: "/old/*", To: "/posts/:splat", Status: http.StatusFound},
+			{From: "/ext", To: "https://example.com", Status: http.StatusMovedPermanently},
+			{From: "/self", To: "/self", Status: http.StatusMovedPermanently},
+		}}},
+	}
+	pageObj := matchRedirectRule(pub, "blog/old/a", "/old/a")
+	require.NotNil(t, pageObj)
+	assert.Equal(t, "blog/posts/a", pageObj.RedirectUri)
+	assert.Equal(t, http.StatusFound, pageObj.RedirectStatus)
+
+	pageObj = matchRedirectRule(pub, "blog/ext", "/ext")
+	require.NotNil(t, pageObj)
+	assert.Equal(t, "https://example.com", pageObj.RedirectUrl)
+
+	assert.Nil(t, matchRedirectRule(pub, "blog/self", "/self"))
+	assert.Nil(t, matchRedirectRule(pub, "blog/other", "/other"))
+}
+
+type testStore map[string]string
+
+func (s testStore) Init(a *app.App) error { return nil }
+func (s testStore) Name() string          { return store.CName }
+func (s testStore) Put(ctx context.Context, file store.File) error {
+	data, err := io.ReadAll(file.Reader)
+	s[file.Name] = string(data)
+	return err
+}
+func (s testStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+func (s testStore) DeletePath(ctx context.Context, path string) error { return nil }
+func (s testStore) ListPrefixes(ctx context.Context, do func(prefix string) error) error {
+	return nil
+}
+
+func Test_renderStaticFile(t *testing.T) {
+	ctx := context.Background()
+	pub := &domain.Publish{Id: primitive.NewObjectID(), Type: domain.PublishTypeStatic}
+	prefix := pub.Id.Hex() + "/"
+	g := &gateway{store: testStore{
+		prefix + "index.html":       "root",
+		prefix + "guide/index.html": "guide",
+		prefix + "style.css":        "css",
+		prefix + "big.bin":          strings.Repeat("a", maxStaticBodySize+1),
+	}}
+
+	t.Run("index", func(t *testing.T) {
+		pageObj, err := g.renderStaticFile(ctx, "docs/", pub, "")
+		require.NoError(t, err)
+		assert.Equal(t, "root", pageObj.Body)
+		assert.Equal(t, "text/html; charset=utf-8", pageObj.ContentType)
+		assert.True(t, pageObj.Sandbox)
+	})
+	t.Run("asset", func(t *testing.T) {
+		pageObj, err := g.renderStaticFile(ctx, "docs/style.css", pub, "style.css")
+		require.NoError(t, err)
+		assert.Equal(t, "css", pageObj.Body)
+		assert.Equal(t, "text/css; charset=utf-8", pageObj.ContentType)
+	})
+	t.Run("directory redirect", func(t *testing.T) {
+		pageObj, err := g.renderStaticFile(ctx, "docs/guide", pub, "guide")
+		require.NoError(t, err)
+		assert.Equal(t, "docs/guide/", pageObj.RedirectUri)
+	})
+	t.Run("big file is streamed", func(t *testing.T) {
+		pageObj, err := g.renderStaticFile(ctx, "docs/big.bin", pub, "big.bin")
+		require.NoError(t, err)
+		defer pageObj.close()
+		assert.Empty(t, pageObj.Body)
+		assert.Equal(t, prefix+"big.bin", pageObj.StaticFile)
+		data, err := io.ReadAll(pageObj.stream)
+		require.NoError(t, err)
+		assert.Len(t, data, maxStaticBodySize+1)
+	})
+	t.Run("not found", func(t *testing.T) {
+		pageObj, err := g.renderStaticFile(ctx, "docs/app/route", pub, "app/route")
+		require.NoError(t, err)
+		assert.Nil(t, pageObj)
+	})
+	t.Run("spa fallback", func(t *testing.T) {
+		spaPub := *pub
+		spaPub.SpaFallback = true
+		pageObj, err := g.renderStaticFile(ctx, "docs/app/route", &spaPub, "app/route")
+		require.NoError(t, err)
+		assert.Equal(t, "root", pageObj.Body)
+		assert.False(t, pageObj.IsNotFound)
+
+		pageObj, err = g.renderStaticFile(ctx, "docs/missing.css", &spaPub, "missing.css")
+		require.NoError(t, err)
+		assert.Nil(t, pageObj)
+	})
+}
+
+func Test_renderPreview(t *testing.T) {
+	ctx := context.Background()
+	pub := domain.ObjectWithPublish{Publish: &domain.Publish{
+		Id:    primitive.NewObjectID(),
+		Type:  domain.PublishTypeStatic,
+		Rules: &domain.PublishRules{Headers: []domain.HeaderRule{{Path: "/*", Headers: []domain.Header{{Name: "X-A", Value: "1"}}}}},
+	}}
+	prefix := pub.Publish.Id.Hex() + "/"
+	g := &gateway{store: testStore{
+		prefix + "index.html":       "root",
+		prefix + "guide/index.html": "guide",
+	}}
+
+	pageObj, err := g.renderPreview(ctx, pub, "")
+	require.NoError(t, err)
+	assert.Equal(t, "root", pageObj.Body)
+	assert.Equal(t, "1", pageObj.Headers.Get("X-A"))
+
+	pageObj, err = g.renderPreview(ctx, pub, "guide")
+	require.NoError(t, err)
+	assert.Equal(t, "guide/", pageObj.RedirectUri)
+
+	pageObj, err = g.renderPreview(ctx, pub, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, pageObj)
+
+	pub.Publish.Type = domain.PublishTypeRendered
+	pageObj, err = g.renderPreview(ctx, pub, "sub")
+	require.NoError(t, err)
+	assert.Nil(t, pageObj)
+}
+
+func Test_writePage(t *testing.T) {
+	g := &gateway{}
+	w := httptest.NewRecorder()
+	w.Header().Set("Cache-Control", "private, no-store")
+	pageObj := &pageObject{Body: "page"}
+	pageObj.Headers = http.Header{
+		"Cache-Control":   {"public, max-age=86400"},
+		"X-Frame-Options": {"DENY"},
+	}
+	require.True(t, g.writePage(context.Background(), w, pageObj, "/"))
+	assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "page", w.Body.String())
+}
+
+func Test_writePage_sandbox(t *testing.T) {
+	g := &gateway{}
+	w := httptest.NewRecorder()
+	pageObj := &pageObject{Body: "<script></script>"}
+	pageObj.Sandbox = true
+	pageObj.Headers = http.Header{"Content-Security-Policy": {"default-src *"}}
+	require.True(t, g.writePage(context.Background(), w, pageObj, "/"))
+	assert.Equal(t, sandboxPolicy, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func Test_writePage_redirectQuery(t *testing.T) {
+	g := &gateway{}
+	w := httptest.NewRecorder()
+	pageObj := &pageObject{redirectQuery: "exp=1&sig=s"}
+	pageObj.RedirectUri = "site/"
+	pageObj.RedirectStatus = http.StatusFound
+	require.True(t, g.writePage(context.Background(), w, pageObj, "/identity/"))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/identity/site/?exp=1&sig=s", w.Header().Get("Location"))
+}
+
+func Test_shareParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/identity/site?exp=1&sig=s", nil)
+	share, fromQuery := shareParams(r)
+	assert.True(t, fromQuery)
+	assert.Equal(t, "s", share.Get(sharelink.SigParam))
+
+	w := httptest.NewRecorder()
+	setShareCookie(w, "/identity/site/", url.Values{sharelink.ExpParam: {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}, sharelink.SigParam: {"s"}})
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "/identity/site/", cookies[0].Path)
+	assert.Greater(t, cookies[0].MaxAge, 0)
+
+	r = httptest.NewRequest(http.MethodGet, "/identity/site/app.js", nil)
+	r.AddCookie(cookies[0])
+	share, fromQuery = shareParams(r)
+	assert.False(t, fromQuery)
+	assert.Equal(t, "s", share.Get(sharelink.SigParam))
+
+	share, _ = shareParams(httptest.NewRequest(http.MethodGet, "/identity/site/app.js", nil))
+	assert.Nil(t, share)
+}
+
+func Test_cutVersion(t *testing.T) {
+	base, version, ok := cutVersion("blog/post@v2")
+	require.True(t, ok)
+	assert.Equal(t, "blog/post", base)
+	assert.Equal(t, "v2", version)
+
+	base, version, ok = cutVersion("me@mail/post@65f0c1a2b3c4d5e6f7a8b9c0")
+	require.True(t, ok)
+	assert.Equal(t, "me@mail/post", base)
+	assert.Equal(t, "65f0c1a2b3c4d5e6f7a8b9c0", version)
+
+	for _, uri := range []string{"post", "@v1", "post@", "post@v1/index.html"} {
+		_, _, ok = cutVersion(uri)
+		assert.False(t, ok, uri)
+	}
+}
+
+func Test_invalidationKey(t *testing.T) {
+	subPaths := func(uri string) string {
+		return "{" + string(newCacheId("a1", uri, false, "")) + "}:subpaths"
+	}
+	versions := func(uri string) string {
+		return "{" + string(newCacheId("a1", uri, false, "")) + "}:versions"
+	}
+	for _, c := range []struct {
+		uri, parentUri, expected string
+	}{
+		{"page", "", ""},
+		{"page/sub", "page", subPaths("page")},
+		{"page@v1", "", versions("page")},
+		{"page@v1/sub", "page@v1", versions("page")},
+	} {
+		assert.Equal(t, c.expected, invalidationKey("a1", c.uri, c.parentUri), c.uri)
+	}
+}
+
+func Test_visitorIp(t *testing.T) {
+	proxies, err := parseProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "10.0.0.1", visitorIp(r, proxies))
+	r.Header.Set("X-Forwarded-For", "5.6.7.8, 1.2.3.4, 10.0.0.2")
+	assert.Equal(t, "1.2.3.4", visitorIp(r, proxies))
+	assert.Equal(t, "10.0.0.1", visitorIp(r, nil))
+	r.RemoteAddr = "1.1.1.1:1234"
+	assert.Equal(t, "1.1.1.1", visitorIp(r, proxies))
+	r.RemoteAddr = "192.168.1.1:1234"
+	r.Header.Set("X-Forwarded-For", "10.0.0.3")
+	assert.Equal(t, "10.0.0.3", visitorIp(r, proxies))
+
+	_, err = parseProxies([]string{"proxy"})
+	assert.Error(t, err)
+}
+
+func Test_pageObject_isView(t *testing.T) {
+	assert.True(t, (&pageObject{Body: "page"}).isView())
+	assert.True(t, (&pageObject{pageMeta: pageMeta{ContentType: "text/html; charset=utf-8"}}).isView())
+	assert.False(t, (&pageObject{pageMeta: pageMeta{ContentType: "text/css; charset=utf-8"}}).isView())
+	assert.False(t, (&pageObject{IsNotFound: true}).isView())
+	assert.False(t, (&pageObject{pageMeta: pageMeta{RedirectUri: "a/"}}).isView())
+	assert.False(t, (&pageObject{pageMeta: pageMeta{TakedownStatus: http.StatusUnavailableForLegalReasons}}).isView())
 }
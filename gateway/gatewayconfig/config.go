@@ -13,4 +13,21 @@ type Config struct {
 	ServePublish         bool   `yaml:"servePublish"`
 	AnalyticsCode        string `yaml:"analyticsCode"`
 	AnalyticsCodeMembers string `yaml:"analyticsCodeMembers"`
+	TLS                  TLS    `yaml:"tls"`
+	// ShareLinkKey signs expiring share links, share links are disabled when empty
+	ShareLinkKey string `yaml:"shareLinkKey"`
+	// TrustedProxies are the ips or cidrs of the proxies whose X-Forwarded-For is honored
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+type TLS struct {
+	// Addr is the listen address of the https server, https is disabled when empty
+	Addr string `yaml:"addr"`
+	Acme Acme   `yaml:"acme"`
+}
+
+type Acme struct {
+	// DirectoryURL of the ACME server, certificates are not issued automatically when empty
+	DirectoryURL string `yaml:"directoryUrl"`
+	Email        string `yaml:"email"`
 }
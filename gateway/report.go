@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/moderation"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+const (
+	// reportPath answers POST on every host, so pages on custom domains can be reported too,
+	// other methods are served as the page
+	reportPath = "/report"
+
+	maxReportRequestSize = 8 << 10
+)
+
+type reportRequest struct {
+	// Url is the address of the reported page as the visitor sees it
+	Url     string `json:"url"`
+	Reason  string `json:"reason"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// pageUrl is the reported page address split by the gateway routes
+type pageUrl struct {
+	// name is set for the /name/{name}/ routes and {name}.{domain} hosts
+	name string
+	// identity is set for the /{identity}/ routes
+	identity string
+	// host is set for custom domains
+	host string
+	uri  string
+}
+
+// handleReport records the abuse report of the published page
+func (g *gateway) handleReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	var req reportRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReportRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	u, err := url.Parse(req.Url)
+	if err != nil || u.Host == "" {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	pub, err := g.resolveReportedPage(ctx, splitPageUrl(u, g.domain))
+	if err != nil {
+		if errors.Is(err, publishapi.ErrNotFound) {
+			http.Error(w, "page not found", http.StatusNotFound)
+		} else {
+			log.Error("resolve reported page error", zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+	err = g.moderation.Report(ctx, domain.Report{
+		Identity: pub.Identity,
+		SpaceId:  pub.SpaceId,
+		ObjectId: pub.ObjectId,
+		Uri:      pub.Uri,
+		Reason:   req.Reason,
+		Comment:  req.Comment,
+	}, visitorIp(r, g.proxies))
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusAccepted)
+	case errors.Is(err, moderation.ErrInvalidReport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, moderation.ErrRateLimited):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	default:
+		log.Error("report error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// resolveReportedPage finds the published object of the page, sub paths of static sites are reported to the nearest published parent uri
+func (g *gateway) resolveReportedPage(ctx context.Context, page pageUrl) (pub domain.ObjectWithPublish, err error) {
+	identity := page.identity
+	switch {
+	case page.name != "":
+		if identity, err = g.getIdentity(ctx, page.name); err != nil {
+			return pub, publishapi.ErrNotFound
+		}
+	case page.host != "":
+		if identity, err = g.customDomain.ResolveHost(ctx, page.host); err != nil {
+			return
+		}
+	case identity == "":
+		return pub, publishapi.ErrNotFound
+	}
+	uri := page.uri
+	for range maxSubPathDepth + 1 {
+		pub, err = g.resolveUri(ctx, identity, uri)
+		if err == nil && pub.Publish != nil {
+			return pub, nil
+		}
+		if err != nil && !errors.Is(err, publishapi.ErrNotFound) {
+			return
+		}
+		idx := strings.LastIndex(uri, "/")
+		if idx <= 0 {
+			break
+		}
+		uri = uri[:idx]
+	}
+	return pub, publishapi.ErrNotFound
+}
+
+// splitPageUrl splits the page address by the same rules as serveHTTP routes the request
+func splitPageUrl(u *url.URL, gatewayDomain string) pageUrl {
+	host := strings.ToLower(u.Hostname())
+	p := strings.Trim(u.Path, "/")
+	if host != gatewayDomain {
+		if gatewayDomain != "" {
+			if name, ok := strings.CutSuffix(host, "."+gatewayDomain); ok && name != "" && !strings.Contains(name, ".") {
+				return pageUrl{name: name, uri: p}
+			}
+		}
+		return pageUrl{host: host, uri: p}
+	}
+	first, rest, _ := strings.Cut(p, "/")
+	if first == "name" {
+		name, uri, _ := strings.Cut(rest, "/")
+		return pageUrl{name: name, uri: uri}
+	}
+	if first == "preview" || first == "static" {
+		return pageUrl{}
+	}
+	return pageUrl{identity: first, uri: rest}
+}
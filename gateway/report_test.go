@@ -0,0 +1,25 @@
+package gateway
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitPageUrl(t *testing.T) {
+	for rawUrl, exp := range map[string]pageUrl{
+		"https://any.coop/name/alice/page/a":  {name: "alice", uri: "page/a"},
+		"https://any.coop/A1b2/page":          {identity: "A1b2", uri: "page"},
+		"https://ANY.coop/A1b2/site/":         {identity: "A1b2", uri: "site"},
+		"https://any.coop/preview/id/key/":    {},
+		"https://alice.any.coop/page?x=1":     {name: "alice", uri: "page"},
+		"https://example.com:8443/blog/post/": {host: "example.com", uri: "blog/post"},
+		"https://a.b.any.coop/page":           {host: "a.b.any.coop", uri: "page"},
+	} {
+		u, err := url.Parse(rawUrl)
+		require.NoError(t, err)
+		assert.Equal(t, exp, splitPageUrl(u, "any.coop"), rawUrl)
+	}
+}
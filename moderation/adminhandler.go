@@ -0,0 +1,102 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func (m *moderation) registerAdminHandlers(a admin.Admin) {
+	a.Handle("GET /admin/reports", http.HandlerFunc(m.handleListReports))
+	a.Handle("POST /admin/reports/{id}/dismiss", http.HandlerFunc(m.handleDismissReport))
+	a.Handle("GET /admin/takedowns", http.HandlerFunc(m.handleListTakedowns))
+	a.Handle("POST /admin/takedowns", http.HandlerFunc(m.handleTakedown))
+	a.Handle("DELETE /admin/takedowns/{identity}/{spaceId}/{objectId}", http.HandlerFunc(m.handleRemoveTakedown))
+}
+
+// handleListReports lists reports by the status query param, open reports by default
+func (m *moderation) handleListReports(w http.ResponseWriter, r *http.Request) {
+	var status, limit int
+	var err error
+	if s := r.URL.Query().Get("status"); s != "" {
+		if status, err = strconv.Atoi(s); err != nil {
+			admin.WriteErr(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil {
+			admin.WriteErr(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	reports, err := m.ListReports(r.Context(), domain.ReportStatus(status), limit)
+	if err != nil {
+		admin.WriteErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, reports)
+}
+
+func (m *moderation) handleDismissReport(w http.ResponseWriter, r *http.Request) {
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = m.DismissReport(r.Context(), id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (m *moderation) handleListTakedowns(w http.ResponseWriter, r *http.Request) {
+	takedowns, err := m.ListTakedowns(r.Context())
+	if err != nil {
+		admin.WriteErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, takedowns)
+}
+
+// handleTakedown takes down the object, the status is 451 by default
+func (m *moderation) handleTakedown(w http.ResponseWriter, r *http.Request) {
+	var takedown domain.Takedown
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&takedown); err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if takedown.Status == 0 {
+		takedown.Status = http.StatusUnavailableForLegalReasons
+	}
+	if err := m.Takedown(r.Context(), takedown); err != nil {
+		admin.WriteErr(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (m *moderation) handleRemoveTakedown(w http.ResponseWriter, r *http.Request) {
+	object := domain.Object{Identity: r.PathValue("identity"), SpaceId: r.PathValue("spaceId"), ObjectId: r.PathValue("objectId")}
+	if err := m.RemoveTakedown(r.Context(), object); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeErr(w http.ResponseWriter, err error) {
+	if errors.Is(err, publishapi.ErrNotFound) {
+		admin.WriteErr(w, http.StatusNotFound, err)
+	} else {
+		admin.WriteErr(w, http.StatusInternalServerError, err)
+	}
+}
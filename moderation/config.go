@@ -0,0 +1,12 @@
+package moderation
+
+type configGetter interface {
+	GetModeration() Config
+}
+
+type Config struct {
+	// Salt is mixed into the reporter ip hash, it's required
+	Salt string `yaml:"salt"`
+	// ReportsPerHour is the max number of reports from one ip, 10 by default
+	ReportsPerHour int `yaml:"reportsPerHour"`
+}
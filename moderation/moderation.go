@@ -0,0 +1,267 @@
+// Package moderation handles abuse reports of the published pages and takedowns of the reported objects.
+//
+// Takedowns are keyed by identity, space and object, so they survive republishing of the same object.
+package moderation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/admin"
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/redisprovider"
+)
+
+const CName = "publish.moderation"
+
+var log = logger.NewNamed(CName)
+
+const (
+	MaxCommentLength = 2000
+
+	defaultReportsPerHour = 10
+	maxListLimit          = 1000
+)
+
+var (
+	ErrRateLimited   = errors.New("too many reports")
+	ErrInvalidReport = errors.New("invalid report")
+)
+
+// Reasons are the accepted report reasons
+var Reasons = []string{"phishing", "malware", "illegal", "copyright", "spam", "other"}
+
+func New() Moderation {
+	return new(moderation)
+}
+
+type Moderation interface {
+	// Report records the abuse report, repeated reports of the object from the same ip are ignored
+	Report(ctx context.Context, report domain.Report, reporterIp string) (err error)
+	ListReports(ctx context.Context, status domain.ReportStatus, limit int) (reports []domain.Report, err error)
+	DismissReport(ctx context.Context, id primitive.ObjectID) (err error)
+	// Takedown blocks the object and marks its open reports as actioned
+	Takedown(ctx context.Context, takedown domain.Takedown) (err error)
+	RemoveTakedown(ctx context.Context, object domain.Object) (err error)
+	ListTakedowns(ctx context.Context) (takedowns []domain.Takedown, err error)
+	// GetTakedown returns the takedown of the object or publishapi.ErrNotFound
+	GetTakedown(ctx context.Context, object domain.Object) (takedown domain.Takedown, err error)
+	// SetInvalidateCacheCallback sets the cache invalidation, withVersions drops the cached version permalinks too
+	SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool))
+	app.ComponentRunnable
+}
+
+type moderation struct {
+	config         Config
+	reportsColl    *mongo.Collection
+	takedownsColl  *mongo.Collection
+	redisClient    redis.UniversalClient
+	repo           publishrepo.PublishRepo
+	invalidateFunc func(identity, uri string, withVersions bool)
+}
+
+func (m *moderation) Name() (name string) {
+	return CName
+}
+
+func (m *moderation) Init(a *app.App) (err error) {
+	m.config = a.MustComponent("config").(configGetter).GetModeration()
+	if m.config.Salt == "" {
+		// the unsalted hash of the ip can be reversed by brute force
+		return errors.New("moderation.salt is required")
+	}
+	if m.config.ReportsPerHour <= 0 {
+		m.config.ReportsPerHour = defaultReportsPerHour
+	}
+	database := a.MustComponent(db.CName).(db.Database).Db()
+	m.reportsColl = database.Collection("reports")
+	m.takedownsColl = database.Collection("takedowns")
+	m.redisClient = a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
+	m.repo = a.MustComponent(publishrepo.CName).(publishrepo.PublishRepo)
+	m.registerAdminHandlers(a.MustComponent(admin.CName).(admin.Admin))
+	return
+}
+
+func (m *moderation) Run(ctx context.Context) (err error) {
+	_, err = m.reportsColl.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"status", 1}}},
+		{Keys: bson.D{{"identity", 1}, {"spaceId", 1}, {"objectId", 1}, {"reporter", 1}}},
+	})
+	return
+}
+
+func (m *moderation) SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool)) {
+	m.invalidateFunc = f
+}
+
+func (m *moderation) Report(ctx context.Context, report domain.Report, reporterIp string) (err error) {
+	if !slices.Contains(Reasons, report.Reason) {
+		return fmt.Errorf("%w: unknown reason %q", ErrInvalidReport, report.Reason)
+	}
+	if utf8.RuneCountInString(report.Comment) > MaxCommentLength {
+		return fmt.Errorf("%w: comment is longer than %d characters", ErrInvalidReport, MaxCommentLength)
+	}
+	report.Reporter = hashReporter(m.config.Salt, reporterIp)
+	rateKey := "report-rate:" + report.Reporter
+	count, err := m.redisClient.Incr(ctx, rateKey).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		if err = m.redisClient.Expire(ctx, rateKey, time.Hour).Err(); err != nil {
+			return
+		}
+	}
+	if count > int64(m.config.ReportsPerHour) {
+		return ErrRateLimited
+	}
+	query := bson.D{
+		{"identity", report.Identity},
+		{"spaceId", report.SpaceId},
+		{"objectId", report.ObjectId},
+		{"reporter", report.Reporter},
+		{"status", domain.ReportStatusOpen},
+	}
+	report.Id = primitive.NewObjectID()
+	report.Status = domain.ReportStatusOpen
+	report.Timestamp = time.Now().Unix()
+	// the open report of the same reporter is kept as is
+	_, err = m.reportsColl.UpdateOne(ctx, query, bson.D{{"$setOnInsert", report}}, options.Update().SetUpsert(true))
+	return
+}
+
+func (m *moderation) ListReports(ctx context.Context, status domain.ReportStatus, limit int) (reports []domain.Report, err error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+	cur, err := m.reportsColl.Find(ctx, bson.D{{"status", status}}, options.Find().SetSort(bson.D{{"_id", -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	err = cur.All(ctx, &reports)
+	return
+}
+
+func (m *moderation) DismissReport(ctx context.Context, id primitive.ObjectID) (err error) {
+	res, err := m.reportsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"status", domain.ReportStatusDismissed}}}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	return
+}
+
+func (m *moderation) Takedown(ctx context.Context, takedown domain.Takedown) (err error) {
+	if takedown.Status != http.StatusUnavailableForLegalReasons && takedown.Status != http.StatusGone {
+		return fmt.Errorf("unsupported takedown status %d", takedown.Status)
+	}
+	if takedown.Identity == "" || takedown.SpaceId == "" || takedown.ObjectId == "" {
+		return errors.New("identity, spaceId and objectId are required")
+	}
+	object := domain.Object{Identity: takedown.Identity, SpaceId: takedown.SpaceId, ObjectId: takedown.ObjectId}
+	takedown.Id = takedownId(object)
+	takedown.Timestamp = time.Now().Unix()
+	if _, err = m.takedownsColl.ReplaceOne(ctx, bson.D{{"_id", takedown.Id}}, takedown, options.Replace().SetUpsert(true)); err != nil {
+		return
+	}
+	if _, err = m.reportsColl.UpdateMany(
+		ctx,
+		bson.D{
+			{"identity", object.Identity},
+			{"spaceId", object.SpaceId},
+			{"objectId", object.ObjectId},
+			{"status", domain.ReportStatusOpen},
+		},
+		bson.D{{"$set", bson.D{{"status", domain.ReportStatusActioned}}}},
+	); err != nil {
+		return
+	}
+	m.invalidateCache(ctx, object)
+	return
+}
+
+func (m *moderation) RemoveTakedown(ctx context.Context, object domain.Object) (err error) {
+	res, err := m.takedownsColl.DeleteOne(ctx, bson.D{{"_id", takedownId(object)}})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	m.invalidateCache(ctx, object)
+	return
+}
+
+func (m *moderation) ListTakedowns(ctx context.Context) (takedowns []domain.Takedown, err error) {
+	cur, err := m.takedownsColl.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"timestamp", -1}}))
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	err = cur.All(ctx, &takedowns)
+	return
+}
+
+func (m *moderation) GetTakedown(ctx context.Context, object domain.Object) (takedown domain.Takedown, err error) {
+	if err = m.takedownsColl.FindOne(ctx, bson.D{{"_id", takedownId(object)}}).Decode(&takedown); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.Takedown{}, err
+	}
+	return
+}
+
+// invalidateCache drops the cached pages of the current object uri with its sub paths and version permalinks
+func (m *moderation) invalidateCache(ctx context.Context, object domain.Object) {
+	if m.invalidateFunc == nil {
+		return
+	}
+	obj, err := m.repo.ObjectPublishStatus(ctx, object)
+	if err != nil {
+		if !errors.Is(err, publishapi.ErrNotFound) {
+			log.Warn("can't resolve object to invalidate the cache", zap.Error(err))
+		}
+		return
+	}
+	m.invalidateFunc(obj.Identity, obj.Uri, true)
+}
+
+func (m *moderation) Close(ctx context.Context) (err error) {
+	return
+}
+
+func takedownId(object domain.Object) string {
+	return object.Identity + "/" + object.SpaceId + "/" + object.ObjectId
+}
+
+func hashReporter(salt, ip string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + ip))
+	return hex.EncodeToString(sum[:16])
+}
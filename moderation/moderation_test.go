@@ -0,0 +1,33 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func TestHashReporter(t *testing.T) {
+	h := hashReporter("salt", "1.2.3.4")
+	assert.Len(t, h, 32)
+	assert.Equal(t, h, hashReporter("salt", "1.2.3.4"))
+	assert.NotEqual(t, h, hashReporter("other", "1.2.3.4"))
+	assert.NotEqual(t, h, hashReporter("salt", "1.2.3.5"))
+}
+
+func TestModeration_ReportInvalid(t *testing.T) {
+	m := &moderation{}
+	ctx := context.Background()
+	err := m.Report(ctx, domain.Report{Reason: "boring"}, "1.2.3.4")
+	require.ErrorIs(t, err, ErrInvalidReport)
+	err = m.Report(ctx, domain.Report{Reason: "spam", Comment: strings.Repeat("a", MaxCommentLength+1)}, "1.2.3.4")
+	require.ErrorIs(t, err, ErrInvalidReport)
+}
+
+func TestTakedownId(t *testing.T) {
+	assert.Equal(t, "a1/s1/o1", takedownId(domain.Object{Identity: "a1", SpaceId: "s1", ObjectId: "o1"}))
+}
@@ -0,0 +1,73 @@
+package publish
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+// checkAdmin returns the identity of the caller if it's in the admin allowlist
+func (p *publishService) checkAdmin(ctx context.Context) (identity string, err error) {
+	if identity, err = p.checkIdentity(ctx); err != nil {
+		return
+	}
+	if !slices.Contains(p.config.AdminIdentities, identity) {
+		return "", publishapi.ErrAccessDenied
+	}
+	return
+}
+
+func (p *publishService) AdminListPublishes(ctx context.Context, identity, spaceId string) (list []domain.ObjectWithPublish, err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.repo.ListPublishes(ctx, identity, spaceId)
+}
+
+func (p *publishService) AdminTakedown(ctx context.Context, takedown domain.Takedown) (err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	if takedown.Status == 0 {
+		takedown.Status = http.StatusUnavailableForLegalReasons
+	}
+	return p.moderation.Takedown(ctx, takedown)
+}
+
+func (p *publishService) AdminRestore(ctx context.Context, object domain.Object) (err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.moderation.RemoveTakedown(ctx, object)
+}
+
+func (p *publishService) AdminSetLimit(ctx context.Context, identity string, limit int64) (err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.repo.SetLimitOverride(ctx, identity, limit)
+}
+
+func (p *publishService) AdminGetLimit(ctx context.Context, identity string) (limit int, overridden bool, err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.GetLimit(ctx, identity)
+}
+
+func (p *publishService) AdminCleanup(ctx context.Context) (err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.Cleanup(ctx)
+}
+
+func (p *publishService) AdminGetStorageUsage(ctx context.Context, identity string) (usage domain.StorageUsage, err error) {
+	if _, err = p.checkAdmin(ctx); err != nil {
+		return
+	}
+	return p.repo.StorageUsage(ctx, identity)
+}
@@ -0,0 +1,36 @@
+package publish
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anyproto/any-sync/net/peer"
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func TestPublishService_checkAdmin(t *testing.T) {
+	ctxWithKey := func(t *testing.T) (context.Context, string) {
+		_, pubKey, err := crypto.GenerateRandomEd25519KeyPair()
+		require.NoError(t, err)
+		identity, err := pubKey.Marshall()
+		require.NoError(t, err)
+		return peer.CtxWithIdentity(context.Background(), identity), pubKey.Account()
+	}
+	adminCtx, adminIdentity := ctxWithKey(t)
+	userCtx, _ := ctxWithKey(t)
+	p := &publishService{config: Config{AdminIdentities: []string{adminIdentity}}}
+
+	identity, err := p.checkAdmin(adminCtx)
+	require.NoError(t, err)
+	assert.Equal(t, adminIdentity, identity)
+
+	_, err = p.checkAdmin(userCtx)
+	require.ErrorIs(t, err, publishapi.ErrAccessDenied)
+
+	_, err = p.checkAdmin(context.Background())
+	require.Error(t, err)
+}
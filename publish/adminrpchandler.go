@@ -0,0 +1,162 @@
+package publish
+
+import (
+	"context"
+	"time"
+
+	"github.com/anyproto/any-sync/metric"
+	"github.com/anyproto/any-sync/net/peer"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+var _ publishapi.DRPCPublishAdminServer = (*adminRpcHandler)(nil)
+
+type adminRpcHandler struct {
+	s *publishService
+}
+
+func (r adminRpcHandler) AdminListPublishes(ctx context.Context, req *publishapi.AdminListPublishesRequest) (resp *publishapi.ListPublishesResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminListPublishes",
+			metric.TotalDur(time.Since(st)),
+			metric.SpaceId(req.SpaceId),
+			zap.String("identity", req.Identity),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	list, err := r.s.AdminListPublishes(ctx, req.Identity, req.SpaceId)
+	if err != nil {
+		return nil, err
+	}
+	resp = &publishapi.ListPublishesResponse{
+		Publishes: make([]*publishapi.Publish, len(list)),
+	}
+	for i := range list {
+		resp.Publishes[i] = toPublish(list[i])
+	}
+	return resp, nil
+}
+
+func (r adminRpcHandler) AdminTakedown(ctx context.Context, req *publishapi.AdminTakedownRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminTakedown",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("identity", req.Identity),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.AdminTakedown(ctx, domain.Takedown{
+		Identity: req.Identity,
+		SpaceId:  req.SpaceId,
+		ObjectId: req.ObjectId,
+		Status:   int(req.Status),
+		Reason:   req.Reason,
+	}); err != nil {
+		return nil, err
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r adminRpcHandler) AdminRestore(ctx context.Context, req *publishapi.AdminRestoreRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminRestore",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("identity", req.Identity),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.AdminRestore(ctx, domain.Object{
+		Identity: req.Identity,
+		SpaceId:  req.SpaceId,
+		ObjectId: req.ObjectId,
+	}); err != nil {
+		return nil, err
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r adminRpcHandler) AdminSetLimit(ctx context.Context, req *publishapi.AdminSetLimitRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminSetLimit",
+			metric.TotalDur(time.Since(st)),
+			zap.String("identity", req.Identity),
+			zap.Int64("limit", req.Limit),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.AdminSetLimit(ctx, req.Identity, req.Limit); err != nil {
+		return nil, err
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r adminRpcHandler) AdminGetLimit(ctx context.Context, req *publishapi.AdminGetLimitRequest) (resp *publishapi.AdminGetLimitResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminGetLimit",
+			metric.TotalDur(time.Since(st)),
+			zap.String("identity", req.Identity),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	limit, overridden, err := r.s.AdminGetLimit(ctx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.AdminGetLimitResponse{
+		Limit:      int64(limit),
+		Overridden: overridden,
+	}, nil
+}
+
+func (r adminRpcHandler) AdminCleanup(ctx context.Context, req *publishapi.AdminCleanupRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminCleanup",
+			metric.TotalDur(time.Since(st)),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.AdminCleanup(ctx); err != nil {
+		return nil, err
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r adminRpcHandler) AdminGetStorageUsage(ctx context.Context, req *publishapi.AdminGetStorageUsageRequest) (resp *publishapi.AdminGetStorageUsageResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.adminGetStorageUsage",
+			metric.TotalDur(time.Since(st)),
+			zap.String("identity", req.Identity),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	usage, err := r.s.AdminGetStorageUsage(ctx, req.Identity)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.AdminGetStorageUsageResponse{
+		Size:      usage.Size,
+		Publishes: usage.Publishes,
+		Objects:   usage.Objects,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package publish
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type archiveFormat int
+
+const (
+	archiveFormatTar archiveFormat = iota
+	archiveFormatZip
+)
+
+var errInvalidArchivePath = errors.New("invalid archive path")
+
+// uploadFormat returns the archive format by the Content-Type, tar by default
+func uploadFormat(r *http.Request) archiveFormat {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/zip", "application/x-zip-compressed":
+		return archiveFormatZip
+	}
+	return archiveFormatTar
+}
+
+// archiveReader iterates the regular files of the uploaded archive
+type archiveReader interface {
+	// Next returns the next file with the validated name, io.EOF at the end
+	Next() (name string, size int64, reader io.Reader, err error)
+	Close() error
+}
+
+// newArchiveReader returns the reader of the archive, the reader is limited by limitArchive
+func newArchiveReader(format archiveFormat, reader io.Reader, limit int) (archiveReader, error) {
+	reader = limitArchive(reader, limit)
+	if format == archiveFormatZip {
+		return newZipArchive(reader)
+	}
+	return &tarArchive{tr: tar.NewReader(reader)}, nil
+}
+
+// archivePath validates the file name, the names are relative slash separated paths without dot elements
+func archivePath(name string) (string, error) {
+	name = strings.TrimPrefix(name, "/")
+	name = strings.TrimPrefix(name, "./")
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("%w: %q", errInvalidArchivePath, name)
+	}
+	return name, nil
+}
+
+type tarArchive struct {
+	tr *tar.Reader
+}
+
+func (t *tarArchive) Next() (name string, size int64, reader io.Reader, err error) {
+	for {
+		header, err := t.tr.Next()
+		if err != nil {
+			return "", 0, nil, err
+		}
+		if header.FileInfo().IsDir() {
+			continue
+		}
+		if name, err = archivePath(header.Name); err != nil {
+			return "", 0, nil, err
+		}
+		return name, header.Size, t.tr, nil
+	}
+}
+
+func (t *tarArchive) Close() error {
+	return nil
+}
+
+// zipArchive keeps the uploaded zip in the temporary file, the zip directory is at the end of the file
+type zipArchive struct {
+	file    *os.File
+	zr      *zip.Reader
+	idx     int
+	current io.ReadCloser
+}
+
+func newZipArchive(reader io.Reader) (archiveReader, error) {
+	file, err := os.CreateTemp("", "publish-upload-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	z := &zipArchive{file: file}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		_ = z.Close()
+		return nil, err
+	}
+	if z.zr, err = zip.NewReader(file, size); err != nil {
+		_ = z.Close()
+		return nil, fmt.Errorf("%w: %w", errInvalidCompression, err)
+	}
+	return z, nil
+}
+
+func (z *zipArchive) Next() (name string, size int64, reader io.Reader, err error) {
+	if z.current != nil {
+		_ = z.current.Close()
+		z.current = nil
+	}
+	for ; z.idx < len(z.zr.File); z.idx++ {
+		f := z.zr.File[z.idx]
+		// directories and symlinks are not published
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		z.idx++
+		if name, err = archivePath(f.Name); err != nil {
+			return
+		}
+		// the zip reader fails when the content is larger than the declared size
+		if z.current, err = f.Open(); err != nil {
+			return "", 0, nil, fmt.Errorf("%w: %w", errInvalidCompression, err)
+		}
+		return name, int64(f.UncompressedSize64), z.current, nil
+	}
+	return "", 0, nil, io.EOF
+}
+
+func (z *zipArchive) Close() error {
+	if z.current != nil {
+		_ = z.current.Close()
+	}
+	_ = z.file.Close()
+	return os.Remove(z.file.Name())
+}
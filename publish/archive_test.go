@@ -0,0 +1,120 @@
+package publish
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchivePath(t *testing.T) {
+	for name, expected := range map[string]string{
+		"index.html":      "index.html",
+		"/sub/page.html":  "sub/page.html",
+		"./sub/page.html": "sub/page.html",
+	} {
+		res, err := archivePath(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, res)
+	}
+	for _, name := range []string{"", ".", "../index.html", "sub/../../index.html", "sub//page.html"} {
+		_, err := archivePath(name)
+		assert.ErrorIs(t, err, errInvalidArchivePath, name)
+	}
+}
+
+func TestUploadFormat(t *testing.T) {
+	r := httptest.NewRequest("POST", "/api/upload/id/key", nil)
+	assert.Equal(t, archiveFormatTar, uploadFormat(r))
+	r.Header.Set("Content-Type", "application/zip")
+	assert.Equal(t, archiveFormatZip, uploadFormat(r))
+}
+
+var testArchiveFiles = map[string]string{
+	"index.html":    "index",
+	"sub/page.html": "page",
+}
+
+func TestArchiveReader(t *testing.T) {
+	t.Run("tar", func(t *testing.T) {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "sub/", Mode: 0755}))
+		for name, content := range testArchiveFiles {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(content)), Mode: 0644}))
+			_, _ = tw.Write([]byte(content))
+		}
+		require.NoError(t, tw.Close())
+		assert.Equal(t, testArchiveFiles, readArchive(t, archiveFormatTar, &buf))
+	})
+	t.Run("zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		_, err := zw.Create("sub/")
+		require.NoError(t, err)
+		for name, content := range testArchiveFiles {
+			w, err := zw.Create(name)
+			require.NoError(t, err)
+			_, _ = w.Write([]byte(content))
+		}
+		require.NoError(t, zw.Close())
+
+		tmpBefore, _ := filepath.Glob(filepath.Join(os.TempDir(), "publish-upload-*.zip"))
+		assert.Equal(t, testArchiveFiles, readArchive(t, archiveFormatZip, &buf))
+		tmpAfter, _ := filepath.Glob(filepath.Join(os.TempDir(), "publish-upload-*.zip"))
+		assert.Len(t, tmpAfter, len(tmpBefore))
+	})
+	t.Run("zip invalid path", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		_, err := zw.Create("../index.html")
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		archive, err := newArchiveReader(archiveFormatZip, &buf, 1<<20)
+		require.NoError(t, err)
+		defer archive.Close()
+		_, _, _, err = archive.Next()
+		assert.ErrorIs(t, err, errInvalidArchivePath)
+	})
+	t.Run("zip over limit", func(t *testing.T) {
+		_, err := newArchiveReader(archiveFormatZip, bytes.NewReader(make([]byte, 3<<20)), 1<<10)
+		assert.ErrorIs(t, err, ErrUploadLimitExceeded)
+	})
+	t.Run("invalid zip", func(t *testing.T) {
+		_, err := newArchiveReader(archiveFormatZip, bytes.NewReader([]byte("not a zip")), 1<<10)
+		assert.ErrorIs(t, err, errInvalidCompression)
+	})
+}
+
+func readArchive(t *testing.T, format archiveFormat, r io.Reader) map[string]string {
+	archive, err := newArchiveReader(format, r, 1<<20)
+	require.NoError(t, err)
+	return readFiles(t, archive)
+}
+
+func readFiles(t *testing.T, archive archiveReader) map[string]string {
+	defer func() {
+		require.NoError(t, archive.Close())
+	}()
+	files := map[string]string{}
+	for {
+		name, size, reader, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Len(t, data, int(size))
+		files[name] = string(data)
+	}
+}
@@ -0,0 +1,104 @@
+package publish
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+
+	// maxZstdWindow bounds the decoder memory, encoders use 8Mb windows at the default levels
+	maxZstdWindow = 32 << 20
+	// archiveOverhead is the allowed size of tar headers and paddings above the upload limit
+	archiveOverhead = 1 << 20
+)
+
+var (
+	errUnsupportedEncoding = errors.New("unsupported upload encoding")
+	errInvalidCompression  = errors.New("invalid compressed upload")
+)
+
+// uploadEncoding returns the compression of the upload by the Content-Encoding or the Content-Type, empty for the raw tar
+func uploadEncoding(r *http.Request) (string, error) {
+	if encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding != "" && encoding != "identity" {
+		switch encoding {
+		case encodingGzip, "x-gzip":
+			return encodingGzip, nil
+		case encodingZstd:
+			return encodingZstd, nil
+		}
+		return "", fmt.Errorf("%w: %s", errUnsupportedEncoding, encoding)
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/gzip", "application/x-gzip", "application/x-tar+gzip":
+		return encodingGzip, nil
+	case "application/zstd", "application/x-tar+zstd":
+		return encodingZstd, nil
+	}
+	return "", nil
+}
+
+// decompress wraps the body with the decoder of the encoding
+func decompress(body io.Reader, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case encodingGzip:
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidCompression, err)
+		}
+		return decodeReader{zr}, nil
+	case encodingZstd:
+		zr, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxZstdWindow))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidCompression, err)
+		}
+		return decodeReader{zr.IOReadCloser()}, nil
+	}
+	return io.NopCloser(body), nil
+}
+
+// decodeReader marks the decoding errors with errInvalidCompression
+type decodeReader struct {
+	io.ReadCloser
+}
+
+func (d decodeReader) Read(p []byte) (n int, err error) {
+	n, err = d.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %w", errInvalidCompression, err)
+	}
+	return
+}
+
+// limitArchive fails the read with ErrUploadLimitExceeded when the uncompressed archive is larger than the limit
+// with the headers overhead, so compressed archives of empty entries can't be inflated without the bound
+func limitArchive(r io.Reader, limit int) io.Reader {
+	return &archiveLimitReader{r: r, n: int64(limit)*2 + archiveOverhead}
+}
+
+type archiveLimitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *archiveLimitReader) Read(p []byte) (n int, err error) {
+	if l.n <= 0 {
+		return 0, ErrUploadLimitExceeded
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err = l.r.Read(p)
+	l.n -= int64(n)
+	return
+}
@@ -0,0 +1,73 @@
+package publish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		contentEncoding, contentType, encoding string
+		err                                    error
+	}{
+		{"", "application/x-tar", "", nil},
+		{"gzip", "application/x-tar", encodingGzip, nil},
+		{"zstd", "", encodingZstd, nil},
+		{"identity", "application/gzip", encodingGzip, nil},
+		{"", "application/zstd", encodingZstd, nil},
+		{"br", "application/x-tar", "", errUnsupportedEncoding},
+	} {
+		r := httptest.NewRequest("POST", "/api/upload/id/key", nil)
+		r.Header.Set("Content-Encoding", tc.contentEncoding)
+		r.Header.Set("Content-Type", tc.contentType)
+		encoding, err := uploadEncoding(r)
+		assert.ErrorIs(t, err, tc.err, tc.contentEncoding)
+		assert.Equal(t, tc.encoding, encoding, tc.contentEncoding)
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	data := bytes.Repeat([]byte("publish"), 1000)
+	t.Run("gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		require.NoError(t, zw.Close())
+		assertDecompressed(t, &buf, encodingGzip, data)
+	})
+	t.Run("zstd", func(t *testing.T) {
+		zw, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		assertDecompressed(t, bytes.NewReader(zw.EncodeAll(data, nil)), encodingZstd, data)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := decompress(bytes.NewReader([]byte("not gzip")), encodingGzip)
+		assert.ErrorIs(t, err, errInvalidCompression)
+	})
+}
+
+func assertDecompressed(t *testing.T, r io.Reader, encoding string, expected []byte) {
+	rc, err := decompress(r, encoding)
+	require.NoError(t, err)
+	defer rc.Close()
+	res, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, expected, res)
+}
+
+func TestLimitArchive(t *testing.T) {
+	const limit = 1 << 10
+	res, err := io.ReadAll(limitArchive(bytes.NewReader(make([]byte, 2*limit)), limit))
+	require.NoError(t, err)
+	assert.Len(t, res, 2*limit)
+
+	_, err = io.ReadAll(limitArchive(bytes.NewReader(make([]byte, 2*limit+archiveOverhead+1)), limit))
+	assert.ErrorIs(t, err, ErrUploadLimitExceeded)
+}
@@ -6,6 +6,12 @@ type configGetter interface {
 
 type Config struct {
 	UploadUrlPrefix string `yaml:"uploadUrlPrefix"`
-	HttpApiAddr     string `yaml:"httpApiAddr"`
-	CleanupOn       bool   `yaml:"cleanupOn"`
+	// UploadTokenKey signs the upload urls, it's derived from the account signing key when empty
+	UploadTokenKey string `yaml:"uploadTokenKey"`
+	HttpApiAddr    string `yaml:"httpApiAddr"`
+	CleanupOn      bool   `yaml:"cleanupOn"`
+	// VersionRetentionDays is how long archived versions are kept for permalinks, 30 by default
+	VersionRetentionDays int `yaml:"versionRetentionDays"`
+	// AdminIdentities are account addresses allowed to call the PublishAdmin api
+	AdminIdentities []string `yaml:"adminIdentities"`
 }
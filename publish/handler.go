@@ -4,19 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/anyproto/any-sync/metric"
 	"github.com/anyproto/any-sync/net/peer"
+	"github.com/anyproto/any-sync/net/rpc/rpcerr"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/anytype-publish-server/customdomain"
 	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publish/publishrules"
+	"github.com/anyproto/anytype-publish-server/publish/uploadtoken"
 	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/stats"
 )
 
 var _ publishapi.DRPCWebPublisherServer = (*rpcHandler)(nil)
 
+const defaultStatsDays = 30
+
 type rpcHandler struct {
 	s *publishService
 }
@@ -72,15 +81,122 @@ func (r rpcHandler) Publish(ctx context.Context, req *publishapi.PublishRequest)
 		)
 	}()
 
-	uploadUrl, err := r.s.Publish(ctx, domain.Object{SpaceId: req.SpaceId, ObjectId: req.ObjectId, Uri: req.Uri}, req.Version)
+	publish, uploadUrl, err := r.s.Publish(ctx, domain.Object{
+		SpaceId:    req.SpaceId,
+		ObjectId:   req.ObjectId,
+		Uri:        req.Uri,
+		Visibility: domain.Visibility(req.Visibility),
+	}, domain.PublishParams{
+		Version:     req.Version,
+		Type:        domain.PublishType(req.Type),
+		SpaFallback: req.SpaFallback,
+		Members:     req.Members,
+		Preview:     req.Preview,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &publishapi.PublishResponse{
+	resp = &publishapi.PublishResponse{
 		UploadUrl: uploadUrl,
+		Uri:       publish.Uri,
+		PublishId: publish.Publish.Id.Hex(),
+	}
+	if publish.Publish.PreviewKey != "" {
+		resp.PreviewUrl = r.s.previewUrl(publish.Publish)
+	}
+	return resp, nil
+}
+
+func (r rpcHandler) GetPublishStats(ctx context.Context, req *publishapi.GetPublishStatsRequest) (resp *publishapi.GetPublishStatsResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.getPublishStats",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	to := time.Now().UTC()
+	if req.ToDate != "" {
+		if to, err = time.Parse(stats.DateLayout, req.ToDate); err != nil {
+			return nil, stats.ErrInvalidRange
+		}
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays+1)
+	if req.FromDate != "" {
+		if from, err = time.Parse(stats.DateLayout, req.FromDate); err != nil {
+			return nil, stats.ErrInvalidRange
+		}
+	}
+	days, err := r.s.GetPublishStats(ctx, req.SpaceId, req.ObjectId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	resp = &publishapi.GetPublishStatsResponse{Days: make([]*publishapi.PublishStatsDay, 0, len(days))}
+	for _, day := range days {
+		resp.Days = append(resp.Days, &publishapi.PublishStatsDay{Date: day.Date, Views: day.Views, Uniques: day.Uniques})
+		resp.Views += day.Views
+		resp.Uniques += day.Uniques
+	}
+	return resp, nil
+}
+
+func (r rpcHandler) SetAnalytics(ctx context.Context, req *publishapi.SetAnalyticsRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.setAnalytics",
+			metric.TotalDur(time.Since(st)),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	var config domain.AnalyticsConfig
+	if req.Analytics != nil {
+		config = domain.AnalyticsConfig{Provider: req.Analytics.Provider, SiteId: req.Analytics.SiteId, Host: req.Analytics.Host}
+	}
+	if err = r.s.SetAnalytics(ctx, config); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r rpcHandler) GetAnalytics(ctx context.Context, req *publishapi.GetAnalyticsRequest) (resp *publishapi.GetAnalyticsResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.getAnalytics",
+			metric.TotalDur(time.Since(st)),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	config, err := r.s.GetAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.GetAnalyticsResponse{
+		Analytics: &publishapi.Analytics{Provider: config.Provider, SiteId: config.SiteId, Host: config.Host},
 	}, nil
 }
 
+func (r rpcHandler) PromotePreview(ctx context.Context, req *publishapi.PromotePreviewRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.promotePreview",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.PromotePreview(ctx, domain.Object{SpaceId: req.SpaceId, ObjectId: req.ObjectId}, req.PublishId); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
 func (r rpcHandler) UnPublish(ctx context.Context, req *publishapi.UnPublishRequest) (resp *publishapi.Ok, err error) {
 	st := time.Now()
 	defer func() {
@@ -121,18 +237,251 @@ func (r rpcHandler) ListPublishes(ctx context.Context, req *publishapi.ListPubli
 	return resp, nil
 }
 
+func (r rpcHandler) SetNotFoundPage(ctx context.Context, req *publishapi.SetNotFoundPageRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.setNotFoundPage",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.SetNotFoundPage(ctx, req.SpaceId, req.ObjectId); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r rpcHandler) CreateShareLink(ctx context.Context, req *publishapi.CreateShareLinkRequest) (resp *publishapi.CreateShareLinkResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.createShareLink",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	link, expire, err := r.s.CreateShareLink(ctx, req.SpaceId, req.ObjectId, time.Duration(req.Ttl)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.CreateShareLinkResponse{
+		Url:             link,
+		ExpireTimestamp: expire.Unix(),
+	}, nil
+}
+
+func (r rpcHandler) RevokeShareLinks(ctx context.Context, req *publishapi.RevokeShareLinksRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.revokeShareLinks",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.RevokeShareLinks(ctx, req.SpaceId, req.ObjectId); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r rpcHandler) AddDomain(ctx context.Context, req *publishapi.AddDomainRequest) (resp *publishapi.AddDomainResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.addDomain",
+			metric.TotalDur(time.Since(st)),
+			zap.String("host", req.Host),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	customDomain, err := r.s.AddDomain(ctx, req.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.AddDomainResponse{
+		Domain: toDomain(customDomain),
+	}, nil
+}
+
+func (r rpcHandler) VerifyDomain(ctx context.Context, req *publishapi.VerifyDomainRequest) (resp *publishapi.VerifyDomainResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.verifyDomain",
+			metric.TotalDur(time.Since(st)),
+			zap.String("host", req.Host),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	customDomain, err := r.s.VerifyDomain(ctx, req.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &publishapi.VerifyDomainResponse{
+		Domain: toDomain(customDomain),
+	}, nil
+}
+
+func (r rpcHandler) RemoveDomain(ctx context.Context, req *publishapi.RemoveDomainRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.removeDomain",
+			metric.TotalDur(time.Since(st)),
+			zap.String("host", req.Host),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.RemoveDomain(ctx, req.Host); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r rpcHandler) ListDomains(ctx context.Context, req *publishapi.ListDomainsRequest) (resp *publishapi.ListDomainsResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.listDomains",
+			metric.TotalDur(time.Since(st)),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	list, err := r.s.ListDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp = &publishapi.ListDomainsResponse{
+		Domains: make([]*publishapi.Domain, len(list)),
+	}
+	for i := range list {
+		resp.Domains[i] = toDomain(list[i])
+	}
+	return resp, nil
+}
+
+func (r rpcHandler) ListRedirects(ctx context.Context, req *publishapi.ListRedirectsRequest) (resp *publishapi.ListRedirectsResponse, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.listRedirects",
+			metric.TotalDur(time.Since(st)),
+			metric.ObjectId(req.ObjectId),
+			metric.SpaceId(req.SpaceId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	list, err := r.s.ListRedirects(ctx, req.SpaceId, req.ObjectId)
+	if err != nil {
+		return nil, err
+	}
+	resp = &publishapi.ListRedirectsResponse{
+		Redirects: make([]*publishapi.Redirect, len(list)),
+	}
+	for i, redirect := range list {
+		resp.Redirects[i] = &publishapi.Redirect{
+			Uri:       redirect.Uri,
+			SpaceId:   redirect.SpaceId,
+			ObjectId:  redirect.ObjectId,
+			TargetUri: redirect.TargetUri,
+			Timestamp: redirect.Timestamp,
+		}
+	}
+	return resp, nil
+}
+
+func (r rpcHandler) DeleteRedirect(ctx context.Context, req *publishapi.DeleteRedirectRequest) (resp *publishapi.Ok, err error) {
+	st := time.Now()
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.deleteRedirect",
+			metric.TotalDur(time.Since(st)),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	if err = r.s.DeleteRedirect(ctx, req.Uri); err != nil {
+		return
+	}
+	return &publishapi.Ok{}, nil
+}
+
+func (r rpcHandler) UploadFiles(stream publishapi.DRPCWebPublisher_UploadFilesStream) (err error) {
+	ctx := stream.Context()
+	st := time.Now()
+	var publishId string
+	defer func() {
+		r.s.metric.RequestLog(ctx, "publish.uploadFiles",
+			metric.TotalDur(time.Since(st)),
+			zap.String("publishId", publishId),
+			zap.String("addr", peer.CtxPeerAddr(ctx)),
+			zap.Error(err),
+		)
+	}()
+	first, err := stream.Recv()
+	if err != nil {
+		return
+	}
+	publishId = first.PublishId
+	url, err := r.s.UploadFiles(ctx, publishId, newStreamArchive(stream, first))
+	if err != nil {
+		return uploadFilesError(err)
+	}
+	return stream.SendAndClose(&publishapi.UploadFilesResponse{Url: url})
+}
+
+// uploadFilesError converts the upload error to the registered api error, so the client doesn't take it for the connection failure.
+// The details are kept in the message, drpcerr.Code follows only the single wrapped error
+func uploadFilesError(err error) error {
+	if rpcerr.Code(err) != 0 {
+		return err
+	}
+	switch {
+	case errors.Is(err, publishrules.ErrInvalidSyntax), errors.Is(err, errInvalidStream), errors.Is(err, errInvalidArchivePath):
+		return fmt.Errorf("%w: %v", publishapi.ErrInvalidUpload, err)
+	case errors.Is(err, ErrUploadLimitExceeded):
+		return publishapi.ErrUploadLimitExceeded
+	case errors.Is(err, publishrepo.ErrUploadUsed):
+		return publishapi.ErrUploadUsed
+	case errors.Is(err, uploadtoken.ErrExpired):
+		return publishapi.ErrUploadExpired
+	}
+	return fmt.Errorf("%w: %v", publishapi.ErrUnexpected, err)
+}
+
+func toDomain(customDomain domain.CustomDomain) *publishapi.Domain {
+	return &publishapi.Domain{
+		Host:           customDomain.Host,
+		Verified:       customDomain.Verified,
+		TxtRecordName:  customdomain.TxtRecordPrefix + customDomain.Host,
+		TxtRecordValue: customdomain.TxtValuePrefix + customDomain.Token,
+	}
+}
+
 func toPublish(obj domain.ObjectWithPublish) *publishapi.Publish {
 	publish := &publishapi.Publish{
-		SpaceId:   obj.SpaceId,
-		ObjectId:  obj.ObjectId,
-		Uri:       obj.Uri,
-		Timestamp: obj.Timestamp,
+		SpaceId:      obj.SpaceId,
+		ObjectId:     obj.ObjectId,
+		Uri:          obj.Uri,
+		Timestamp:    obj.Timestamp,
+		NotFoundPage: obj.NotFoundPage,
+		Visibility:   publishapi.Visibility(obj.Visibility),
 	}
 	if obj.Publish != nil {
 		if obj.Publish.Status == domain.PublishStatusPublished {
 			publish.Status = publishapi.PublishStatus_PublishStatusPublished
 			publish.Version = obj.Publish.Version
 			publish.Size = obj.Publish.Size
+			publish.Type = publishapi.PublishType(obj.Publish.Type)
+			publish.PublishId = obj.Publish.Id.Hex()
+			publish.Members = obj.Publish.Members
 		}
 	}
 	return publish
@@ -143,7 +492,7 @@ type httpHandler struct {
 }
 
 func (h httpHandler) init(m *http.ServeMux) {
-	m.HandleFunc("/api/upload/{publishId}/{uploadKey}", h.Upload)
+	m.HandleFunc("/api/upload/{publishId}/{token}", h.Upload)
 	m.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusNotFound, errors.New("not found"))
 	})
@@ -156,7 +505,8 @@ func (h httpHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		h.s.metric.RequestLog(r.Context(), "publish.upload",
 			metric.TotalDur(time.Since(st)),
 			zap.Error(err),
-			zap.String("uploadKey", r.PathValue("uploadKey")),
+			zap.String("publishId", r.PathValue("publishId")),
+			zap.String("encoding", r.Header.Get("Content-Encoding")),
 		)
 	}()
 	if r.Method != http.MethodPost {
@@ -168,9 +518,35 @@ func (h httpHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		_ = r.Body.Close()
 	}()
+	encoding, err := uploadEncoding(r)
+	if err != nil {
+		writeErr(w, http.StatusUnsupportedMediaType, err)
+		return
+	}
+	body, err := decompress(r.Body, encoding)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err)
+		return
+	}
+	defer func() {
+		_ = body.Close()
+	}()
 	var url string
-	if url, err = h.s.UploadTar(r.Context(), r.PathValue("publishId"), r.PathValue("uploadKey"), r.Body); err != nil {
-		writeErr(w, http.StatusInternalServerError, err)
+	if url, err = h.s.UploadArchive(r.Context(), r.PathValue("publishId"), r.PathValue("token"), uploadFormat(r), body); err != nil {
+		switch {
+		case errors.Is(err, publishrules.ErrInvalidSyntax), errors.Is(err, errInvalidCompression), errors.Is(err, errInvalidArchivePath):
+			writeErr(w, http.StatusBadRequest, err)
+		case errors.Is(err, ErrUploadLimitExceeded):
+			writeErr(w, http.StatusRequestEntityTooLarge, err)
+		case errors.Is(err, publishapi.ErrAccessDenied), errors.Is(err, uploadtoken.ErrInvalidToken):
+			writeErr(w, http.StatusForbidden, err)
+		case errors.Is(err, uploadtoken.ErrExpired):
+			writeErr(w, http.StatusGone, err)
+		case errors.Is(err, publishrepo.ErrUploadUsed):
+			writeErr(w, http.StatusConflict, err)
+		default:
+			writeErr(w, http.StatusInternalServerError, err)
+		}
 	} else {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -2,15 +2,21 @@ package publishrepo
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
 
 	"github.com/anyproto/anytype-publish-server/db"
 	"github.com/anyproto/anytype-publish-server/domain"
@@ -19,23 +25,69 @@ import (
 
 const CName = "publish.repo"
 
+var log = logger.NewNamed(CName)
+
+// mongo error codes of creating the index that differs from the existing one
+const (
+	indexOptionsConflict  = 85
+	indexKeySpecsConflict = 86
+)
+
+// ErrUploadUsed is returned by ClaimUpload when the publish is already uploading or uploaded
+var ErrUploadUsed = errors.New("upload url already used")
+
 func New() PublishRepo {
 	return new(publishRepo)
 }
 
 type PublishRepo interface {
-	ObjectCreate(ctx context.Context, object domain.Object, version string) (publish domain.ObjectWithPublish, prevUri string, err error)
+	ObjectCreate(ctx context.Context, object domain.Object, params domain.PublishParams) (publish domain.ObjectWithPublish, prevUri string, err error)
 	ObjectDelete(ctx context.Context, object domain.Object) (uri string, err error)
 	ObjectPublishStatus(ctx context.Context, object domain.Object) (publish domain.ObjectWithPublish, err error)
 	ResolveUri(ctx context.Context, identity, uri string) (publish domain.ObjectWithPublish, err error)
 	ResolvePublishUri(ctx context.Context, identity, uri string) (publish domain.Object, err error)
+	// ResolveVersion returns the published or archived publish of the uri by the version or the publish id
+	ResolveVersion(ctx context.Context, identity, uri, version string) (publish domain.ObjectWithPublish, err error)
 	ListPublishes(ctx context.Context, identity string, spaceId string) ([]domain.ObjectWithPublish, error)
+	SetNotFoundPage(ctx context.Context, object domain.Object) (err error)
+	ResolveNotFoundPage(ctx context.Context, identity string) (publish domain.ObjectWithPublish, err error)
+	// EnsureShareNonce returns the share nonce of the object, creates it if not exists
+	EnsureShareNonce(ctx context.Context, object domain.Object) (result domain.Object, err error)
+	// RotateShareNonce replaces the share nonce of the object, all previous share links become invalid
+	RotateShareNonce(ctx context.Context, object domain.Object) (err error)
+	ResolveRedirect(ctx context.Context, identity, uri string) (redirect domain.Redirect, err error)
+	ListRedirects(ctx context.Context, object domain.Object) (redirects []domain.Redirect, err error)
+	DeleteRedirect(ctx context.Context, identity, uri string) (err error)
 	GetPublish(ctx context.Context, id primitive.ObjectID) (publish domain.ObjectWithPublish, err error)
+	// ClaimUpload moves the created publish to the uploading status, so only one upload takes it.
+	// The non-empty uploadKey must match the publish, ErrUploadUsed is returned for the taken publish
+	ClaimUpload(ctx context.Context, id primitive.ObjectID, uploadKey string) (publish domain.ObjectWithPublish, err error)
+	// FinalizePublish saves the uploading publish or promotes the preview, publishapi.ErrNotFound is returned for others.
+	// The published one becomes the live version of the object and its 404.html makes the object the 404 page when the identity has none
 	FinalizePublish(ctx context.Context, publish domain.ObjectWithPublish) (err error)
 	IterateReadyToDeleteIds(ctx context.Context, do func(id primitive.ObjectID) error) error
 	DeletePublish(ctx context.Context, id primitive.ObjectID) (err error)
 	DeleteOutdatedPublishes(ctx context.Context, before time.Time) (deletedCount int, err error)
+	// DeleteStaleUploads marks uploads started by publishes created before the given time to delete with their files
+	DeleteStaleUploads(ctx context.Context, before time.Time) (deletedCount int, err error)
 	DeleteOutdatedObjects(ctx context.Context, before time.Time) (deletedCount int, err error)
+	// DeleteOutdatedVersions marks publishes archived before the given time to delete
+	DeleteOutdatedVersions(ctx context.Context, before time.Time) (deletedCount int, err error)
+	// DeleteExpiredPreviews marks preview publishes expired before the given time to delete,
+	// objects created by the previews and never published are deleted with them
+	DeleteExpiredPreviews(ctx context.Context, before time.Time) (deletedCount int, err error)
+	// SetLimitOverride sets the upload limit of the identity, zero limit removes the override
+	SetLimitOverride(ctx context.Context, identity string, limit int64) (err error)
+	// GetLimitOverride returns the upload limit of the identity or publishapi.ErrNotFound
+	GetLimitOverride(ctx context.Context, identity string) (limit int64, err error)
+	// StorageUsage sums up sizes of all publishes of the identity objects including archived versions
+	// empty identity sums up all publishes
+	StorageUsage(ctx context.Context, identity string) (usage domain.StorageUsage, err error)
+	// CountCleanup counts what the cleanup would delete with the same cutoffs, it changes nothing
+	CountCleanup(ctx context.Context, before, uploadsBefore, versionsBefore time.Time) (report domain.CleanupReport, err error)
+	HasPublish(ctx context.Context, id primitive.ObjectID) (ok bool, err error)
+	// IterateObjects calls do for every object of the identity with its active publish, all objects for the empty identity
+	IterateObjects(ctx context.Context, identity string, do func(publish domain.ObjectWithPublish) error) error
 	app.ComponentRunnable
 }
 
@@ -46,6 +98,12 @@ var (
 				{"status", 1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{"objectId", 1},
+				{"version", 1},
+			},
+		},
 	}
 	objectIndexes = []mongo.IndexModel{
 		{
@@ -56,12 +114,23 @@ var (
 			},
 		},
 	}
+	redirectIndexes = []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{"identity", 1},
+				{"spaceId", 1},
+				{"objectId", 1},
+			},
+		},
+	}
 )
 
 type publishRepo struct {
-	db          db.Database
-	publishColl *mongo.Collection
-	objectsColl *mongo.Collection
+	db           db.Database
+	publishColl  *mongo.Collection
+	objectsColl  *mongo.Collection
+	redirectColl *mongo.Collection
+	limitsColl   *mongo.Collection
 }
 
 func (p *publishRepo) Name() (name string) {
@@ -72,6 +141,8 @@ func (p *publishRepo) Init(a *app.App) (err error) {
 	p.db = a.MustComponent(db.CName).(db.Database)
 	p.publishColl = p.db.Db().Collection("publish")
 	p.objectsColl = p.db.Db().Collection("object")
+	p.redirectColl = p.db.Db().Collection("redirect")
+	p.limitsColl = p.db.Db().Collection("limits")
 	return
 }
 
@@ -82,22 +153,29 @@ func (p *publishRepo) Run(ctx context.Context) (err error) {
 	if err = ensureIndexes(ctx, p.publishColl, publishIndexes...); err != nil {
 		return
 	}
+	if err = ensureIndexes(ctx, p.redirectColl, redirectIndexes...); err != nil {
+		return
+	}
 	return
 }
 
+// ensureIndexes creates the missing indexes one by one, so the indexes added later get to the existing collections.
+// Creating the existing index is a no-op, the index conflicting with the existing one is skipped with the warning
 func ensureIndexes(ctx context.Context, coll *mongo.Collection, indexes ...mongo.IndexModel) (err error) {
-	existingIndexes, err := coll.Indexes().ListSpecifications(ctx)
-	if err != nil {
-		return
-	}
-	if len(existingIndexes) <= 1 {
-		_, err = coll.Indexes().CreateMany(ctx, indexes)
+	for _, index := range indexes {
+		if _, err = coll.Indexes().CreateOne(ctx, index); err != nil {
+			var cmdErr mongo.CommandError
+			if errors.As(err, &cmdErr) && (cmdErr.Code == indexOptionsConflict || cmdErr.Code == indexKeySpecsConflict) {
+				log.Warn("index conflicts with the existing one", zap.String("collection", coll.Name()), zap.Error(err))
+				continue
+			}
+			return
+		}
 	}
-	return
+	return nil
 }
 
-func (p *publishRepo) ObjectCreate(ctx context.Context, object domain.Object, version string) (publish domain.ObjectWithPublish, prevUri string, err error) {
-	objectId := object.Identity + "/" + object.Uri
+func (p *publishRepo) ObjectCreate(ctx context.Context, object domain.Object, params domain.PublishParams) (publish domain.ObjectWithPublish, prevUri string, err error) {
 	err = p.db.Tx(ctx, func(ctx mongo.SessionContext) (err error) {
 		// check if we have the sharing for the space+object pair
 		var existingObject *domain.Object
@@ -107,22 +185,61 @@ func (p *publishRepo) ObjectCreate(ctx context.Context, object domain.Object, ve
 				return
 			}
 		}
+		if params.Preview && existingObject != nil {
+			// the preview doesn't change the live object
+			publish.Object = *existingObject
+			publish.Publish, err = p.createPublish(ctx, existingObject, params)
+			return
+		}
+		if object.Visibility == domain.VisibilityUnlisted {
+			// keep the suffix while the object stays unlisted under the same uri
+			if existingObject != nil && existingObject.UriSuffix != "" && existingObject.Uri == object.Uri+"-"+existingObject.UriSuffix {
+				object.UriSuffix = existingObject.UriSuffix
+			} else {
+				object.UriSuffix = newUriSuffix()
+			}
+			object.Uri += "-" + object.UriSuffix
+		}
+		if object.Visibility != domain.VisibilityPublic {
+			// never redirect to the non-public uri
+			if _, err = p.redirectColl.DeleteMany(ctx, query); err != nil {
+				return
+			}
+		}
 		if existingObject != nil {
+			visibilityChanged := existingObject.Visibility != object.Visibility || existingObject.UriSuffix != object.UriSuffix
+			existingObject.Visibility = object.Visibility
+			existingObject.UriSuffix = object.UriSuffix
 			// change the uri
 			if existingObject.Uri != object.Uri {
 				prevUri = existingObject.Uri
 				if err = p.changeObjectUri(ctx, existingObject, object.Uri); err != nil {
 					return
 				}
+			} else if visibilityChanged {
+				if _, err = p.objectsColl.UpdateOne(
+					ctx,
+					bson.D{{"_id", existingObject.Id}},
+					bson.D{{"$set", bson.D{
+						{"visibility", existingObject.Visibility},
+						{"uriSuffix", existingObject.UriSuffix},
+					}}},
+				); err != nil {
+					return
+				}
 			}
 		} else {
+			// the object of the preview stays without the active publish until the promotion
+			// and is deleted with the expired preview
 			existingObject = &domain.Object{
-				Id:        objectId,
-				Identity:  object.Identity,
-				SpaceId:   object.SpaceId,
-				ObjectId:  object.ObjectId,
-				Uri:       object.Uri,
-				Timestamp: time.Now().Unix(),
+				Id:         object.Identity + "/" + object.Uri,
+				Identity:   object.Identity,
+				SpaceId:    object.SpaceId,
+				ObjectId:   object.ObjectId,
+				Uri:        object.Uri,
+				Timestamp:  time.Now().Unix(),
+				Visibility: object.Visibility,
+				UriSuffix:  object.UriSuffix,
 			}
 			if _, err = p.objectsColl.InsertOne(ctx, existingObject); err != nil {
 				if mongo.IsDuplicateKeyError(err) {
@@ -131,9 +248,12 @@ func (p *publishRepo) ObjectCreate(ctx context.Context, object domain.Object, ve
 					return err
 				}
 			}
+			if err = p.releaseRedirect(ctx, existingObject); err != nil {
+				return
+			}
 		}
 		publish.Object = *existingObject
-		if publish.Publish, err = p.createPublish(ctx, existingObject, version); err != nil {
+		if publish.Publish, err = p.createPublish(ctx, existingObject, params); err != nil {
 			return
 		}
 		return
@@ -148,6 +268,8 @@ func (p *publishRepo) changeObjectUri(ctx context.Context, object *domain.Object
 	if _, err = p.objectsColl.DeleteOne(ctx, bson.D{{"_id", object.Id}}); err != nil {
 		return
 	}
+	prevId := object.Id
+	prevUri := object.Uri
 	object.Id = object.Identity + "/" + uri
 	object.Uri = uri
 	if _, err = p.objectsColl.InsertOne(ctx, object); err != nil {
@@ -156,16 +278,103 @@ func (p *publishRepo) changeObjectUri(ctx context.Context, object *domain.Object
 		}
 		return
 	}
+	// move active and archived publishes to the new uri
+	if _, err = p.publishColl.UpdateMany(
+		ctx,
+		bson.D{{"objectId", prevId}},
+		bson.D{{"$set", bson.D{{"objectId", object.Id}}}},
+	); err != nil {
+		return
+	}
+	if err = p.releaseRedirect(ctx, object); err != nil {
+		return
+	}
+	if object.Visibility != domain.VisibilityPublic {
+		return
+	}
+	return p.addRedirect(ctx, object, prevUri)
+}
+
+// addRedirect points the previous uri and all older uris of the object to the current uri
+func (p *publishRepo) addRedirect(ctx context.Context, object *domain.Object, prevUri string) (err error) {
+	if _, err = p.redirectColl.UpdateMany(
+		ctx,
+		bson.D{{"identity", object.Identity}, {"spaceId", object.SpaceId}, {"objectId", object.ObjectId}},
+		bson.D{{"$set", bson.D{{"targetUri", object.Uri}}}},
+	); err != nil {
+		return
+	}
+	redirect := domain.Redirect{
+		Id:        object.Identity + "/" + prevUri,
+		Identity:  object.Identity,
+		Uri:       prevUri,
+		SpaceId:   object.SpaceId,
+		ObjectId:  object.ObjectId,
+		TargetUri: object.Uri,
+		Timestamp: time.Now().Unix(),
+	}
+	_, err = p.redirectColl.ReplaceOne(ctx, bson.D{{"_id", redirect.Id}}, redirect, options.Replace().SetUpsert(true))
+	return
+}
+
+// releaseRedirect removes the redirect from the uri taken by the object
+func (p *publishRepo) releaseRedirect(ctx context.Context, object *domain.Object) (err error) {
+	_, err = p.redirectColl.DeleteOne(ctx, bson.D{{"_id", object.Id}})
+	return
+}
+
+func (p *publishRepo) ResolveRedirect(ctx context.Context, identity, uri string) (redirect domain.Redirect, err error) {
+	if err = p.redirectColl.FindOne(ctx, bson.D{{"_id", identity + "/" + uri}}).Decode(&redirect); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Redirect{}, publishapi.ErrNotFound
+		}
+	}
+	return
+}
+
+func (p *publishRepo) ListRedirects(ctx context.Context, object domain.Object) (redirects []domain.Redirect, err error) {
+	filter := bson.D{{"identity", object.Identity}}
+	if object.SpaceId != "" {
+		filter = append(filter, bson.E{Key: "spaceId", Value: object.SpaceId})
+	}
+	if object.ObjectId != "" {
+		filter = append(filter, bson.E{Key: "objectId", Value: object.ObjectId})
+	}
+	cur, err := p.redirectColl.Find(ctx, filter)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	err = cur.All(ctx, &redirects)
 	return
 }
 
-func (p *publishRepo) createPublish(ctx context.Context, object *domain.Object, version string) (publish *domain.Publish, err error) {
+func (p *publishRepo) DeleteRedirect(ctx context.Context, identity, uri string) (err error) {
+	res, err := p.redirectColl.DeleteOne(ctx, bson.D{{"_id", identity + "/" + uri}})
+	if err != nil {
+		return
+	}
+	if res.DeletedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	return
+}
+
+func (p *publishRepo) createPublish(ctx context.Context, object *domain.Object, params domain.PublishParams) (publish *domain.Publish, err error) {
 	publish = &domain.Publish{
-		Id:        primitive.NewObjectID(),
-		ObjectId:  object.Id,
-		Status:    domain.PublishStatusCreated,
-		Version:   version,
-		UploadKey: uuid.New().String(),
+		Id:          primitive.NewObjectID(),
+		ObjectId:    object.Id,
+		Status:      domain.PublishStatusCreated,
+		Version:     params.Version,
+		UploadKey:   uuid.New().String(),
+		Type:        params.Type,
+		SpaFallback: params.SpaFallback,
+		Members:     params.Members,
+	}
+	if params.Preview {
+		publish.PreviewKey = newRandomKey()
 	}
 	if _, err = p.publishColl.InsertOne(ctx, publish); err != nil {
 		return
@@ -193,6 +402,31 @@ func (p *publishRepo) ResolvePublishUri(ctx context.Context, identity, uri strin
 	return objectWithPublish.Object, nil
 }
 
+func (p *publishRepo) ResolveVersion(ctx context.Context, identity, uri, version string) (publish domain.ObjectWithPublish, err error) {
+	if err = p.objectsColl.FindOne(ctx, bson.D{{"_id", identity + "/" + uri}}).Decode(&publish.Object); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.ObjectWithPublish{}, err
+	}
+	versionQuery := bson.D{{"version", version}}
+	if id, idErr := primitive.ObjectIDFromHex(version); idErr == nil {
+		versionQuery = bson.D{{"$or", bson.A{versionQuery, bson.D{{"_id", id}}}}}
+	}
+	query := append(bson.D{
+		{"objectId", publish.Object.Id},
+		{"status", bson.D{{"$in", bson.A{domain.PublishStatusPublished, domain.PublishStatusArchived}}}},
+	}, versionQuery...)
+	opts := options.FindOne().SetSort(bson.D{{"_id", -1}})
+	if err = p.publishColl.FindOne(ctx, query, opts).Decode(&publish.Publish); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.ObjectWithPublish{}, err
+	}
+	return
+}
+
 func (p *publishRepo) getPublishByQuery(ctx context.Context, query any, withPublish bool) (publish domain.ObjectWithPublish, err error) {
 	if err = p.objectsColl.FindOne(ctx, query).Decode(&publish.Object); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -239,6 +473,72 @@ func (p *publishRepo) ListPublishes(ctx context.Context, identity string, spaceI
 	return publishes, nil
 }
 
+func (p *publishRepo) SetNotFoundPage(ctx context.Context, object domain.Object) (err error) {
+	return p.db.Tx(ctx, func(ctx mongo.SessionContext) (err error) {
+		if _, err = p.objectsColl.UpdateMany(
+			ctx,
+			bson.D{{"identity", object.Identity}, {"notFoundPage", true}},
+			bson.D{{"$unset", bson.D{{"notFoundPage", ""}}}},
+		); err != nil {
+			return
+		}
+		// empty objectId means reset
+		if object.ObjectId == "" {
+			return
+		}
+		res, err := p.objectsColl.UpdateOne(
+			ctx,
+			bson.D{{"identity", object.Identity}, {"spaceId", object.SpaceId}, {"objectId", object.ObjectId}},
+			bson.D{{"$set", bson.D{{"notFoundPage", true}}}},
+		)
+		if err != nil {
+			return
+		}
+		if res.MatchedCount == 0 {
+			return publishapi.ErrNotFound
+		}
+		return
+	})
+}
+
+func (p *publishRepo) ResolveNotFoundPage(ctx context.Context, identity string) (publish domain.ObjectWithPublish, err error) {
+	return p.getPublishByQuery(ctx, bson.D{{"identity", identity}, {"notFoundPage", true}}, true)
+}
+
+func (p *publishRepo) EnsureShareNonce(ctx context.Context, object domain.Object) (result domain.Object, err error) {
+	query := bson.D{{"identity", object.Identity}, {"spaceId", object.SpaceId}, {"objectId", object.ObjectId}}
+	// set the nonce only once, so concurrent calls don't revoke each other links
+	if _, err = p.objectsColl.UpdateOne(
+		ctx,
+		append(query, bson.E{Key: "shareNonce", Value: bson.D{{"$exists", false}}}),
+		bson.D{{"$set", bson.D{{"shareNonce", newRandomKey()}}}},
+	); err != nil {
+		return
+	}
+	if err = p.objectsColl.FindOne(ctx, query).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.Object{}, err
+	}
+	return
+}
+
+func (p *publishRepo) RotateShareNonce(ctx context.Context, object domain.Object) (err error) {
+	res, err := p.objectsColl.UpdateOne(
+		ctx,
+		bson.D{{"identity", object.Identity}, {"spaceId", object.SpaceId}, {"objectId", object.ObjectId}},
+		bson.D{{"$set", bson.D{{"shareNonce", newRandomKey()}}}},
+	)
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return publishapi.ErrNotFound
+	}
+	return
+}
+
 func (p *publishRepo) ObjectDelete(ctx context.Context, object domain.Object) (uri string, err error) {
 	err = p.db.Tx(ctx, func(ctx mongo.SessionContext) (err error) {
 		var query = bson.D{{"identity", object.Identity}, {"spaceId", object.SpaceId}, {"objectId", object.ObjectId}}
@@ -250,19 +550,31 @@ func (p *publishRepo) ObjectDelete(ctx context.Context, object domain.Object) (u
 		if _, err = p.objectsColl.DeleteOne(ctx, bson.D{{"_id", existingObject.Id}}); err != nil {
 			return
 		}
-		if existingObject.ActivePublishId != nil {
-			return p.markPublishToDelete(ctx, *existingObject.ActivePublishId)
+		if _, err = p.redirectColl.DeleteMany(ctx, query); err != nil {
+			return
 		}
+		// delete the active publish, all archived versions and previews
+		_, err = p.publishColl.UpdateMany(
+			ctx,
+			bson.D{
+				{"objectId", existingObject.Id},
+				{"status", bson.D{{"$in", bson.A{domain.PublishStatusPublished, domain.PublishStatusArchived, domain.PublishStatusPreview}}}},
+			},
+			bson.D{{"$set", bson.D{{"status", domain.PublishStatusReadyToDelete}}}},
+		)
 		return
 	})
 	return
 }
 
-func (p *publishRepo) markPublishToDelete(ctx context.Context, id primitive.ObjectID) (err error) {
+func (p *publishRepo) archivePublish(ctx context.Context, id primitive.ObjectID) (err error) {
 	if _, err = p.publishColl.UpdateOne(
 		ctx,
 		bson.D{{"_id", id}},
-		bson.D{{"$set", bson.D{{"status", domain.PublishStatusReadyToDelete}}}},
+		bson.D{{"$set", bson.D{
+			{"status", domain.PublishStatusArchived},
+			{"archivedTimestamp", time.Now().Unix()},
+		}}},
 	); err != nil {
 		return
 	}
@@ -284,24 +596,65 @@ func (p *publishRepo) GetPublish(ctx context.Context, id primitive.ObjectID) (pu
 	}, nil
 }
 
+func (p *publishRepo) ClaimUpload(ctx context.Context, id primitive.ObjectID, uploadKey string) (publish domain.ObjectWithPublish, err error) {
+	filter := bson.D{
+		{"_id", id},
+		{"status", domain.PublishStatusCreated},
+	}
+	if uploadKey != "" {
+		filter = append(filter, bson.E{"uploadKey", uploadKey})
+	}
+	res, err := p.publishColl.UpdateOne(ctx, filter, bson.D{{"$set", bson.D{{"status", domain.PublishStatusUploading}}}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		var ok bool
+		if ok, err = p.HasPublish(ctx, id); err != nil {
+			return
+		}
+		if ok {
+			return publish, ErrUploadUsed
+		}
+		return publish, publishapi.ErrNotFound
+	}
+	return p.GetPublish(ctx, id)
+}
+
 func (p *publishRepo) FinalizePublish(ctx context.Context, publish domain.ObjectWithPublish) (err error) {
 	return p.db.Tx(ctx, func(ctx mongo.SessionContext) (err error) {
 		var obj = publish.Object
-		// mark previous publish to delete
-		if obj.ActivePublishId != nil {
-			if err = p.markPublishToDelete(ctx, *obj.ActivePublishId); err != nil {
+		isPreview := publish.Publish.Status == domain.PublishStatusPreview
+		// keep previous publish for version permalinks
+		if obj.ActivePublishId != nil && !isPreview {
+			if err = p.archivePublish(ctx, *obj.ActivePublishId); err != nil {
 				return err
 			}
 		}
-		// update publish
-		if _, err = p.publishColl.UpdateOne(
+		// only the uploading publish or the preview can be finalized, the cleanup may have marked it to delete meanwhile
+		res, err := p.publishColl.UpdateOne(
 			ctx,
-			bson.D{{"_id", publish.Publish.Id}},
+			bson.D{
+				{"_id", publish.Publish.Id},
+				{"status", bson.D{{"$in", bson.A{domain.PublishStatusUploading, domain.PublishStatusPreview}}}},
+			},
 			bson.D{{"$set", bson.D{
 				{"status", publish.Publish.Status},
 				{"size", publish.Publish.Size},
+				{"notFoundHtml", publish.Publish.NotFoundHtml},
+				{"rules", publish.Publish.Rules},
+				{"previewKey", publish.Publish.PreviewKey},
+				{"previewExpire", publish.Publish.PreviewExpire},
 			}}},
-		); err != nil {
+		)
+		if err != nil {
+			return
+		}
+		if res.MatchedCount == 0 {
+			return publishapi.ErrNotFound
+		}
+		// the preview doesn't replace the live version
+		if isPreview {
 			return
 		}
 		// update object
@@ -315,10 +668,23 @@ func (p *publishRepo) FinalizePublish(ctx context.Context, publish domain.Object
 		); err != nil {
 			return
 		}
+		if publish.Publish.NotFoundHtml && !obj.NotFoundPage {
+			return p.ensureNotFoundPage(ctx, obj)
+		}
 		return
 	})
 }
 
+// ensureNotFoundPage makes the object the 404 page of the identity unless the identity has chosen one
+func (p *publishRepo) ensureNotFoundPage(ctx context.Context, object domain.Object) (err error) {
+	count, err := p.objectsColl.CountDocuments(ctx, bson.D{{"identity", object.Identity}, {"notFoundPage", true}}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return
+	}
+	_, err = p.objectsColl.UpdateOne(ctx, bson.D{{"_id", object.Id}}, bson.D{{"$set", bson.D{{"notFoundPage", true}}}})
+	return
+}
+
 func (p *publishRepo) IterateReadyToDeleteIds(ctx context.Context, do func(id primitive.ObjectID) error) error {
 	opts := options.Find().SetProjection(bson.D{{"_id", 1}})
 	cur, err := p.publishColl.Find(ctx, bson.D{{"status", domain.PublishStatusReadyToDelete}}, opts)
@@ -348,21 +714,150 @@ func (p *publishRepo) DeletePublish(ctx context.Context, id primitive.ObjectID)
 }
 
 func (p *publishRepo) DeleteOutdatedPublishes(ctx context.Context, before time.Time) (deleted int, err error) {
-	query := bson.D{
+	res, err := p.publishColl.DeleteMany(ctx, outdatedPublishesQuery(before))
+	if err != nil {
+		return
+	}
+	return int(res.DeletedCount), nil
+}
+
+func (p *publishRepo) DeleteStaleUploads(ctx context.Context, before time.Time) (deleted int, err error) {
+	res, err := p.publishColl.UpdateMany(ctx, staleUploadsQuery(before), bson.D{{"$set", bson.D{{"status", domain.PublishStatusReadyToDelete}}}})
+	if err != nil {
+		return
+	}
+	return int(res.ModifiedCount), nil
+}
+
+func (p *publishRepo) DeleteOutdatedObjects(ctx context.Context, before time.Time) (deleted int, err error) {
+	res, err := p.publishColl.DeleteMany(ctx, outdatedObjectsQuery(before))
+	if err != nil {
+		return
+	}
+	return int(res.DeletedCount), nil
+}
+
+func (p *publishRepo) DeleteOutdatedVersions(ctx context.Context, before time.Time) (deleted int, err error) {
+	res, err := p.publishColl.UpdateMany(ctx, outdatedVersionsQuery(before), bson.D{{"$set", bson.D{{"status", domain.PublishStatusReadyToDelete}}}})
+	if err != nil {
+		return
+	}
+	return int(res.ModifiedCount), nil
+}
+
+func (p *publishRepo) DeleteExpiredPreviews(ctx context.Context, before time.Time) (deleted int, err error) {
+	err = p.db.Tx(ctx, func(ctx mongo.SessionContext) (err error) {
+		query := expiredPreviewsQuery(before)
+		objectIds, err := p.publishColl.Distinct(ctx, "objectId", query)
+		if err != nil {
+			return
+		}
+		res, err := p.publishColl.UpdateMany(ctx, query, bson.D{{"$set", bson.D{{"status", domain.PublishStatusReadyToDelete}}}})
+		if err != nil {
+			return
+		}
+		deleted = int(res.ModifiedCount)
+		for _, objectId := range objectIds {
+			if err = p.deletePreviewObject(ctx, objectId); err != nil {
+				return
+			}
+		}
+		return
+	})
+	return
+}
+
+// deletePreviewObject deletes the object created by the preview when it was never published and has no pending publishes
+func (p *publishRepo) deletePreviewObject(ctx context.Context, objectId any) (err error) {
+	count, err := p.publishColl.CountDocuments(ctx, bson.D{
+		{"objectId", objectId},
+		{"status", bson.D{{"$in", bson.A{domain.PublishStatusCreated, domain.PublishStatusUploading, domain.PublishStatusPreview}}}},
+	}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return
+	}
+	_, err = p.objectsColl.DeleteOne(ctx, bson.D{{"_id", objectId}, {"activePublishId", bson.D{{"$exists", false}}}})
+	return
+}
+
+func (p *publishRepo) CountCleanup(ctx context.Context, before, uploadsBefore, versionsBefore time.Time) (report domain.CleanupReport, err error) {
+	count := func(coll *mongo.Collection, query bson.D) (n int) {
+		if err != nil {
+			return
+		}
+		var res int64
+		res, err = coll.CountDocuments(ctx, query)
+		return int(res)
+	}
+	report.OutdatedPublishes = count(p.publishColl, outdatedPublishesQuery(before))
+	report.StaleUploads = count(p.publishColl, staleUploadsQuery(uploadsBefore))
+	report.OutdatedObjects = count(p.publishColl, outdatedObjectsQuery(before))
+	report.OutdatedVersions = count(p.publishColl, outdatedVersionsQuery(versionsBefore))
+	report.ExpiredPreviews = count(p.publishColl, expiredPreviewsQuery(time.Now()))
+	// publishes marked by the cleanup itself are deleted in the same run
+	report.DeletedPublishes = count(p.publishColl, bson.D{{"status", domain.PublishStatusReadyToDelete}}) +
+		report.StaleUploads + report.OutdatedVersions + report.ExpiredPreviews
+	return
+}
+
+func (p *publishRepo) HasPublish(ctx context.Context, id primitive.ObjectID) (ok bool, err error) {
+	count, err := p.publishColl.CountDocuments(ctx, bson.D{{"_id", id}}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+func (p *publishRepo) IterateObjects(ctx context.Context, identity string, do func(publish domain.ObjectWithPublish) error) error {
+	filter := bson.D{}
+	if identity != "" {
+		filter = bson.D{{"identity", identity}}
+	}
+	cur, err := p.objectsColl.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cur.Close(context.Background())
+	}()
+	for cur.Next(ctx) {
+		var publish domain.ObjectWithPublish
+		if err = cur.Decode(&publish.Object); err != nil {
+			return err
+		}
+		if publish.ActivePublishId != nil {
+			publish.Publish = &domain.Publish{}
+			if err = p.publishColl.FindOne(ctx, bson.D{{"_id", *publish.ActivePublishId}}).Decode(publish.Publish); err != nil {
+				if !errors.Is(err, mongo.ErrNoDocuments) {
+					return err
+				}
+				publish.Publish = nil
+			}
+		}
+		if err = do(publish); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+func outdatedPublishesQuery(before time.Time) bson.D {
+	return bson.D{
 		{"status", domain.PublishStatusCreated},
 		{"_id", bson.D{
 			{"$lt", primitive.NewObjectIDFromTimestamp(before)},
 		}},
 	}
-	res, err := p.publishColl.DeleteMany(ctx, query)
-	if err != nil {
-		return
+}
+
+func staleUploadsQuery(before time.Time) bson.D {
+	return bson.D{
+		{"status", domain.PublishStatusUploading},
+		{"_id", bson.D{
+			{"$lt", primitive.NewObjectIDFromTimestamp(before)},
+		}},
 	}
-	return int(res.DeletedCount), nil
 }
 
-func (p *publishRepo) DeleteOutdatedObjects(ctx context.Context, before time.Time) (deleted int, err error) {
-	query := bson.D{
+func outdatedObjectsQuery(before time.Time) bson.D {
+	return bson.D{
 		{"activePublishId", bson.D{
 			{"$exists", false},
 		}},
@@ -370,11 +865,92 @@ func (p *publishRepo) DeleteOutdatedObjects(ctx context.Context, before time.Tim
 			{"$lt", before.Unix()},
 		}},
 	}
-	res, err := p.publishColl.DeleteMany(ctx, query)
+}
+
+func outdatedVersionsQuery(before time.Time) bson.D {
+	return bson.D{
+		{"status", domain.PublishStatusArchived},
+		{"archivedTimestamp", bson.D{
+			{"$lt", before.Unix()},
+		}},
+	}
+}
+
+func expiredPreviewsQuery(before time.Time) bson.D {
+	return bson.D{
+		{"status", domain.PublishStatusPreview},
+		{"previewExpire", bson.D{
+			{"$lt", before.Unix()},
+		}},
+	}
+}
+
+func (p *publishRepo) SetLimitOverride(ctx context.Context, identity string, limit int64) (err error) {
+	if limit <= 0 {
+		_, err = p.limitsColl.DeleteOne(ctx, bson.D{{"_id", identity}})
+		return
+	}
+	override := domain.LimitOverride{Identity: identity, Limit: limit, Timestamp: time.Now().Unix()}
+	_, err = p.limitsColl.ReplaceOne(ctx, bson.D{{"_id", identity}}, override, options.Replace().SetUpsert(true))
+	return
+}
+
+func (p *publishRepo) GetLimitOverride(ctx context.Context, identity string) (limit int64, err error) {
+	var override domain.LimitOverride
+	if err = p.limitsColl.FindOne(ctx, bson.D{{"_id", identity}}).Decode(&override); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, publishapi.ErrNotFound
+		}
+		return
+	}
+	return override.Limit, nil
+}
+
+func (p *publishRepo) StorageUsage(ctx context.Context, identity string) (usage domain.StorageUsage, err error) {
+	var pipeline mongo.Pipeline
+	if identity != "" {
+		pipeline = append(pipeline, bson.D{{"$match", bson.D{{"identity", identity}}}})
+	}
+	cur, err := p.objectsColl.Aggregate(ctx, append(pipeline,
+		bson.D{{"$lookup", bson.D{
+			{"from", p.publishColl.Name()},
+			{"localField", "_id"},
+			{"foreignField", "objectId"},
+			{"as", "publishes"},
+		}}},
+		bson.D{{"$group", bson.D{
+			{"_id", nil},
+			{"objects", bson.D{{"$sum", 1}}},
+			{"publishes", bson.D{{"$sum", bson.D{{"$size", "$publishes"}}}}},
+			{"size", bson.D{{"$sum", bson.D{{"$sum", "$publishes.size"}}}}},
+		}}},
+	))
 	if err != nil {
 		return
 	}
-	return int(res.DeletedCount), nil
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	if cur.Next(ctx) {
+		err = cur.Decode(&usage)
+	} else {
+		err = cur.Err()
+	}
+	return
+}
+
+// newUriSuffix returns the unguessable suffix of the unlisted uri
+func newUriSuffix() string {
+	var buf = make([]byte, 10)
+	_, _ = rand.Read(buf)
+	return strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+}
+
+// newRandomKey returns the unguessable url-safe key for share nonces and preview keys
+func newRandomKey() string {
+	var buf = make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
 }
 
 func (p *publishRepo) Close(ctx context.Context) (err error) {
@@ -3,6 +3,7 @@ package publishrepo
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +30,7 @@ func TestPublishRepo_ObjectCreate(t *testing.T) {
 	t.Run("new publish", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		publish, prevUri, err := fx.ObjectCreate(ctx, obj, "v1")
+		publish, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		assertObject(t, obj, publish.Object)
 		require.NotEmpty(t, publish.Publish)
@@ -38,13 +39,23 @@ func TestPublishRepo_ObjectCreate(t *testing.T) {
 		assert.NotEmpty(t, publish.Publish.UploadKey)
 		assert.Empty(t, prevUri)
 	})
+	t.Run("static publish", func(t *testing.T) {
+		fx := newFixture(t)
+		obj := newTestObj()
+		publish, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1", Type: domain.PublishTypeStatic, SpaFallback: true})
+		require.NoError(t, err)
+		stored, err := fx.GetPublish(ctx, publish.Publish.Id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.PublishTypeStatic, stored.Publish.Type)
+		assert.True(t, stored.Publish.SpaFallback)
+	})
 	t.Run("update same object", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		_, prevUri, err := fx.ObjectCreate(ctx, obj, "v1")
+		_, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		var publish domain.ObjectWithPublish
-		publish, prevUri, err = fx.ObjectCreate(ctx, obj, "v2")
+		publish, prevUri, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
 		require.NoError(t, err)
 		require.NotEmpty(t, publish.Publish)
 		assert.Equal(t, "v2", publish.Publish.Version)
@@ -53,11 +64,11 @@ func TestPublishRepo_ObjectCreate(t *testing.T) {
 	t.Run("change uri", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		_, prevUri, err := fx.ObjectCreate(ctx, obj, "v1")
+		_, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		obj.Uri = "u2"
 		var publish domain.ObjectWithPublish
-		publish, prevUri, err = fx.ObjectCreate(ctx, obj, "v2")
+		publish, prevUri, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
 		require.NoError(t, err)
 		require.NotEmpty(t, publish.Publish)
 		assert.Equal(t, "v2", publish.Publish.Version)
@@ -66,19 +77,134 @@ func TestPublishRepo_ObjectCreate(t *testing.T) {
 	t.Run("change uri to the taken one", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		_, _, err := fx.ObjectCreate(ctx, obj, "v1")
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		obj.ObjectId = "o2"
-		_, _, err = fx.ObjectCreate(ctx, obj, "v2")
+		_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
 		require.ErrorIs(t, err, publishapi.ErrUriNotUnique)
 	})
 }
 
+func TestPublishRepo_Redirects(t *testing.T) {
+	t.Run("change uri", func(t *testing.T) {
+		fx := newFixture(t)
+		obj := newTestObj()
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+		obj.Uri = "u2"
+		_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
+		require.NoError(t, err)
+		obj.Uri = "u3"
+		_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v3"})
+		require.NoError(t, err)
+
+		for _, uri := range []string{"u1", "u2"} {
+			redirect, err := fx.ResolveRedirect(ctx, obj.Identity, uri)
+			require.NoError(t, err)
+			assert.Equal(t, "u3", redirect.TargetUri)
+		}
+		_, err = fx.ResolveRedirect(ctx, obj.Identity, "u3")
+		require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+		list, err := fx.ListRedirects(ctx, obj)
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+
+		require.NoError(t, fx.DeleteRedirect(ctx, obj.Identity, "u1"))
+		_, err = fx.ResolveRedirect(ctx, obj.Identity, "u1")
+		require.ErrorIs(t, err, publishapi.ErrNotFound)
+	})
+	t.Run("release on reuse", func(t *testing.T) {
+		fx := newFixture(t)
+		obj := newTestObj()
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+		obj.Uri = "u2"
+		_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
+		require.NoError(t, err)
+
+		obj2 := newTestObj()
+		obj2.ObjectId = "o2"
+		_, _, err = fx.ObjectCreate(ctx, obj2, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+		_, err = fx.ResolveRedirect(ctx, obj.Identity, "u1")
+		require.ErrorIs(t, err, publishapi.ErrNotFound)
+	})
+	t.Run("delete object", func(t *testing.T) {
+		fx := newFixture(t)
+		obj := newTestObj()
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+		obj.Uri = "u2"
+		_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
+		require.NoError(t, err)
+		_, err = fx.ObjectDelete(ctx, obj)
+		require.NoError(t, err)
+		_, err = fx.ResolveRedirect(ctx, obj.Identity, "u1")
+		require.ErrorIs(t, err, publishapi.ErrNotFound)
+	})
+}
+
+func TestPublishRepo_Visibility(t *testing.T) {
+	fx := newFixture(t)
+	obj := newTestObj()
+	_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+	require.NoError(t, err)
+
+	// unlisted gets the suffix and no redirect from the public uri
+	obj.Visibility = domain.VisibilityUnlisted
+	unlisted, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, "u1", prevUri)
+	assert.Len(t, unlisted.UriSuffix, 16)
+	assert.Equal(t, "u1-"+unlisted.UriSuffix, unlisted.Uri)
+	_, err = fx.ResolveRedirect(ctx, obj.Identity, "u1")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	// suffix is kept on republish
+	publish, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v3"})
+	require.NoError(t, err)
+	assert.Empty(t, prevUri)
+	assert.Equal(t, unlisted.Uri, publish.Uri)
+
+	// private keeps the requested uri
+	obj.Visibility = domain.VisibilityPrivate
+	publish, prevUri, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v4"})
+	require.NoError(t, err)
+	assert.Equal(t, unlisted.Uri, prevUri)
+	assert.Equal(t, "u1", publish.Uri)
+	assert.Empty(t, publish.UriSuffix)
+	resolved, err := fx.ResolveUri(ctx, obj.Identity, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.VisibilityPrivate, resolved.Visibility)
+}
+
+func TestPublishRepo_ShareNonce(t *testing.T) {
+	fx := newFixture(t)
+	obj := newTestObj()
+	_, err := fx.EnsureShareNonce(ctx, obj)
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	_, _, err = fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+	require.NoError(t, err)
+	res, err := fx.EnsureShareNonce(ctx, obj)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.ShareNonce)
+	res2, err := fx.EnsureShareNonce(ctx, obj)
+	require.NoError(t, err)
+	assert.Equal(t, res.ShareNonce, res2.ShareNonce)
+
+	require.NoError(t, fx.RotateShareNonce(ctx, obj))
+	res2, err = fx.EnsureShareNonce(ctx, obj)
+	require.NoError(t, err)
+	assert.NotEqual(t, res.ShareNonce, res2.ShareNonce)
+}
+
 func TestPublishRepo_ObjectPublishStatus(t *testing.T) {
 	t.Run("created", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		_, _, err := fx.ObjectCreate(ctx, obj, "v1")
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		publish, err := fx.ObjectPublishStatus(ctx, obj)
 		require.NoError(t, err)
@@ -88,7 +214,7 @@ func TestPublishRepo_ObjectPublishStatus(t *testing.T) {
 	t.Run("published", func(t *testing.T) {
 		fx := newFixture(t)
 		obj := newTestObj()
-		publishObj, _, err := fx.ObjectCreate(ctx, obj, "v1")
+		publishObj, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
 		require.NoError(t, err)
 		uploadKey := publishObj.Publish.UploadKey
 		publish, err := fx.GetPublish(ctx, publishObj.Publish.Id)
@@ -96,6 +222,7 @@ func TestPublishRepo_ObjectPublishStatus(t *testing.T) {
 		assert.Equal(t, publish.Publish.UploadKey, uploadKey)
 		publish.Publish.Size = 123
 		publish.Publish.Status = domain.PublishStatusPublished
+		fx.claim(t, publish.Publish.Id)
 		require.NoError(t, fx.FinalizePublish(ctx, publish))
 		publishObj, err = fx.ObjectPublishStatus(ctx, obj)
 		require.NoError(t, err)
@@ -111,6 +238,252 @@ func TestPublishRepo_ObjectPublishStatus(t *testing.T) {
 	})
 }
 
+func TestPublishRepo_ResolveVersion(t *testing.T) {
+	fx := newFixture(t)
+	obj := newTestObj()
+	publishVersion := func(uri, version string) primitive.ObjectID {
+		obj.Uri = uri
+		publishObj, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: version})
+		require.NoError(t, err)
+		publish, err := fx.GetPublish(ctx, publishObj.Publish.Id)
+		require.NoError(t, err)
+		publish.Publish.Status = domain.PublishStatusPublished
+		fx.claim(t, publish.Publish.Id)
+		require.NoError(t, fx.FinalizePublish(ctx, publish))
+		return publishObj.Publish.Id
+	}
+	v1Id := publishVersion("u1", "v1")
+	publishVersion("u1", "v2")
+	// versions follow the uri change
+	publishVersion("u2", "v3")
+
+	publish, err := fx.ResolveVersion(ctx, obj.Identity, "u2", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishStatusArchived, publish.Publish.Status)
+	assert.NotEmpty(t, publish.Publish.ArchivedTimestamp)
+	assert.Equal(t, "u2", publish.Uri)
+
+	publish, err = fx.ResolveVersion(ctx, obj.Identity, "u2", v1Id.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "v1", publish.Publish.Version)
+
+	publish, err = fx.ResolveVersion(ctx, obj.Identity, "u2", "v3")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishStatusPublished, publish.Publish.Status)
+
+	_, err = fx.ResolveVersion(ctx, obj.Identity, "u1", "v1")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	deleted, err := fx.DeleteOutdatedVersions(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	_, err = fx.ResolveVersion(ctx, obj.Identity, "u2", "v1")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+}
+
+func TestPublishRepo_Preview(t *testing.T) {
+	fx := newFixture(t)
+	obj := newTestObj()
+	live, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+	require.NoError(t, err)
+	live.Publish.Status = domain.PublishStatusPublished
+	fx.claim(t, live.Publish.Id)
+	require.NoError(t, fx.FinalizePublish(ctx, live))
+
+	// the preview keeps the live uri and publish
+	obj.Uri = "u2"
+	preview, prevUri, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v2", Preview: true})
+	require.NoError(t, err)
+	assert.Empty(t, prevUri)
+	assert.Equal(t, "u1", preview.Uri)
+	require.NotEmpty(t, preview.Publish.PreviewKey)
+	preview.Publish.Status = domain.PublishStatusPreview
+	preview.Publish.PreviewExpire = time.Now().Add(time.Hour).Unix()
+	fx.claim(t, preview.Publish.Id)
+	require.NoError(t, fx.FinalizePublish(ctx, preview))
+
+	resolved, err := fx.ResolveUri(ctx, obj.Identity, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, live.Publish.Id, resolved.Publish.Id)
+	_, err = fx.ResolveVersion(ctx, obj.Identity, "u1", "v2")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	deleted, err := fx.DeleteExpiredPreviews(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	deleted, err = fx.DeleteExpiredPreviews(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = fx.ResolveUri(ctx, obj.Identity, "u1")
+	require.NoError(t, err)
+
+	// the object created by the preview is deleted with it
+	newObj := newTestObj()
+	newObj.ObjectId = "preview"
+	newObj.Uri = "u3"
+	preview, _, err = fx.ObjectCreate(ctx, newObj, domain.PublishParams{Version: "v1", Preview: true})
+	require.NoError(t, err)
+	preview.Publish.Status = domain.PublishStatusPreview
+	preview.Publish.PreviewExpire = time.Now().Add(time.Hour).Unix()
+	fx.claim(t, preview.Publish.Id)
+	require.NoError(t, fx.FinalizePublish(ctx, preview))
+	deleted, err = fx.DeleteExpiredPreviews(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = fx.ResolvePublishUri(ctx, newObj.Identity, "u3")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+}
+
+func TestPublishRepo_ClaimUpload(t *testing.T) {
+	fx := newFixture(t)
+	created, _, err := fx.ObjectCreate(ctx, newTestObj(), domain.PublishParams{Version: "v1"})
+	require.NoError(t, err)
+	id := created.Publish.Id
+
+	_, err = fx.ClaimUpload(ctx, id, "other key")
+	require.ErrorIs(t, err, ErrUploadUsed)
+	_, err = fx.ClaimUpload(ctx, primitive.NewObjectID(), "")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	// concurrent claims take the publish once
+	var (
+		claimed = make(chan error, 3)
+		wins    int
+	)
+	for range cap(claimed) {
+		go func() {
+			_, err := fx.ClaimUpload(ctx, id, created.Publish.UploadKey)
+			claimed <- err
+		}()
+	}
+	for range cap(claimed) {
+		if err := <-claimed; err == nil {
+			wins++
+		} else {
+			require.ErrorIs(t, err, ErrUploadUsed)
+		}
+	}
+	assert.Equal(t, 1, wins)
+
+	stored, err := fx.GetPublish(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishStatusUploading, stored.Publish.Status)
+	_, err = fx.ClaimUpload(ctx, id, "")
+	require.ErrorIs(t, err, ErrUploadUsed)
+
+	// failed uploads are marked to delete with their files
+	deleted, err := fx.DeleteOutdatedPublishes(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	deleted, err = fx.DeleteStaleUploads(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	deleted, err = fx.DeleteStaleUploads(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	stored, err = fx.GetPublish(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PublishStatusReadyToDelete, stored.Publish.Status)
+
+	// the publish marked to delete can't be finalized
+	stored.Publish.Status = domain.PublishStatusPublished
+	require.ErrorIs(t, fx.FinalizePublish(ctx, stored), publishapi.ErrNotFound)
+}
+
+func TestPublishRepo_LimitOverride(t *testing.T) {
+	fx := newFixture(t)
+	_, err := fx.GetLimitOverride(ctx, "a1")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+	require.NoError(t, fx.SetLimitOverride(ctx, "a1", 1<<30))
+	limit, err := fx.GetLimitOverride(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<30), limit)
+	require.NoError(t, fx.SetLimitOverride(ctx, "a1", 0))
+	_, err = fx.GetLimitOverride(ctx, "a1")
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+}
+
+func TestPublishRepo_StorageUsage(t *testing.T) {
+	fx := newFixture(t)
+	obj := newTestObj()
+	for _, version := range []string{"v1", "v2"} {
+		pub, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: version})
+		require.NoError(t, err)
+		pub.Publish.Status = domain.PublishStatusPublished
+		pub.Publish.Size = 100
+		fx.claim(t, pub.Publish.Id)
+		require.NoError(t, fx.FinalizePublish(ctx, pub))
+	}
+	usage, err := fx.StorageUsage(ctx, obj.Identity)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{Size: 200, Publishes: 2, Objects: 1}, usage)
+
+	usage, err = fx.StorageUsage(ctx, "a2")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StorageUsage{}, usage)
+}
+
+func TestPublishRepo_SetNotFoundPage(t *testing.T) {
+	fx := newFixture(t)
+	obj1 := newTestObj()
+	obj2 := newTestObj()
+	obj2.ObjectId = "o2"
+	obj2.Uri = "u2"
+	for _, obj := range []domain.Object{obj1, obj2} {
+		_, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+	}
+
+	_, err := fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	require.NoError(t, fx.SetNotFoundPage(ctx, obj1))
+	res, err := fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.NoError(t, err)
+	assertObject(t, obj1, res.Object)
+
+	require.NoError(t, fx.SetNotFoundPage(ctx, obj2))
+	res, err = fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.NoError(t, err)
+	assertObject(t, obj2, res.Object)
+
+	obj2.ObjectId = ""
+	require.NoError(t, fx.SetNotFoundPage(ctx, obj2))
+	_, err = fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.ErrorIs(t, err, publishapi.ErrNotFound)
+
+	obj2.ObjectId = "unknown"
+	require.ErrorIs(t, fx.SetNotFoundPage(ctx, obj2), publishapi.ErrNotFound)
+}
+
+func TestPublishRepo_FinalizeNotFoundHtml(t *testing.T) {
+	fx := newFixture(t)
+	obj1 := newTestObj()
+	obj2 := newTestObj()
+	obj2.ObjectId = "o2"
+	obj2.Uri = "u2"
+	publishWith404 := func(obj domain.Object) {
+		pub, _, err := fx.ObjectCreate(ctx, obj, domain.PublishParams{Version: "v1"})
+		require.NoError(t, err)
+		pub.Publish.Status = domain.PublishStatusPublished
+		pub.Publish.NotFoundHtml = true
+		fx.claim(t, pub.Publish.Id)
+		require.NoError(t, fx.FinalizePublish(ctx, pub))
+	}
+
+	// the uploaded 404.html makes the first object the 404 page
+	publishWith404(obj1)
+	res, err := fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.NoError(t, err)
+	assertObject(t, obj1, res.Object)
+
+	// the chosen 404 page isn't taken over by other uploads
+	publishWith404(obj2)
+	res, err = fx.ResolveNotFoundPage(ctx, obj1.Identity)
+	require.NoError(t, err)
+	assertObject(t, obj1, res.Object)
+}
+
 func TestPublishRepo_IterateReadyToDeleteIds(t *testing.T) {
 	fx := newFixture(t)
 	docs := []any{
@@ -163,9 +536,17 @@ type fixture struct {
 	a *app.App
 }
 
+// claim takes the created publish for the upload as the upload handlers do before the finalization
+func (fx *fixture) claim(t testing.TB, id primitive.ObjectID) {
+	_, err := fx.ClaimUpload(ctx, id, "")
+	require.NoError(t, err)
+}
+
 func (fx *fixture) finish(t testing.TB) {
 	_ = fx.PublishRepo.(*publishRepo).publishColl.Drop(ctx)
 	_ = fx.PublishRepo.(*publishRepo).objectsColl.Drop(ctx)
+	_ = fx.PublishRepo.(*publishRepo).redirectColl.Drop(ctx)
+	_ = fx.PublishRepo.(*publishRepo).limitsColl.Drop(ctx)
 	require.NoError(t, fx.a.Close(ctx))
 }
 
@@ -0,0 +1,254 @@
+// Package publishrules parses Netlify-style _redirects and _headers files and matches request paths against them.
+//
+// Rule paths are relative to the publish uri: "/" is the page itself and "/docs/*" matches every uri under {uri}/docs/.
+package publishrules
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+const (
+	RedirectsFileName = "_redirects"
+	HeadersFileName   = "_headers"
+
+	// MaxFileSize is the max size of the _redirects or _headers file
+	MaxFileSize = 64 << 10
+	// MaxRules is the max number of rules in one file
+	MaxRules = 1000
+
+	splat            = "*"
+	splatPlaceholder = ":splat"
+)
+
+var ErrInvalidSyntax = errors.New("invalid syntax")
+
+// allowedHeaders can be set by rules, others are managed by the gateway or affect the whole gateway domain
+var allowedHeaders = map[string]struct{}{
+	"Cache-Control":                       {},
+	"Content-Disposition":                 {},
+	"Content-Language":                    {},
+	"Content-Security-Policy":             {},
+	"Content-Security-Policy-Report-Only": {},
+	"Cross-Origin-Embedder-Policy":        {},
+	"Cross-Origin-Opener-Policy":          {},
+	"Cross-Origin-Resource-Policy":        {},
+	"Expires":                             {},
+	"Link":                                {},
+	"Permissions-Policy":                  {},
+	"Referrer-Policy":                     {},
+	"X-Content-Type-Options":              {},
+	"X-Frame-Options":                     {},
+	"X-Robots-Tag":                        {},
+}
+
+var redirectStatuses = []int{
+	http.StatusMovedPermanently,
+	http.StatusFound,
+	http.StatusSeeOther,
+	http.StatusTemporaryRedirect,
+	http.StatusPermanentRedirect,
+}
+
+// ParseRedirects parses the _redirects file, every line has the form "/from /to [status]"
+func ParseRedirects(r io.Reader) (rules []domain.RedirectRule, err error) {
+	err = scanLines(r, RedirectsFileName, func(line string) error {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return errors.New("expected: /from /to [status]")
+		}
+		rule := domain.RedirectRule{From: fields[0], To: fields[1], Status: http.StatusMovedPermanently}
+		if err := validatePath(rule.From); err != nil {
+			return err
+		}
+		if err := validateTarget(rule.To, strings.HasSuffix(rule.From, splat)); err != nil {
+			return err
+		}
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil || !slices.Contains(redirectStatuses, status) {
+				return fmt.Errorf("unsupported status %q", fields[2])
+			}
+			rule.Status = status
+		}
+		if len(rules) == MaxRules {
+			return fmt.Errorf("too many rules, max %d", MaxRules)
+		}
+		rules = append(rules, rule)
+		return nil
+	})
+	return
+}
+
+// ParseHeaders parses the _headers file: a path line followed by indented "Name: value" lines
+func ParseHeaders(r io.Reader) (rules []domain.HeaderRule, err error) {
+	err = scanLines(r, HeadersFileName, func(line string) error {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return nil
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			if len(rules) > 0 && len(rules[len(rules)-1].Headers) == 0 {
+				return fmt.Errorf("no headers for path %q", rules[len(rules)-1].Path)
+			}
+			if err := validatePath(trimmed); err != nil {
+				return err
+			}
+			if len(rules) == MaxRules {
+				return fmt.Errorf("too many rules, max %d", MaxRules)
+			}
+			rules = append(rules, domain.HeaderRule{Path: trimmed})
+			return nil
+		}
+		if len(rules) == 0 {
+			return errors.New("header without path")
+		}
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return errors.New("expected: Name: value")
+		}
+		name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if !isToken(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if _, allowed := allowedHeaders[name]; !allowed {
+			return fmt.Errorf("header %q can't be set", name)
+		}
+		if strings.ContainsFunc(value, isCtl) {
+			return fmt.Errorf("invalid value of header %q", name)
+		}
+		last := &rules[len(rules)-1]
+		last.Headers = append(last.Headers, domain.Header{Name: name, Value: value})
+		return nil
+	})
+	if err == nil && len(rules) > 0 && len(rules[len(rules)-1].Headers) == 0 {
+		err = fmt.Errorf("%w: %s: no headers for path %q", ErrInvalidSyntax, HeadersFileName, rules[len(rules)-1].Path)
+	}
+	return
+}
+
+// MatchRedirect returns the first redirect rule matching the path with the target where :splat is substituted
+func MatchRedirect(rules []domain.RedirectRule, path string) (rule domain.RedirectRule, to string, ok bool) {
+	for _, rule = range rules {
+		if matched, ok := match(rule.From, path); ok {
+			return rule, strings.ReplaceAll(rule.To, splatPlaceholder, matched), true
+		}
+	}
+	return domain.RedirectRule{}, "", false
+}
+
+// MatchHeaders returns the headers of all rules matching the path
+func MatchHeaders(rules []domain.HeaderRule, path string) (headers http.Header) {
+	for _, rule := range rules {
+		if _, ok := match(rule.Path, path); !ok {
+			continue
+		}
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		for _, h := range rule.Headers {
+			headers.Add(h.Name, h.Value)
+		}
+	}
+	return
+}
+
+// match checks the path against the pattern, a trailing * matches any suffix and is returned as splat
+func match(pattern, path string) (matched string, ok bool) {
+	if prefix, isSplat := strings.CutSuffix(pattern, splat); isSplat {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			return rest, true
+		}
+		// "/docs/*" matches "/docs" as well
+		return "", strings.TrimSuffix(prefix, "/") == path
+	}
+	if pattern != "/" {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return "", pattern == path
+}
+
+func scanLines(r io.Reader, fileName string, handle func(line string) error) error {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxFileSize {
+		return fmt.Errorf("%w: %s: file is larger than %d bytes", ErrInvalidSyntax, fileName, MaxFileSize)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 4096), MaxFileSize+1)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+		if err = handle(scanner.Text()); err != nil {
+			return fmt.Errorf("%w: %s line %d: %w", ErrInvalidSyntax, fileName, lineNum, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func validatePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path %q must start with /", path)
+	}
+	if idx := strings.Index(path, splat); idx != -1 && idx != len(path)-1 {
+		return fmt.Errorf("path %q: * is allowed only at the end", path)
+	}
+	if strings.ContainsAny(path, "?#") || strings.ContainsFunc(path, isCtl) {
+		return fmt.Errorf("invalid path %q", path)
+	}
+	return nil
+}
+
+func validateTarget(to string, withSplat bool) error {
+	if strings.Contains(to, splatPlaceholder) && !withSplat {
+		return fmt.Errorf("target %q uses :splat without * in the source path", to)
+	}
+	if strings.HasPrefix(to, "/") {
+		if strings.HasPrefix(to, "//") || strings.ContainsFunc(to, isCtl) {
+			return fmt.Errorf("invalid target %q", to)
+		}
+		return nil
+	}
+	u, err := url.Parse(to)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target %q must be a path or an http(s) url", to)
+	}
+	return nil
+}
+
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r >= 0x7f || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isCtl(r rune) bool {
+	return r < ' ' && r != '\t' || r == 0x7f
+}
@@ -0,0 +1,114 @@
+package publishrules
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func TestParseRedirects(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		rules, err := ParseRedirects(strings.NewReader(`
+# comment
+/old /new
+/docs/*   /documentation/:splat  302
+/ext https://example.com/page 308
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RedirectRule{
+			{From: "/old", To: "/new", Status: http.StatusMovedPermanently},
+			{From: "/docs/*", To: "/documentation/:splat", Status: http.StatusFound},
+			{From: "/ext", To: "https://example.com/page", Status: http.StatusPermanentRedirect},
+		}, rules)
+	})
+	for _, src := range []string{
+		"/old",
+		"old /new",
+		"/old /new 200",
+		"/old /new 301 Country=us",
+		"/a*b /new",
+		"/old /new/:splat",
+		"/old //evil.com",
+		"/old javascript:alert(1)",
+	} {
+		t.Run(src, func(t *testing.T) {
+			_, err := ParseRedirects(strings.NewReader(src))
+			require.ErrorIs(t, err, ErrInvalidSyntax)
+		})
+	}
+	t.Run("too large", func(t *testing.T) {
+		_, err := ParseRedirects(strings.NewReader(strings.Repeat("#", MaxFileSize+1)))
+		require.ErrorIs(t, err, ErrInvalidSyntax)
+	})
+}
+
+func TestParseHeaders(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		rules, err := ParseHeaders(strings.NewReader(`
+/*
+  x-frame-options: DENY
+  Cache-Control: public, max-age=60
+/docs
+	X-Robots-Tag: noindex
+`))
+		require.NoError(t, err)
+		assert.Equal(t, []domain.HeaderRule{
+			{Path: "/*", Headers: []domain.Header{{Name: "X-Frame-Options", Value: "DENY"}, {Name: "Cache-Control", Value: "public, max-age=60"}}},
+			{Path: "/docs", Headers: []domain.Header{{Name: "X-Robots-Tag", Value: "noindex"}}},
+		}, rules)
+	})
+	for name, src := range map[string]string{
+		"no path":       "  X-Robots-Tag: noindex",
+		"no headers":    "/a\n/b\n  X-Robots-Tag: noindex",
+		"last empty":    "/a\n  X-Robots-Tag: noindex\n/b",
+		"no colon":      "/a\n  X-Robots-Tag noindex",
+		"bad name":      "/a\n  X A: b",
+		"forbidden":     "/a\n  Set-Cookie: a=b",
+		"not allowed":   "/a\n  Strict-Transport-Security: max-age=63072000; includeSubDomains",
+		"unknown":       "/a\n  X-Accel-Redirect: /internal",
+		"relative path": "a\n  X-Robots-Tag: noindex",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseHeaders(strings.NewReader(src))
+			require.ErrorIs(t, err, ErrInvalidSyntax)
+		})
+	}
+}
+
+func TestMatchRedirect(t *testing.T) {
+	rules := []domain.RedirectRule{
+		{From: "/old/", To: "/new", Status: http.StatusMovedPermanently},
+		{From: "/docs/*", To: "/documentation/:splat", Status: http.StatusFound},
+	}
+	_, to, ok := MatchRedirect(rules, "/old")
+	require.True(t, ok)
+	assert.Equal(t, "/new", to)
+
+	rule, to, ok := MatchRedirect(rules, "/docs/a/b")
+	require.True(t, ok)
+	assert.Equal(t, http.StatusFound, rule.Status)
+	assert.Equal(t, "/documentation/a/b", to)
+
+	_, to, ok = MatchRedirect(rules, "/docs")
+	require.True(t, ok)
+	assert.Equal(t, "/documentation/", to)
+
+	_, _, ok = MatchRedirect(rules, "/other")
+	assert.False(t, ok)
+}
+
+func TestMatchHeaders(t *testing.T) {
+	rules := []domain.HeaderRule{
+		{Path: "/*", Headers: []domain.Header{{Name: "X-A", Value: "1"}}},
+		{Path: "/", Headers: []domain.Header{{Name: "X-B", Value: "2"}}},
+		{Path: "/sub", Headers: []domain.Header{{Name: "X-A", Value: "3"}}},
+	}
+	assert.Equal(t, http.Header{"X-A": {"1"}, "X-B": {"2"}}, MatchHeaders(rules, "/"))
+	assert.Equal(t, http.Header{"X-A": {"1", "3"}}, MatchHeaders(rules, "/sub"))
+	assert.Nil(t, MatchHeaders(rules[1:], "/other"))
+}
@@ -1,10 +1,11 @@
 package publish
 
 import (
-	"archive/tar"
 	"bufio"
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -13,21 +14,32 @@ import (
 	"strings"
 	"time"
 
+	"github.com/anyproto/any-sync/accountservice"
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/app/logger"
 	"github.com/anyproto/any-sync/app/ocache"
 	"github.com/anyproto/any-sync/metric"
 	"github.com/anyproto/any-sync/net/peer"
 	"github.com/anyproto/any-sync/net/rpc/server"
+	"github.com/anyproto/any-sync/util/crypto"
 	"github.com/anyproto/any-sync/util/periodicsync"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
 
+	"github.com/anyproto/anytype-publish-server/analytics"
+	"github.com/anyproto/anytype-publish-server/banlist"
+	"github.com/anyproto/anytype-publish-server/customdomain"
 	"github.com/anyproto/anytype-publish-server/domain"
 	"github.com/anyproto/anytype-publish-server/gateway/gatewayconfig"
+	"github.com/anyproto/anytype-publish-server/moderation"
 	"github.com/anyproto/anytype-publish-server/nameservice"
 	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publish/publishrules"
+	"github.com/anyproto/anytype-publish-server/publish/sharelink"
+	"github.com/anyproto/anytype-publish-server/publish/uploadtoken"
 	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+	"github.com/anyproto/anytype-publish-server/stats"
 	"github.com/anyproto/anytype-publish-server/store"
 )
 
@@ -41,15 +53,45 @@ const (
 	anytypeInternalLimit = 6000 << 20
 )
 
+const (
+	defaultShareLinkTTL = 24 * time.Hour
+	maxMembers          = 1000
+	// previewTTL is the lifetime of the uploaded preview publish
+	previewTTL = 7 * 24 * time.Hour
+	// uploadUrlTTL is the lifetime of the upload url, created publishes are deleted by the cleanup after an hour
+	uploadUrlTTL = 30 * time.Minute
+	// defaultVersionRetentionDays is the retention of archived versions when it is not configured
+	defaultVersionRetentionDays = 30
+	// staleUploadAfter is the time after the publish creation when its upload is considered failed,
+	// the upload starts before the url expires and takes at most the rest of the window
+	staleUploadAfter = uploadUrlTTL + 24*time.Hour
+)
+
+// NotFoundHtmlName is a file name of the custom 404 page inside the uploaded tar
+const NotFoundHtmlName = "404.html"
+
 var anytypeInternalNames = strings.Split(os.Getenv("INCREASED_LIMIT_NAMES"), ",")
 
+// ErrUploadLimitExceeded is returned when the uploaded files exceed the limit of the identity
+var ErrUploadLimitExceeded = errors.New("upload limit exceeded")
+
 func New() Service {
 	return new(publishService)
 }
 
 type Service interface {
-	ResolveUriWithIdentity(ctx context.Context, name, uri string) (publish domain.Object, err error)
-	SetInvalidateCacheCallback(f func(identity, uri string))
+	ResolveUriWithIdentity(ctx context.Context, name, uri string) (publish domain.ObjectWithPublish, err error)
+	ResolveNotFoundPage(ctx context.Context, identity string) (publish domain.ObjectWithPublish, err error)
+	ResolveRedirect(ctx context.Context, identity, uri string) (redirect domain.Redirect, err error)
+	ResolveVersion(ctx context.Context, identity, uri, version string) (publish domain.ObjectWithPublish, err error)
+	// ResolvePreview returns the uploaded not expired preview publish by the preview key
+	ResolvePreview(ctx context.Context, publishId, previewKey string) (publish domain.ObjectWithPublish, err error)
+	// SetInvalidateCacheCallback sets the cache invalidation, withVersions drops the cached version permalinks too
+	SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool))
+	GetLimit(ctx context.Context, identity string) (limit int, overridden bool, err error)
+	// Cleanup deletes outdated and unpublished publishes, it runs periodically when cleanupOn is set
+	Cleanup(ctx context.Context) error
+	RunCleanup(ctx context.Context, dryRun bool) (report domain.CleanupReport, err error)
 	app.ComponentRunnable
 }
 
@@ -60,18 +102,44 @@ type publishService struct {
 	repo           publishrepo.PublishRepo
 	ticker         periodicsync.PeriodicSync
 	nameService    nameservice.NameService
+	customDomain   customdomain.CustomDomain
 	metric         metric.Metric
-	invalidateFunc func(identity string, uri string)
+	invalidateFunc func(identity, uri string, withVersions bool)
+	stats          stats.Stats
+	analytics      analytics.Analytics
+	moderation     moderation.Moderation
+	banList        banlist.BanList
 }
 
 func (p *publishService) Init(a *app.App) (err error) {
 	p.repo = a.MustComponent(publishrepo.CName).(publishrepo.PublishRepo)
 	p.store = a.MustComponent(store.CName).(store.Store)
 	p.config = a.MustComponent("config").(configGetter).GetPublish()
+	if p.config.UploadTokenKey == "" {
+		// deployments without the key keep working, instances sharing the account derive the same key
+		signingKey := a.MustComponent("config").(accountservice.ConfigGetter).GetAccount().SigningKey
+		if signingKey == "" {
+			return errors.New("publish.uploadTokenKey is required")
+		}
+		p.config.UploadTokenKey = uploadtoken.DeriveKey(signingKey)
+		log.Warn("publish.uploadTokenKey is not set, the upload token key is derived from the account signing key")
+	}
+	if p.config.VersionRetentionDays <= 0 {
+		p.config.VersionRetentionDays = defaultVersionRetentionDays
+	}
 	p.gatewayConfig = a.MustComponent("config").(gatewayconfig.ConfigGetter).GetGateway()
 	p.nameService = a.MustComponent(nameservice.CName).(nameservice.NameService)
+	p.customDomain = a.MustComponent(customdomain.CName).(customdomain.CustomDomain)
 	p.metric = a.MustComponent(metric.CName).(metric.Metric)
-	return publishapi.DRPCRegisterWebPublisher(a.MustComponent(server.CName).(server.DRPCServer), &rpcHandler{s: p})
+	p.stats = a.MustComponent(stats.CName).(stats.Stats)
+	p.analytics = a.MustComponent(analytics.CName).(analytics.Analytics)
+	p.moderation = a.MustComponent(moderation.CName).(moderation.Moderation)
+	p.banList = a.MustComponent(banlist.CName).(banlist.BanList)
+	drpcServer := a.MustComponent(server.CName).(server.DRPCServer)
+	if err = publishapi.DRPCRegisterWebPublisher(drpcServer, &rpcHandler{s: p}); err != nil {
+		return
+	}
+	return publishapi.DRPCRegisterPublishAdmin(drpcServer, &adminRpcHandler{s: p})
 }
 
 func (p *publishService) Run(ctx context.Context) (err error) {
@@ -79,6 +147,9 @@ func (p *publishService) Run(ctx context.Context) (err error) {
 		p.ticker = periodicsync.NewPeriodicSync(300, 0, p.Cleanup, log)
 		p.ticker.Run()
 	}
+	if p.config.HttpApiAddr == "" {
+		return
+	}
 	mux := http.NewServeMux()
 	handler := httpHandler{s: p}
 	handler.init(mux)
@@ -99,13 +170,26 @@ func (p *publishService) Name() (name string) {
 	return CName
 }
 
-func (p *publishService) SetInvalidateCacheCallback(f func(identity, uri string)) {
+func (p *publishService) SetInvalidateCacheCallback(f func(identity, uri string, withVersions bool)) {
 	p.invalidateFunc = f
 }
 
+// invalidateCache drops the cached live page with its sub paths, version permalinks are kept across republishes
 func (p *publishService) invalidateCache(identity, uri string) {
 	if p.invalidateFunc != nil {
-		p.invalidateFunc(identity, uri)
+		p.invalidateFunc(identity, uri, false)
+	}
+}
+
+// invalidateNotFoundPages drops the cached 404 pages of the identity, they're tracked under the root uri
+func (p *publishService) invalidateNotFoundPages(identity string) {
+	p.invalidateCache(identity, "")
+}
+
+// invalidateCacheWithVersions drops the version permalinks too, they must go when the object is unpublished or hidden
+func (p *publishService) invalidateCacheWithVersions(identity, uri string) {
+	if p.invalidateFunc != nil {
+		p.invalidateFunc(identity, uri, true)
 	}
 }
 
@@ -117,8 +201,39 @@ func (p *publishService) ResolveUri(ctx context.Context, uri string) (publish do
 	return p.repo.ResolveUri(ctx, identity, uri)
 }
 
-func (p *publishService) ResolveUriWithIdentity(ctx context.Context, name, uri string) (publish domain.Object, err error) {
-	return p.repo.ResolvePublishUri(ctx, name, uri)
+func (p *publishService) ResolveUriWithIdentity(ctx context.Context, name, uri string) (publish domain.ObjectWithPublish, err error) {
+	return p.repo.ResolveUri(ctx, name, uri)
+}
+
+func (p *publishService) ResolveNotFoundPage(ctx context.Context, identity string) (publish domain.ObjectWithPublish, err error) {
+	return p.repo.ResolveNotFoundPage(ctx, identity)
+}
+
+func (p *publishService) ResolveRedirect(ctx context.Context, identity, uri string) (redirect domain.Redirect, err error) {
+	return p.repo.ResolveRedirect(ctx, identity, uri)
+}
+
+func (p *publishService) ResolveVersion(ctx context.Context, identity, uri, version string) (publish domain.ObjectWithPublish, err error) {
+	return p.repo.ResolveVersion(ctx, identity, uri, version)
+}
+
+func (p *publishService) ResolvePreview(ctx context.Context, publishId, previewKey string) (publish domain.ObjectWithPublish, err error) {
+	id, err := primitive.ObjectIDFromHex(publishId)
+	if err != nil {
+		return domain.ObjectWithPublish{}, publishapi.ErrNotFound
+	}
+	if publish, err = p.repo.GetPublish(ctx, id); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return domain.ObjectWithPublish{}, err
+	}
+	if publish.Publish.Status != domain.PublishStatusPreview ||
+		subtle.ConstantTimeCompare([]byte(publish.Publish.PreviewKey), []byte(previewKey)) != 1 ||
+		publish.Publish.PreviewExpire < time.Now().Unix() {
+		return domain.ObjectWithPublish{}, publishapi.ErrNotFound
+	}
+	return
 }
 
 func (p *publishService) GetPublishStatus(ctx context.Context, spaceId string, objectId string) (publish domain.ObjectWithPublish, err error) {
@@ -130,18 +245,100 @@ func (p *publishService) GetPublishStatus(ctx context.Context, spaceId string, o
 	return p.repo.ObjectPublishStatus(ctx, obj)
 }
 
-func (p *publishService) Publish(ctx context.Context, object domain.Object, version string) (uploadUrl string, err error) {
+func (p *publishService) Publish(ctx context.Context, object domain.Object, params domain.PublishParams) (publish domain.ObjectWithPublish, uploadUrl string, err error) {
 	if object.Identity, err = p.checkIdentity(ctx); err != nil {
 		return
 	}
-	publish, prevUri, err := p.repo.ObjectCreate(ctx, object, version)
+	if p.banList.IsBanned(domain.BanKindIdentity, object.Identity) {
+		return publish, "", publishapi.ErrAccessDenied
+	}
+	if params.Type > domain.PublishTypeStatic {
+		return publish, "", errors.New("unknown publish type")
+	}
+	if object.Visibility > domain.VisibilityPrivate {
+		return publish, "", errors.New("unknown visibility")
+	}
+	if len(params.Members) > maxMembers {
+		return publish, "", fmt.Errorf("too many members, max %d", maxMembers)
+	}
+	for _, member := range params.Members {
+		if _, err = crypto.DecodeAccountAddress(member); err != nil {
+			return publish, "", fmt.Errorf("invalid member identity %q: %w", member, err)
+		}
+	}
+	// taken down objects can't be republished
+	if _, err = p.moderation.GetTakedown(ctx, object); err == nil {
+		return publish, "", publishapi.ErrAccessDenied
+	} else if !errors.Is(err, publishapi.ErrNotFound) {
+		return
+	}
+	limit, _, err := p.GetLimit(ctx, object.Identity)
+	if err != nil {
+		return
+	}
+	prevVisibility := object.Visibility
+	if current, statusErr := p.repo.ObjectPublishStatus(ctx, object); statusErr == nil {
+		prevVisibility = current.Visibility
+	} else if !errors.Is(statusErr, publishapi.ErrNotFound) {
+		return publish, "", statusErr
+	}
+	publish, prevUri, err := p.repo.ObjectCreate(ctx, object, params)
 	if err != nil {
 		return
 	}
 	if prevUri != "" {
-		p.invalidateCache(object.Identity, prevUri)
+		p.invalidateCacheWithVersions(object.Identity, prevUri)
+	}
+	if !params.Preview && prevVisibility != publish.Visibility {
+		// visibility change takes effect before the upload
+		p.invalidateCacheWithVersions(object.Identity, publish.Uri)
+	}
+	token := uploadtoken.Sign([]byte(p.config.UploadTokenKey), uploadtoken.Token{
+		PublishId: publish.Publish.Id.Hex(),
+		Limit:     int64(limit),
+		Expire:    time.Now().Add(uploadUrlTTL).Unix(),
+		Nonce:     publish.Publish.UploadKey,
+	})
+	if uploadUrl, err = url.JoinPath(p.config.UploadUrlPrefix, publish.Publish.Id.Hex(), token); err != nil {
+		return
+	}
+	return publish, uploadUrl, nil
+}
+
+// PromotePreview makes the uploaded preview the live version of the object
+func (p *publishService) PromotePreview(ctx context.Context, object domain.Object, publishId string) (err error) {
+	if object.Identity, err = p.checkIdentity(ctx); err != nil {
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(publishId)
+	if err != nil {
+		return publishapi.ErrNotFound
+	}
+	objWithPub, err := p.repo.GetPublish(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
+		return
+	}
+	if objWithPub.Identity != object.Identity || objWithPub.SpaceId != object.SpaceId || objWithPub.ObjectId != object.ObjectId ||
+		objWithPub.Publish.Status != domain.PublishStatusPreview || objWithPub.Publish.PreviewExpire < time.Now().Unix() {
+		return publishapi.ErrNotFound
+	}
+	if p.banList.IsBanned(domain.BanKindIdentity, object.Identity) || p.banList.IsBanned(domain.BanKindPublish, publishId) {
+		return publishapi.ErrAccessDenied
 	}
-	return url.JoinPath(p.config.UploadUrlPrefix, publish.Publish.Id.Hex(), publish.Publish.UploadKey)
+	// the object could be taken down after the preview upload
+	if _, err = p.moderation.GetTakedown(ctx, object); err == nil {
+		return publishapi.ErrAccessDenied
+	} else if !errors.Is(err, publishapi.ErrNotFound) {
+		return
+	}
+	publish := objWithPub.Publish
+	publish.Status = domain.PublishStatusPublished
+	publish.PreviewKey = ""
+	publish.PreviewExpire = 0
+	return p.finalizePublish(ctx, objWithPub)
 }
 
 func (p *publishService) UnPublish(ctx context.Context, object domain.Object) (err error) {
@@ -152,7 +349,9 @@ func (p *publishService) UnPublish(ctx context.Context, object domain.Object) (e
 	if err != nil {
 		return err
 	}
-	p.invalidateCache(object.Identity, uri)
+	p.invalidateCacheWithVersions(object.Identity, uri)
+	// the unpublished object may be the 404 page
+	p.invalidateNotFoundPages(object.Identity)
 	return
 }
 
@@ -164,81 +363,322 @@ func (p *publishService) ListPublishes(ctx context.Context, spaceId string) (lis
 	return p.repo.ListPublishes(ctx, identity, spaceId)
 }
 
-func (p *publishService) UploadTar(ctx context.Context, publishId, uploadKey string, reader io.Reader) (resultUrl string, err error) {
+func (p *publishService) SetNotFoundPage(ctx context.Context, spaceId, objectId string) (err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	if err = p.repo.SetNotFoundPage(ctx, domain.Object{Identity: identity, SpaceId: spaceId, ObjectId: objectId}); err != nil {
+		return
+	}
+	p.invalidateNotFoundPages(identity)
+	return
+}
+
+func (p *publishService) ListRedirects(ctx context.Context, spaceId, objectId string) (redirects []domain.Redirect, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.repo.ListRedirects(ctx, domain.Object{Identity: identity, SpaceId: spaceId, ObjectId: objectId})
+}
+
+func (p *publishService) DeleteRedirect(ctx context.Context, uri string) (err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	if err = p.repo.DeleteRedirect(ctx, identity, uri); err != nil {
+		return
+	}
+	p.invalidateCache(identity, uri)
+	return
+}
+
+func (p *publishService) CreateShareLink(ctx context.Context, spaceId, objectId string, ttl time.Duration) (link string, expire time.Time, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	if p.gatewayConfig.ShareLinkKey == "" {
+		return "", time.Time{}, errors.New("share links are disabled")
+	}
+	if ttl <= 0 {
+		ttl = defaultShareLinkTTL
+	}
+	ttl = min(ttl, sharelink.MaxTTL)
+	obj, err := p.repo.EnsureShareNonce(ctx, domain.Object{Identity: identity, SpaceId: spaceId, ObjectId: objectId})
+	if err != nil {
+		return
+	}
+	expire = time.Now().Add(ttl).Truncate(time.Second)
+	link = (&url.URL{
+		Scheme:   "https",
+		Host:     p.gatewayConfig.Domain,
+		Path:     "/" + obj.Identity + "/" + obj.Uri,
+		RawQuery: sharelink.Query([]byte(p.gatewayConfig.ShareLinkKey), obj, expire).Encode(),
+	}).String()
+	return link, expire, nil
+}
+
+func (p *publishService) RevokeShareLinks(ctx context.Context, spaceId, objectId string) (err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.repo.RotateShareNonce(ctx, domain.Object{Identity: identity, SpaceId: spaceId, ObjectId: objectId})
+}
+
+func (p *publishService) GetPublishStats(ctx context.Context, spaceId, objectId string, from, to time.Time) (days []domain.PageStats, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	obj, err := p.repo.ObjectPublishStatus(ctx, domain.Object{Identity: identity, SpaceId: spaceId, ObjectId: objectId})
+	if err != nil {
+		return
+	}
+	return p.stats.Get(ctx, identity, obj.Uri, from, to)
+}
+
+// SetAnalytics saves the analytics config of the identity, the empty provider removes it
+func (p *publishService) SetAnalytics(ctx context.Context, config domain.AnalyticsConfig) (err error) {
+	if config.Identity, err = p.checkIdentity(ctx); err != nil {
+		return
+	}
+	if config.Provider == "" {
+		return p.analytics.Delete(ctx, config.Identity)
+	}
+	return p.analytics.Set(ctx, config)
+}
+
+func (p *publishService) GetAnalytics(ctx context.Context) (config domain.AnalyticsConfig, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.analytics.Get(ctx, identity)
+}
+
+func (p *publishService) AddDomain(ctx context.Context, host string) (customDomain domain.CustomDomain, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.customDomain.Add(ctx, identity, host)
+}
+
+func (p *publishService) VerifyDomain(ctx context.Context, host string) (customDomain domain.CustomDomain, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.customDomain.Verify(ctx, identity, host)
+}
+
+func (p *publishService) RemoveDomain(ctx context.Context, host string) (err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.customDomain.Remove(ctx, identity, host)
+}
+
+func (p *publishService) ListDomains(ctx context.Context) (customDomains []domain.CustomDomain, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	return p.customDomain.List(ctx, identity)
+}
+
+// UploadArchive puts the files of the tar or zip archive to the store and finalizes the publish,
+// the signed upload token is used once and limits the size of the files
+func (p *publishService) UploadArchive(ctx context.Context, publishId, token string, format archiveFormat, reader io.Reader) (resultUrl string, err error) {
+	uploadToken, err := uploadtoken.Verify([]byte(p.config.UploadTokenKey), publishId, token, time.Now())
+	if err != nil {
+		return
+	}
 	id, err := primitive.ObjectIDFromHex(publishId)
 	if err != nil {
 		return
 	}
+	objWithPub, err := p.claimUpload(ctx, id, uploadToken.Nonce)
+	if err != nil {
+		return
+	}
+	archive, err := newArchiveReader(format, reader, int(uploadToken.Limit))
+	if err != nil {
+		return
+	}
+	return p.upload(ctx, objWithPub, archive, int(uploadToken.Limit))
+}
+
+// UploadFiles puts the files streamed by the publish owner to the store and finalizes the publish,
+// the stream is accepted within the lifetime of the upload url of the publish
+func (p *publishService) UploadFiles(ctx context.Context, publishId string, archive archiveReader) (resultUrl string, err error) {
+	identity, err := p.checkIdentity(ctx)
+	if err != nil {
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(publishId)
+	if err != nil {
+		return "", publishapi.ErrNotFound
+	}
+	if time.Since(id.Timestamp()) > uploadUrlTTL {
+		return "", uploadtoken.ErrExpired
+	}
 	objWithPub, err := p.repo.GetPublish(ctx, id)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = publishapi.ErrNotFound
+		}
 		return
 	}
-	publish := objWithPub.Publish
-	if publish.UploadKey != uploadKey {
-		return "", errors.New("invalid upload key")
+	if objWithPub.Identity != identity {
+		return "", publishapi.ErrAccessDenied
+	}
+	if objWithPub, err = p.claimUpload(ctx, id, ""); err != nil {
+		return
+	}
+	limit, _, err := p.GetLimit(ctx, identity)
+	if err != nil {
+		return
+	}
+	return p.upload(ctx, objWithPub, archive, limit)
+}
+
+// claimUpload takes the created publish for the single upload, the empty upload key skips the key check
+func (p *publishService) claimUpload(ctx context.Context, id primitive.ObjectID, uploadKey string) (objWithPub domain.ObjectWithPublish, err error) {
+	if objWithPub, err = p.repo.ClaimUpload(ctx, id, uploadKey); err != nil {
+		return
 	}
-	if publish.Status != domain.PublishStatusCreated {
-		return "", errors.New("publish is not in created state")
+	if p.banList.IsBanned(domain.BanKindIdentity, objWithPub.Identity) || p.banList.IsBanned(domain.BanKindPublish, id.Hex()) {
+		return objWithPub, publishapi.ErrAccessDenied
 	}
+	return
+}
+
+// upload puts the archive files to the store and finalizes the publish, the uploaded files are removed on errors before the finalization
+func (p *publishService) upload(ctx context.Context, objWithPub domain.ObjectWithPublish, archive archiveReader, limit int) (resultUrl string, err error) {
+	publish := objWithPub.Publish
 	defer func() {
+		_ = archive.Close()
 		if err != nil {
-			_ = p.store.DeletePath(context.Background(), publishId)
+			_ = p.store.DeletePath(context.Background(), publish.Id.Hex())
 		}
 	}()
-	var size int
-
-	limit, err := p.getLimitByIdentity(ctx, objWithPub.Identity)
-	if err != nil {
-		return
-	}
-	if size, err = p.uploadTar(ctx, publishId, reader, limit); err != nil {
+	if err = p.uploadArchive(ctx, publish, archive, limit); err != nil {
 		return
 	}
 	// TODO: validate here
-	publish.Size = int64(size)
-	publish.Status = domain.PublishStatusPublished
 	publish.UploadKey = ""
+	if publish.PreviewKey != "" {
+		publish.Status = domain.PublishStatusPreview
+		publish.PreviewExpire = time.Now().Add(previewTTL).Unix()
+		if err = p.repo.FinalizePublish(ctx, objWithPub); err != nil {
+			return
+		}
+		return p.previewUrl(publish), nil
+	}
+	publish.Status = domain.PublishStatusPublished
+	if err = p.finalizePublish(ctx, objWithPub); err != nil {
+		return
+	}
+	return url.JoinPath("https://", p.gatewayConfig.Domain, objWithPub.Identity, objWithPub.Uri)
+}
+
+// finalizePublish makes the publish the live version of the object, nothing fails after the publish is live
+func (p *publishService) finalizePublish(ctx context.Context, objWithPub domain.ObjectWithPublish) (err error) {
 	if err = p.repo.FinalizePublish(ctx, objWithPub); err != nil {
 		return
 	}
 	p.invalidateCache(objWithPub.Identity, objWithPub.Uri)
-	return url.JoinPath("https://", p.gatewayConfig.Domain, publish.ObjectId)
+	if objWithPub.NotFoundPage || objWithPub.Publish.NotFoundHtml {
+		// the object may be the 404 page of the identity
+		p.invalidateNotFoundPages(objWithPub.Identity)
+	}
+	return
 }
 
-func (p *publishService) uploadTar(ctx context.Context, publishId string, reader io.Reader, limit int) (size int, err error) {
-	tarReader := tar.NewReader(reader)
-	var header *tar.Header
+// previewUrl returns the secret gateway url of the preview publish
+func (p *publishService) previewUrl(publish *domain.Publish) string {
+	return (&url.URL{
+		Scheme: "https",
+		Host:   p.gatewayConfig.Domain,
+		Path:   "/preview/" + publish.Id.Hex() + "/" + publish.PreviewKey + "/",
+	}).String()
+}
+
+// uploadArchive puts the archive files to the store and fills the publish size, 404.html flag and rules
+func (p *publishService) uploadArchive(ctx context.Context, publish *domain.Publish, archive archiveReader, limit int) (err error) {
+	var (
+		name       string
+		fileSize   int64
+		fileReader io.Reader
+		size       int
+	)
 	for {
-		if header, err = tarReader.Next(); errors.Is(err, io.EOF) {
+		if name, fileSize, fileReader, err = archive.Next(); errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
 			return
 		}
-		if header.FileInfo().IsDir() {
+		size += int(fileSize)
+		if size > limit {
+			return ErrUploadLimitExceeded
+		}
+		switch name {
+		case NotFoundHtmlName:
+			publish.NotFoundHtml = true
+		case publishrules.RedirectsFileName:
+			if publish.Rules == nil {
+				publish.Rules = &domain.PublishRules{}
+			}
+			if publish.Rules.Redirects, err = publishrules.ParseRedirects(fileReader); err != nil {
+				return
+			}
+			continue
+		case publishrules.HeadersFileName:
+			if publish.Rules == nil {
+				publish.Rules = &domain.PublishRules{}
+			}
+			if publish.Rules.Headers, err = publishrules.ParseHeaders(fileReader); err != nil {
+				return
+			}
 			continue
 		}
 		fileName := strings.Join([]string{
-			publishId,
-			strings.TrimPrefix(header.Name, "/"),
+			publish.Id.Hex(),
+			name,
 		}, "/")
 		file := store.File{
 			Name:   fileName,
-			Size:   int(header.Size),
-			Reader: bufio.NewReader(tarReader),
+			Size:   int(fileSize),
+			Reader: bufio.NewReader(fileReader),
 		}
 		if err = p.store.Put(ctx, file); err != nil {
 			return
 		}
-		size += int(header.Size)
-		if size > limit {
-			return 0, errors.New("upload limit exceeded")
-		}
 	}
-	return size, nil
+	publish.Size = int64(size)
+	return nil
 }
 
-func (p *publishService) getLimitByIdentity(ctx context.Context, identity string) (limit int, err error) {
+// GetLimit returns the upload limit of the identity, the operator override takes precedence over the name based limit
+func (p *publishService) GetLimit(ctx context.Context, identity string) (limit int, overridden bool, err error) {
+	override, err := p.repo.GetLimitOverride(ctx, identity)
+	if err == nil {
+		return int(override), true, nil
+	} else if !errors.Is(err, publishapi.ErrNotFound) {
+		return
+	}
+	limit, err = p.getLimitByName(ctx, identity)
+	return
+}
+
+func (p *publishService) getLimitByName(ctx context.Context, identity string) (limit int, err error) {
 	var name string
 	name, err = p.nameService.ResolveIdentity(ctx, identity)
 	if errors.Is(err, ocache.ErrNotExists) {
@@ -254,33 +694,67 @@ func (p *publishService) getLimitByIdentity(ctx context.Context, identity string
 }
 
 func (p *publishService) Cleanup(ctx context.Context) error {
+	_, err := p.RunCleanup(ctx, false)
+	return err
+}
+
+// RunCleanup deletes outdated documents and files of deleted publishes, the dry run only counts them
+func (p *publishService) RunCleanup(ctx context.Context, dryRun bool) (report domain.CleanupReport, err error) {
 	before := time.Now().Add(-time.Hour)
+	uploadsBefore := time.Now().Add(-staleUploadAfter)
+	versionsBefore := time.Now().AddDate(0, 0, -p.config.VersionRetentionDays)
+	if dryRun {
+		return p.repo.CountCleanup(ctx, before, uploadsBefore, versionsBefore)
+	}
 	st := time.Now()
-	deletedCount, err := p.repo.DeleteOutdatedPublishes(ctx, before)
+	report.OutdatedPublishes, err = p.repo.DeleteOutdatedPublishes(ctx, before)
 	if err != nil {
 		log.Warn("delete outdated publishes", zap.Error(err))
 	} else {
-		log.Info("deleted outdated publishes", zap.Int("count", deletedCount), zap.Duration("dur", time.Since(st)))
+		log.Info("deleted outdated publishes", zap.Int("count", report.OutdatedPublishes), zap.Duration("dur", time.Since(st)))
+	}
+
+	st = time.Now()
+	report.StaleUploads, err = p.repo.DeleteStaleUploads(ctx, uploadsBefore)
+	if err != nil {
+		log.Warn("delete stale uploads", zap.Error(err))
+	} else {
+		log.Info("deleted stale uploads", zap.Int("count", report.StaleUploads), zap.Duration("dur", time.Since(st)))
 	}
 
 	st = time.Now()
-	deletedCount, err = p.repo.DeleteOutdatedObjects(ctx, before)
+	report.OutdatedObjects, err = p.repo.DeleteOutdatedObjects(ctx, before)
 	if err != nil {
 		log.Warn("delete outdated objects", zap.Error(err))
 	} else {
-		log.Info("deleted outdated objects", zap.Int("count", deletedCount), zap.Duration("dur", time.Since(st)))
+		log.Info("deleted outdated objects", zap.Int("count", report.OutdatedObjects), zap.Duration("dur", time.Since(st)))
+	}
+
+	st = time.Now()
+	report.OutdatedVersions, err = p.repo.DeleteOutdatedVersions(ctx, versionsBefore)
+	if err != nil {
+		log.Warn("delete outdated versions", zap.Error(err))
+	} else {
+		log.Info("deleted outdated versions", zap.Int("count", report.OutdatedVersions), zap.Duration("dur", time.Since(st)))
+	}
+
+	st = time.Now()
+	report.ExpiredPreviews, err = p.repo.DeleteExpiredPreviews(ctx, time.Now())
+	if err != nil {
+		log.Warn("delete expired previews", zap.Error(err))
+	} else {
+		log.Info("deleted expired previews", zap.Int("count", report.ExpiredPreviews), zap.Duration("dur", time.Since(st)))
 	}
 
 	st = time.Now()
-	var deletedPublishes int
 	err = p.repo.IterateReadyToDeleteIds(ctx, func(id primitive.ObjectID) error {
 		if delErr := p.store.DeletePath(ctx, id.Hex()); delErr != nil {
-			log.Warn("can't delete s3 path", zap.Error(err), zap.String("path", id.Hex()))
+			log.Warn("can't delete s3 path", zap.Error(delErr), zap.String("path", id.Hex()))
 		} else {
 			if delErr = p.repo.DeletePublish(ctx, id); delErr != nil {
-				log.Warn("can't delete publish by id", zap.Error(err), zap.String("id", id.Hex()))
+				log.Warn("can't delete publish by id", zap.Error(delErr), zap.String("id", id.Hex()))
 			} else {
-				deletedPublishes++
+				report.DeletedPublishes++
 			}
 		}
 		return nil
@@ -288,9 +762,10 @@ func (p *publishService) Cleanup(ctx context.Context) error {
 	if err != nil {
 		log.Warn("iterate unpublished publishes", zap.Error(err))
 	} else {
-		log.Info("deleted unpublished publishes", zap.Int("count", deletedPublishes), zap.Duration("dur", time.Since(st)))
+		log.Info("deleted unpublished publishes", zap.Int("count", report.DeletedPublishes), zap.Duration("dur", time.Since(st)))
 	}
-	return nil
+	// errors of the single steps are logged, the next run retries them
+	return report, nil
 }
 
 func (p *publishService) checkIdentity(ctx context.Context) (identity string, err error) {
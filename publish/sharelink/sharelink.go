@@ -0,0 +1,71 @@
+// Package sharelink signs and verifies expiring share links of the published objects.
+//
+// The signature covers the object ids, the expiration time and the per-object nonce,
+// so rotating the nonce revokes all links of the object.
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+const (
+	ExpParam = "exp"
+	SigParam = "sig"
+
+	// MaxTTL is the max lifetime of the share link
+	MaxTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrExpired          = errors.New("share link expired")
+	ErrInvalidSignature = errors.New("invalid share link signature")
+)
+
+// Query returns the query params of the share link
+func Query(key []byte, object domain.Object, expire time.Time) url.Values {
+	exp := expire.Unix()
+	return url.Values{
+		ExpParam: {strconv.FormatInt(exp, 10)},
+		SigParam: {sign(key, object, exp)},
+	}
+}
+
+// IsSigned checks whether the query has the share link signature
+func IsSigned(query url.Values) bool {
+	return query.Has(SigParam)
+}
+
+// Verify checks the signature and the expiration of the share link query
+func Verify(key []byte, object domain.Object, query url.Values, now time.Time) error {
+	if len(key) == 0 || object.ShareNonce == "" {
+		return ErrInvalidSignature
+	}
+	exp, err := strconv.ParseInt(query.Get(ExpParam), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(query.Get(SigParam)), []byte(sign(key, object, exp))) {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > exp {
+		return ErrExpired
+	}
+	return nil
+}
+
+func sign(key []byte, object domain.Object, exp int64) string {
+	mac := hmac.New(sha256.New, key)
+	for _, part := range []string{object.Identity, object.SpaceId, object.ObjectId, object.ShareNonce, strconv.FormatInt(exp, 10)} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package sharelink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/domain"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("key")
+	obj := domain.Object{Identity: "a1", SpaceId: "s1", ObjectId: "o1", ShareNonce: "n1"}
+	now := time.Now()
+	query := Query(key, obj, now.Add(time.Hour))
+	require.True(t, IsSigned(query))
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, Verify(key, obj, query, now))
+	})
+	t.Run("expired", func(t *testing.T) {
+		require.ErrorIs(t, Verify(key, obj, query, now.Add(2*time.Hour)), ErrExpired)
+	})
+	t.Run("rotated nonce", func(t *testing.T) {
+		rotated := obj
+		rotated.ShareNonce = "n2"
+		require.ErrorIs(t, Verify(key, rotated, query, now), ErrInvalidSignature)
+	})
+	t.Run("other object", func(t *testing.T) {
+		other := obj
+		other.ObjectId = "o2"
+		require.ErrorIs(t, Verify(key, other, query, now), ErrInvalidSignature)
+	})
+	t.Run("changed expiration", func(t *testing.T) {
+		changed := Query(key, obj, now.Add(time.Hour))
+		changed.Set(ExpParam, "9999999999")
+		require.ErrorIs(t, Verify(key, obj, changed, now), ErrInvalidSignature)
+	})
+	t.Run("other key", func(t *testing.T) {
+		require.ErrorIs(t, Verify([]byte("other"), obj, query, now), ErrInvalidSignature)
+	})
+	t.Run("not signed", func(t *testing.T) {
+		assert.False(t, IsSigned(nil))
+		require.ErrorIs(t, Verify(key, obj, nil, now), ErrInvalidSignature)
+	})
+}
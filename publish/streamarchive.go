@@ -0,0 +1,101 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+var errInvalidStream = errors.New("invalid upload stream")
+
+// uploadStream receives the messages of the UploadFiles stream
+type uploadStream interface {
+	Recv() (*publishapi.UploadFilesRequest, error)
+}
+
+// streamArchive reads the files of the UploadFiles stream, every file is the header message followed by the chunks
+type streamArchive struct {
+	stream uploadStream
+	// pending is the received message not consumed yet
+	pending *publishapi.UploadFilesRequest
+	current *streamFile
+}
+
+// newStreamArchive makes the archive of the stream, first is the received message with the publishId
+func newStreamArchive(stream uploadStream, first *publishapi.UploadFilesRequest) *streamArchive {
+	s := &streamArchive{stream: stream}
+	if first.Header != nil || len(first.Chunk) != 0 {
+		s.pending = first
+	}
+	return s
+}
+
+func (s *streamArchive) recv() (msg *publishapi.UploadFilesRequest, err error) {
+	if s.pending != nil {
+		msg, s.pending = s.pending, nil
+		return
+	}
+	return s.stream.Recv()
+}
+
+func (s *streamArchive) Next() (name string, size int64, reader io.Reader, err error) {
+	if s.current != nil {
+		// the rest of the skipped file must be read to get the next header
+		if _, err = io.Copy(io.Discard, s.current); err != nil {
+			return
+		}
+		s.current = nil
+	}
+	msg, err := s.recv()
+	if err != nil {
+		return
+	}
+	if msg.Header == nil {
+		return "", 0, nil, fmt.Errorf("%w: expected file header", errInvalidStream)
+	}
+	if name, err = archivePath(msg.Header.Name); err != nil {
+		return
+	}
+	if msg.Header.Size < 0 {
+		return "", 0, nil, fmt.Errorf("%w: negative size of %q", errInvalidStream, name)
+	}
+	s.current = &streamFile{s: s, name: name, left: msg.Header.Size, chunk: msg.Chunk}
+	return name, msg.Header.Size, s.current, nil
+}
+
+func (s *streamArchive) Close() error {
+	return nil
+}
+
+// streamFile reads the chunks of the file until the declared size
+type streamFile struct {
+	s     *streamArchive
+	name  string
+	left  int64
+	chunk []byte
+}
+
+func (f *streamFile) Read(p []byte) (n int, err error) {
+	for len(f.chunk) == 0 {
+		if f.left == 0 {
+			return 0, io.EOF
+		}
+		msg, err := f.s.recv()
+		if errors.Is(err, io.EOF) || (err == nil && msg.Header != nil) {
+			return 0, fmt.Errorf("%w: %q is shorter than its size", errInvalidStream, f.name)
+		}
+		if err != nil {
+			return 0, err
+		}
+		f.chunk = msg.Chunk
+	}
+	if int64(len(f.chunk)) > f.left {
+		return 0, fmt.Errorf("%w: %q is larger than its size", errInvalidStream, f.name)
+	}
+	n = copy(p, f.chunk)
+	f.chunk = f.chunk[n:]
+	f.left -= int64(n)
+	return n, nil
+}
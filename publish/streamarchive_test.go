@@ -0,0 +1,124 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/anyproto/any-sync/net/rpc/rpcerr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/publish/publishrepo"
+	"github.com/anyproto/anytype-publish-server/publish/uploadtoken"
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+type testUploadStream []*publishapi.UploadFilesRequest
+
+func (s *testUploadStream) Recv() (msg *publishapi.UploadFilesRequest, err error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	msg, *s = (*s)[0], (*s)[1:]
+	return
+}
+
+func header(name string, size int64) *publishapi.UploadFileHeader {
+	return &publishapi.UploadFileHeader{Name: name, Size: size}
+}
+
+func TestStreamArchive(t *testing.T) {
+	newArchive := func(msgs ...*publishapi.UploadFilesRequest) *streamArchive {
+		stream := testUploadStream(msgs[1:])
+		return newStreamArchive(&stream, msgs[0])
+	}
+	t.Run("files", func(t *testing.T) {
+		archive := newArchive(
+			&publishapi.UploadFilesRequest{PublishId: "id", Header: header("index.html", 5), Chunk: []byte("ind")},
+			&publishapi.UploadFilesRequest{Chunk: []byte("ex")},
+			&publishapi.UploadFilesRequest{Header: header("empty.txt", 0)},
+			&publishapi.UploadFilesRequest{Header: header("sub/page.html", 4)},
+			&publishapi.UploadFilesRequest{Chunk: []byte("page")},
+		)
+		assert.Equal(t, map[string]string{
+			"index.html":    "index",
+			"empty.txt":     "",
+			"sub/page.html": "page",
+		}, readFiles(t, archive))
+	})
+	t.Run("skipped file", func(t *testing.T) {
+		archive := newArchive(
+			&publishapi.UploadFilesRequest{PublishId: "id"},
+			&publishapi.UploadFilesRequest{Header: header("skipped.html", 3), Chunk: []byte("a")},
+			&publishapi.UploadFilesRequest{Chunk: []byte("bc")},
+			&publishapi.UploadFilesRequest{Header: header("index.html", 5), Chunk: []byte("index")},
+		)
+		name, _, _, err := archive.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "skipped.html", name)
+		name, _, reader, err := archive.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "index.html", name)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "index", string(data))
+		_, _, _, err = archive.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, msgs := range map[string][]*publishapi.UploadFilesRequest{
+			"no header": {
+				{PublishId: "id"},
+				{Chunk: []byte("data")},
+			},
+			"invalid path": {
+				{PublishId: "id", Header: header("../index.html", 1), Chunk: []byte("a")},
+			},
+			"larger": {
+				{PublishId: "id", Header: header("index.html", 1), Chunk: []byte("ab")},
+			},
+			"shorter": {
+				{PublishId: "id", Header: header("index.html", 3), Chunk: []byte("ab")},
+				{Header: header("page.html", 1), Chunk: []byte("a")},
+			},
+			"truncated": {
+				{PublishId: "id", Header: header("index.html", 3), Chunk: []byte("ab")},
+			},
+		} {
+			archive := newArchive(msgs...)
+			var err error
+			for err == nil {
+				var reader io.Reader
+				if _, _, reader, err = archive.Next(); err == nil {
+					_, err = io.ReadAll(reader)
+				}
+			}
+			assert.NotErrorIs(t, err, io.EOF, name)
+			if name == "invalid path" {
+				assert.ErrorIs(t, err, errInvalidArchivePath, name)
+			} else {
+				assert.ErrorIs(t, err, errInvalidStream, name)
+			}
+		}
+	})
+}
+
+func TestUploadFilesError(t *testing.T) {
+	for _, c := range []struct {
+		err      error
+		expected error
+	}{
+		{fmt.Errorf("%w: %q is shorter than its size", errInvalidStream, "index.html"), publishapi.ErrInvalidUpload},
+		{ErrUploadLimitExceeded, publishapi.ErrUploadLimitExceeded},
+		{publishapi.ErrAccessDenied, publishapi.ErrAccessDenied},
+		{publishrepo.ErrUploadUsed, publishapi.ErrUploadUsed},
+		{uploadtoken.ErrExpired, publishapi.ErrUploadExpired},
+		{errors.New("store error"), publishapi.ErrUnexpected},
+	} {
+		err := uploadFilesError(c.err)
+		assert.Equal(t, rpcerr.Code(c.expected), rpcerr.Code(err), c.err.Error())
+		assert.Contains(t, err.Error(), c.err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+// Package uploadtoken signs and verifies the upload tokens of the publish upload urls.
+//
+// The token carries the publish id, the upload size limit, the expiration time and the nonce
+// stored in the publish, the nonce lets the repo enforce the single use of the token.
+package uploadtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrExpired      = errors.New("upload url expired")
+	ErrInvalidToken = errors.New("invalid upload token")
+)
+
+// Token is the payload of the signed upload token
+type Token struct {
+	PublishId string
+	// Limit is the max uncompressed size of the uploaded files
+	Limit int64
+	// Expire is the unix time after which the token is rejected
+	Expire int64
+	Nonce  string
+}
+
+// Sign returns the token string: limit, expiration, nonce and signature separated by dots
+func Sign(key []byte, token Token) string {
+	parts := []string{strconv.FormatInt(token.Limit, 10), strconv.FormatInt(token.Expire, 10), token.Nonce}
+	return strings.Join(append(parts, sign(key, token)), ".")
+}
+
+// Verify parses the token of the publish, checks the signature and the expiration
+func Verify(key []byte, publishId, tokenStr string, now time.Time) (token Token, err error) {
+	parts := strings.Split(tokenStr, ".")
+	if len(key) == 0 || len(parts) != 4 || parts[2] == "" {
+		return token, ErrInvalidToken
+	}
+	token.PublishId, token.Nonce = publishId, parts[2]
+	if token.Limit, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return token, ErrInvalidToken
+	}
+	if token.Expire, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return token, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[3]), []byte(sign(key, token))) {
+		return token, ErrInvalidToken
+	}
+	if now.Unix() > token.Expire {
+		return token, ErrExpired
+	}
+	return token, nil
+}
+
+// DeriveKey returns the token key derived from the secret, it's the fallback for deployments without the configured key
+func DeriveKey(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("publish upload token"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func sign(key []byte, token Token) string {
+	mac := hmac.New(sha256.New, key)
+	for _, part := range []string{token.PublishId, strconv.FormatInt(token.Limit, 10), strconv.FormatInt(token.Expire, 10), token.Nonce} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package uploadtoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("key")
+	now := time.Now()
+	token := Token{PublishId: "p1", Limit: 10 << 20, Expire: now.Add(time.Hour).Unix(), Nonce: "n1"}
+	signed := Sign(key, token)
+
+	t.Run("valid", func(t *testing.T) {
+		res, err := Verify(key, "p1", signed, now)
+		require.NoError(t, err)
+		assert.Equal(t, token, res)
+	})
+	t.Run("expired", func(t *testing.T) {
+		_, err := Verify(key, "p1", signed, now.Add(2*time.Hour))
+		require.ErrorIs(t, err, ErrExpired)
+	})
+	t.Run("other publish", func(t *testing.T) {
+		_, err := Verify(key, "p2", signed, now)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+	t.Run("changed limit", func(t *testing.T) {
+		parts := strings.Split(signed, ".")
+		parts[0] = "999999999"
+		_, err := Verify(key, "p1", strings.Join(parts, "."), now)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+	t.Run("other key", func(t *testing.T) {
+		_, err := Verify([]byte("other"), "p1", signed, now)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		for _, s := range []string{"", "uuid-key", "1.2.n1", "a.2.n1.sig", "1.2..sig"} {
+			_, err := Verify(key, "p1", s, now)
+			require.ErrorIs(t, err, ErrInvalidToken, s)
+		}
+	})
+}
+
+func TestDeriveKey(t *testing.T) {
+	assert.Equal(t, DeriveKey("secret"), DeriveKey("secret"))
+	assert.NotEqual(t, DeriveKey("secret"), DeriveKey("other"))
+	assert.NotEqual(t, "secret", DeriveKey("secret"))
+}
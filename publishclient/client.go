@@ -1,13 +1,10 @@
 package publishclient
 
 import (
-	"archive/tar"
 	"context"
-	"fmt"
-	"io"
-	"net/http"
+	"io/fs"
+	"iter"
 	"os"
-	"path/filepath"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/net/peerservice"
@@ -32,7 +29,29 @@ type Client interface {
 	Publish(ctx context.Context, req *publishapi.PublishRequest) (uploadUrl string, err error)
 	UnPublish(ctx context.Context, req *publishapi.UnPublishRequest) (err error)
 	ListPublishes(ctx context.Context, spaceId string) (publishes []*publishapi.Publish, err error)
-	UploadDir(ctx context.Context, uploadUrl, dir string) (err error)
+	SetNotFoundPage(ctx context.Context, req *publishapi.SetNotFoundPageRequest) (err error)
+	AddDomain(ctx context.Context, host string) (domain *publishapi.Domain, err error)
+	VerifyDomain(ctx context.Context, host string) (domain *publishapi.Domain, err error)
+	RemoveDomain(ctx context.Context, host string) (err error)
+	ListDomains(ctx context.Context) (domains []*publishapi.Domain, err error)
+	ListRedirects(ctx context.Context, req *publishapi.ListRedirectsRequest) (redirects []*publishapi.Redirect, err error)
+	DeleteRedirect(ctx context.Context, uri string) (err error)
+	CreateShareLink(ctx context.Context, req *publishapi.CreateShareLinkRequest) (resp *publishapi.CreateShareLinkResponse, err error)
+	RevokeShareLinks(ctx context.Context, req *publishapi.RevokeShareLinksRequest) (err error)
+	PromotePreview(ctx context.Context, req *publishapi.PromotePreviewRequest) (err error)
+	GetPublishStats(ctx context.Context, req *publishapi.GetPublishStatsRequest) (resp *publishapi.GetPublishStatsResponse, err error)
+	SetAnalytics(ctx context.Context, analytics *publishapi.Analytics) (err error)
+	GetAnalytics(ctx context.Context) (analytics *publishapi.Analytics, err error)
+	UploadDir(ctx context.Context, uploadUrl, dir string, opts ...UploadOption) (err error)
+	// UploadFS uploads the files of the file system, e.g. fstest.MapFS or embed.FS
+	UploadFS(ctx context.Context, uploadUrl string, fsys fs.FS, opts ...UploadOption) (err error)
+	// UploadEntries streams the entries into the upload, the entries are iterated again on retries
+	UploadEntries(ctx context.Context, uploadUrl string, entries iter.Seq2[UploadEntry, error], opts ...UploadOption) (err error)
+	// UploadFiles streams the files of the created publish over the rpc connection authenticated by the account,
+	// the archive is uploaded by the uploadUrl when the server doesn't support the streaming
+	UploadFiles(ctx context.Context, publishId, uploadUrl string, fsys fs.FS, opts ...UploadOption) (err error)
+	// PublishDir publishes the directory, retries failed uploads and verifies the result
+	PublishDir(ctx context.Context, opts PublishOptions) (result PublishResult, err error)
 }
 
 type publishClient struct {
@@ -87,7 +106,14 @@ func (p *publishClient) GetPublishStatus(ctx context.Context, spaceId, objectId
 }
 
 func (p *publishClient) Publish(ctx context.Context, req *publishapi.PublishRequest) (uploadUrl string, err error) {
-	var resp *publishapi.PublishResponse
+	resp, err := p.publish(ctx, req)
+	if err != nil {
+		return
+	}
+	return resp.UploadUrl, nil
+}
+
+func (p *publishClient) publish(ctx context.Context, req *publishapi.PublishRequest) (resp *publishapi.PublishResponse, err error) {
 	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
 		resp, err = c.Publish(ctx, req)
 		if err != nil {
@@ -95,10 +121,7 @@ func (p *publishClient) Publish(ctx context.Context, req *publishapi.PublishRequ
 		}
 		return
 	})
-	if err != nil {
-		return
-	}
-	return resp.UploadUrl, nil
+	return
 }
 
 func (p *publishClient) UnPublish(ctx context.Context, req *publishapi.UnPublishRequest) (err error) {
@@ -126,91 +149,175 @@ func (p *publishClient) ListPublishes(ctx context.Context, spaceId string) (publ
 	return resp.Publishes, nil
 }
 
-func (p *publishClient) UploadDir(ctx context.Context, uploadUrl, dir string) (err error) {
-	// Create a pipe for streaming the tar archive
-	pr, pw := io.Pipe()
-
-	// Start a goroutine for packing files into the tar archive
-	go func() {
-		tw := tar.NewWriter(pw)
-		defer func() {
-			_ = tw.Close()
-			_ = pw.Close()
-		}()
-
-		// Walk through the directory and add files to the tar archive
-		err = filepath.Walk(dir, func(file string, info os.FileInfo, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
-
-			// Check if the context is cancelled
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
-
-			// Create a header for the tar archive
-			header, err := tar.FileInfoHeader(info, info.Name())
-			if err != nil {
-				return err
-			}
-
-			// Set the correct relative file name
-			relPath, err := filepath.Rel(dir, file)
-			if err != nil {
-				return err
-			}
-			relPath = filepath.ToSlash(relPath)
-			header.Name = relPath
-
-			// Write the header
-			if err := tw.WriteHeader(header); err != nil {
-				return err
-			}
-
-			// If it's a file, write its contents to the tar archive
-			if !info.IsDir() {
-				f, err := os.Open(file)
-				if err != nil {
-					return err
-				}
-
-				if _, err := io.Copy(tw, f); err != nil {
-					_ = f.Close()
-					return err
-				}
-				_ = f.Close()
-			}
-
-			return nil
-		})
-
-		// Close the writer with the resulting error
-		_ = pw.CloseWithError(err)
-	}()
-
-	// Send the tar archive to the server as a POST request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadUrl, pr)
+func (p *publishClient) SetNotFoundPage(ctx context.Context, req *publishapi.SetNotFoundPageRequest) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.SetNotFoundPage(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
+
+func (p *publishClient) AddDomain(ctx context.Context, host string) (domain *publishapi.Domain, err error) {
+	var resp *publishapi.AddDomainResponse
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.AddDomain(ctx, &publishapi.AddDomainRequest{Host: host})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
 	if err != nil {
-		return err
+		return
+	}
+	return resp.Domain, nil
+}
+
+func (p *publishClient) VerifyDomain(ctx context.Context, host string) (domain *publishapi.Domain, err error) {
+	var resp *publishapi.VerifyDomainResponse
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.VerifyDomain(ctx, &publishapi.VerifyDomainRequest{Host: host})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+	if err != nil {
+		return
 	}
-	req.Header.Set("Content-Type", "application/x-tar")
+	return resp.Domain, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (p *publishClient) RemoveDomain(ctx context.Context, host string) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.RemoveDomain(ctx, &publishapi.RemoveDomainRequest{Host: host})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
+
+func (p *publishClient) ListDomains(ctx context.Context) (domains []*publishapi.Domain, err error) {
+	var resp *publishapi.ListDomainsResponse
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.ListDomains(ctx, &publishapi.ListDomainsRequest{})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
 	if err != nil {
-		return err
+		return
+	}
+	return resp.Domains, nil
+}
+
+func (p *publishClient) ListRedirects(ctx context.Context, req *publishapi.ListRedirectsRequest) (redirects []*publishapi.Redirect, err error) {
+	var resp *publishapi.ListRedirectsResponse
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.ListRedirects(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+	if err != nil {
+		return
 	}
-	defer resp.Body.Close()
+	return resp.Redirects, nil
+}
+
+func (p *publishClient) DeleteRedirect(ctx context.Context, uri string) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.DeleteRedirect(ctx, &publishapi.DeleteRedirectRequest{Uri: uri})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
+
+func (p *publishClient) CreateShareLink(ctx context.Context, req *publishapi.CreateShareLinkRequest) (resp *publishapi.CreateShareLinkResponse, err error) {
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.CreateShareLink(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+	return
+}
+
+func (p *publishClient) RevokeShareLinks(ctx context.Context, req *publishapi.RevokeShareLinksRequest) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.RevokeShareLinks(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
+
+func (p *publishClient) PromotePreview(ctx context.Context, req *publishapi.PromotePreviewRequest) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.PromotePreview(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to upload tar archive: %s", string(body))
+func (p *publishClient) GetPublishStats(ctx context.Context, req *publishapi.GetPublishStatsRequest) (resp *publishapi.GetPublishStatsResponse, err error) {
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.GetPublishStats(ctx, req)
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+	return
+}
+
+func (p *publishClient) SetAnalytics(ctx context.Context, analytics *publishapi.Analytics) (err error) {
+	return p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		_, err = c.SetAnalytics(ctx, &publishapi.SetAnalyticsRequest{Analytics: analytics})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+}
+
+func (p *publishClient) GetAnalytics(ctx context.Context) (analytics *publishapi.Analytics, err error) {
+	var resp *publishapi.GetAnalyticsResponse
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) (err error) {
+		resp, err = c.GetAnalytics(ctx, &publishapi.GetAnalyticsRequest{})
+		if err != nil {
+			err = rpcerr.Unwrap(err)
+		}
+		return
+	})
+	if err != nil {
+		return
 	}
+	return resp.Analytics, nil
+}
+
+func (p *publishClient) UploadDir(ctx context.Context, uploadUrl, dir string, opts ...UploadOption) (err error) {
+	return p.UploadFS(ctx, uploadUrl, os.DirFS(dir), opts...)
+}
 
-	return nil
+func (p *publishClient) UploadFS(ctx context.Context, uploadUrl string, fsys fs.FS, opts ...UploadOption) (err error) {
+	_, err = p.upload(ctx, uploadUrl, fsSource{fsys: fsys}, opts...)
+	return
+}
+
+func (p *publishClient) UploadEntries(ctx context.Context, uploadUrl string, entries iter.Seq2[UploadEntry, error], opts ...UploadOption) (err error) {
+	_, err = p.upload(ctx, uploadUrl, entriesSource{entries: entries}, opts...)
+	return
 }
 
 func (p *publishClient) doClient(ctx context.Context, do func(c publishapi.DRPCWebPublisherClient) error) error {
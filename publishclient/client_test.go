@@ -3,6 +3,7 @@ package publishclient
 import (
 	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
@@ -10,7 +11,10 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,8 +30,11 @@ func TestUploadDir(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			assert.Equal(t, http.MethodPost, r.Method)
 			assert.Equal(t, "application/x-tar", r.Header.Get("Content-Type"))
+			assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
 
-			tr := tar.NewReader(r.Body)
+			body, err := uploadBody(r)
+			require.NoError(t, err)
+			tr := tar.NewReader(body)
 			var files []string
 			var fileContent []byte
 			for {
@@ -88,4 +95,133 @@ func TestUploadDir(t *testing.T) {
 		err := client.UploadDir(ctx, server.URL, dir)
 		assert.Error(t, err)
 	})
+	t.Run("progress", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file1.txt"), []byte("content1"), 0644))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file2.txt"), []byte("content22"), 0644))
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		var last UploadProgress
+		var calls int
+		err := New().UploadDir(context.Background(), server.URL, dir, WithProgress(func(p UploadProgress) {
+			assert.GreaterOrEqual(t, p.Bytes, last.Bytes)
+			last = p
+			calls++
+		}))
+		require.NoError(t, err)
+		assert.Greater(t, calls, 0)
+		assert.Equal(t, UploadProgress{Files: 2, TotalFiles: 2, Bytes: 17, TotalBytes: 17, Attempt: 1}, last)
+	})
+}
+
+// uploadBody returns the tar stream of the upload request
+func uploadBody(r *http.Request) (io.Reader, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return r.Body, nil
+	}
+	return gzip.NewReader(r.Body)
+}
+
+func TestUploadDirOptions(t *testing.T) {
+	newDir := func(t *testing.T) string {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file1.txt"), []byte("content1"), 0644))
+		return dir
+	}
+	retry := WithRetry(RetryPolicy{Attempts: 3, Delay: time.Millisecond})
+
+	t.Run("retry on server error", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			if calls.Add(1) < 3 {
+				http.Error(w, `{"error":"internal"}`, http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		require.NoError(t, New().UploadDir(context.Background(), server.URL, newDir(t), retry))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+	t.Run("limit exceeded is not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(`{"error":"upload limit exceeded"}`))
+		}))
+		defer server.Close()
+
+		err := New().UploadDir(context.Background(), server.URL, newDir(t), retry)
+		assert.ErrorIs(t, err, ErrUploadLimitExceeded)
+		var uploadErr *UploadError
+		require.ErrorAs(t, err, &uploadErr)
+		assert.Equal(t, "upload limit exceeded", uploadErr.Message)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+	t.Run("transport error", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		err := New().UploadDir(context.Background(), server.URL, newDir(t), retry)
+		var transportErr *TransportError
+		assert.ErrorAs(t, err, &transportErr)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+		}))
+		defer server.Close()
+
+		err := New().UploadDir(context.Background(), server.URL, newDir(t), WithTimeout(10*time.Millisecond))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("without compression", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get("Content-Encoding"))
+			header, err := tar.NewReader(r.Body).Next()
+			require.NoError(t, err)
+			assert.Equal(t, "file1.txt", header.Name)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		require.NoError(t, New().UploadDir(context.Background(), server.URL, newDir(t), WithCompression(CompressionNone)))
+	})
+	t.Run("custom http client", func(t *testing.T) {
+		var used bool
+		client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			used = true
+			_, _ = io.Copy(io.Discard, r.Body)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+		})}
+		require.NoError(t, New().UploadDir(context.Background(), "http://upload.test", newDir(t), WithHTTPClient(client)))
+		assert.True(t, used)
+	})
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	r := RetryPolicy{Delay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, r.delay(1))
+	assert.Equal(t, 2*time.Second, r.delay(2))
+	assert.Equal(t, 4*time.Second, r.delay(3))
+	assert.Equal(t, 5*time.Second, r.delay(4))
+	assert.Equal(t, 5*time.Second, r.delay(10))
 }
@@ -0,0 +1,625 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: publishclient/publishapi/protos/admin.proto
+
+package publishapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AdminListPublishesRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Identity string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	// empty spaceId lists publishes of all spaces
+	SpaceId       string `protobuf:"bytes,2,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListPublishesRequest) Reset() {
+	*x = AdminListPublishesRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListPublishesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListPublishesRequest) ProtoMessage() {}
+
+func (x *AdminListPublishesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListPublishesRequest.ProtoReflect.Descriptor instead.
+func (*AdminListPublishesRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AdminListPublishesRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *AdminListPublishesRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+type AdminTakedownRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Identity string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	SpaceId  string                 `protobuf:"bytes,2,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId string                 `protobuf:"bytes,3,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	// status is the http status of the page: 451 (default) or 410
+	Status        int32  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminTakedownRequest) Reset() {
+	*x = AdminTakedownRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminTakedownRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminTakedownRequest) ProtoMessage() {}
+
+func (x *AdminTakedownRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminTakedownRequest.ProtoReflect.Descriptor instead.
+func (*AdminTakedownRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AdminTakedownRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *AdminTakedownRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *AdminTakedownRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *AdminTakedownRequest) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *AdminTakedownRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AdminRestoreRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	SpaceId       string                 `protobuf:"bytes,2,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId      string                 `protobuf:"bytes,3,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminRestoreRequest) Reset() {
+	*x = AdminRestoreRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminRestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminRestoreRequest) ProtoMessage() {}
+
+func (x *AdminRestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminRestoreRequest.ProtoReflect.Descriptor instead.
+func (*AdminRestoreRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AdminRestoreRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *AdminRestoreRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *AdminRestoreRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type AdminSetLimitRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Identity string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	// limit is the max upload size in bytes, 0 resets to the default limit of the identity
+	Limit         int64 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSetLimitRequest) Reset() {
+	*x = AdminSetLimitRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSetLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSetLimitRequest) ProtoMessage() {}
+
+func (x *AdminSetLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSetLimitRequest.ProtoReflect.Descriptor instead.
+func (*AdminSetLimitRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AdminSetLimitRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *AdminSetLimitRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AdminGetLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetLimitRequest) Reset() {
+	*x = AdminGetLimitRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetLimitRequest) ProtoMessage() {}
+
+func (x *AdminGetLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetLimitRequest.ProtoReflect.Descriptor instead.
+func (*AdminGetLimitRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AdminGetLimitRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type AdminGetLimitResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit is the effective max upload size in bytes
+	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// overridden is true when the limit is set by an operator
+	Overridden    bool `protobuf:"varint,2,opt,name=overridden,proto3" json:"overridden,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetLimitResponse) Reset() {
+	*x = AdminGetLimitResponse{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetLimitResponse) ProtoMessage() {}
+
+func (x *AdminGetLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetLimitResponse.ProtoReflect.Descriptor instead.
+func (*AdminGetLimitResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AdminGetLimitResponse) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *AdminGetLimitResponse) GetOverridden() bool {
+	if x != nil {
+		return x.Overridden
+	}
+	return false
+}
+
+type AdminCleanupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminCleanupRequest) Reset() {
+	*x = AdminCleanupRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminCleanupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminCleanupRequest) ProtoMessage() {}
+
+func (x *AdminCleanupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminCleanupRequest.ProtoReflect.Descriptor instead.
+func (*AdminCleanupRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{6}
+}
+
+type AdminGetStorageUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetStorageUsageRequest) Reset() {
+	*x = AdminGetStorageUsageRequest{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetStorageUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetStorageUsageRequest) ProtoMessage() {}
+
+func (x *AdminGetStorageUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetStorageUsageRequest.ProtoReflect.Descriptor instead.
+func (*AdminGetStorageUsageRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AdminGetStorageUsageRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type AdminGetStorageUsageResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// size is the total size in bytes of all stored publishes including archived versions
+	Size          int64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Publishes     int64 `protobuf:"varint,2,opt,name=publishes,proto3" json:"publishes,omitempty"`
+	Objects       int64 `protobuf:"varint,3,opt,name=objects,proto3" json:"objects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetStorageUsageResponse) Reset() {
+	*x = AdminGetStorageUsageResponse{}
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetStorageUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetStorageUsageResponse) ProtoMessage() {}
+
+func (x *AdminGetStorageUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetStorageUsageResponse.ProtoReflect.Descriptor instead.
+func (*AdminGetStorageUsageResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AdminGetStorageUsageResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *AdminGetStorageUsageResponse) GetPublishes() int64 {
+	if x != nil {
+		return x.Publishes
+	}
+	return 0
+}
+
+func (x *AdminGetStorageUsageResponse) GetObjects() int64 {
+	if x != nil {
+		return x.Objects
+	}
+	return 0
+}
+
+var File_publishclient_publishapi_protos_admin_proto protoreflect.FileDescriptor
+
+const file_publishclient_publishapi_protos_admin_proto_rawDesc = "" +
+	"\n" +
+	"+publishclient/publishapi/protos/admin.proto\x12\x06client\x1a/publishclient/publishapi/protos/publisher.proto\"Q\n" +
+	"\x19AdminListPublishesRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x18\n" +
+	"\aspaceId\x18\x02 \x01(\tR\aspaceId\"\x98\x01\n" +
+	"\x14AdminTakedownRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x18\n" +
+	"\aspaceId\x18\x02 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x03 \x01(\tR\bobjectId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"g\n" +
+	"\x13AdminRestoreRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x18\n" +
+	"\aspaceId\x18\x02 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x03 \x01(\tR\bobjectId\"H\n" +
+	"\x14AdminSetLimitRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"2\n" +
+	"\x14AdminGetLimitRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\"M\n" +
+	"\x15AdminGetLimitResponse\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x1e\n" +
+	"\n" +
+	"overridden\x18\x02 \x01(\bR\n" +
+	"overridden\"\x15\n" +
+	"\x13AdminCleanupRequest\"9\n" +
+	"\x1bAdminGetStorageUsageRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\"j\n" +
+	"\x1cAdminGetStorageUsageResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x1c\n" +
+	"\tpublishes\x18\x02 \x01(\x03R\tpublishes\x12\x18\n" +
+	"\aobjects\x18\x03 \x01(\x03R\aobjects2\xff\x03\n" +
+	"\fPublishAdmin\x12V\n" +
+	"\x12AdminListPublishes\x12!.client.AdminListPublishesRequest\x1a\x1d.client.ListPublishesResponse\x129\n" +
+	"\rAdminTakedown\x12\x1c.client.AdminTakedownRequest\x1a\n" +
+	".client.Ok\x127\n" +
+	"\fAdminRestore\x12\x1b.client.AdminRestoreRequest\x1a\n" +
+	".client.Ok\x129\n" +
+	"\rAdminSetLimit\x12\x1c.client.AdminSetLimitRequest\x1a\n" +
+	".client.Ok\x12L\n" +
+	"\rAdminGetLimit\x12\x1c.client.AdminGetLimitRequest\x1a\x1d.client.AdminGetLimitResponse\x127\n" +
+	"\fAdminCleanup\x12\x1b.client.AdminCleanupRequest\x1a\n" +
+	".client.Ok\x12a\n" +
+	"\x14AdminGetStorageUsage\x12#.client.AdminGetStorageUsageRequest\x1a$.client.AdminGetStorageUsageResponseB\x1aZ\x18publishclient/publishapib\x06proto3"
+
+var (
+	file_publishclient_publishapi_protos_admin_proto_rawDescOnce sync.Once
+	file_publishclient_publishapi_protos_admin_proto_rawDescData []byte
+)
+
+func file_publishclient_publishapi_protos_admin_proto_rawDescGZIP() []byte {
+	file_publishclient_publishapi_protos_admin_proto_rawDescOnce.Do(func() {
+		file_publishclient_publishapi_protos_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_publishclient_publishapi_protos_admin_proto_rawDesc), len(file_publishclient_publishapi_protos_admin_proto_rawDesc)))
+	})
+	return file_publishclient_publishapi_protos_admin_proto_rawDescData
+}
+
+var file_publishclient_publishapi_protos_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_publishclient_publishapi_protos_admin_proto_goTypes = []any{
+	(*AdminListPublishesRequest)(nil),    // 0: client.AdminListPublishesRequest
+	(*AdminTakedownRequest)(nil),         // 1: client.AdminTakedownRequest
+	(*AdminRestoreRequest)(nil),          // 2: client.AdminRestoreRequest
+	(*AdminSetLimitRequest)(nil),         // 3: client.AdminSetLimitRequest
+	(*AdminGetLimitRequest)(nil),         // 4: client.AdminGetLimitRequest
+	(*AdminGetLimitResponse)(nil),        // 5: client.AdminGetLimitResponse
+	(*AdminCleanupRequest)(nil),          // 6: client.AdminCleanupRequest
+	(*AdminGetStorageUsageRequest)(nil),  // 7: client.AdminGetStorageUsageRequest
+	(*AdminGetStorageUsageResponse)(nil), // 8: client.AdminGetStorageUsageResponse
+	(*ListPublishesResponse)(nil),        // 9: client.ListPublishesResponse
+	(*Ok)(nil),                           // 10: client.Ok
+}
+var file_publishclient_publishapi_protos_admin_proto_depIdxs = []int32{
+	0,  // 0: client.PublishAdmin.AdminListPublishes:input_type -> client.AdminListPublishesRequest
+	1,  // 1: client.PublishAdmin.AdminTakedown:input_type -> client.AdminTakedownRequest
+	2,  // 2: client.PublishAdmin.AdminRestore:input_type -> client.AdminRestoreRequest
+	3,  // 3: client.PublishAdmin.AdminSetLimit:input_type -> client.AdminSetLimitRequest
+	4,  // 4: client.PublishAdmin.AdminGetLimit:input_type -> client.AdminGetLimitRequest
+	6,  // 5: client.PublishAdmin.AdminCleanup:input_type -> client.AdminCleanupRequest
+	7,  // 6: client.PublishAdmin.AdminGetStorageUsage:input_type -> client.AdminGetStorageUsageRequest
+	9,  // 7: client.PublishAdmin.AdminListPublishes:output_type -> client.ListPublishesResponse
+	10, // 8: client.PublishAdmin.AdminTakedown:output_type -> client.Ok
+	10, // 9: client.PublishAdmin.AdminRestore:output_type -> client.Ok
+	10, // 10: client.PublishAdmin.AdminSetLimit:output_type -> client.Ok
+	5,  // 11: client.PublishAdmin.AdminGetLimit:output_type -> client.AdminGetLimitResponse
+	10, // 12: client.PublishAdmin.AdminCleanup:output_type -> client.Ok
+	8,  // 13: client.PublishAdmin.AdminGetStorageUsage:output_type -> client.AdminGetStorageUsageResponse
+	7,  // [7:14] is the sub-list for method output_type
+	0,  // [0:7] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_publishclient_publishapi_protos_admin_proto_init() }
+func file_publishclient_publishapi_protos_admin_proto_init() {
+	if File_publishclient_publishapi_protos_admin_proto != nil {
+		return
+	}
+	file_publishclient_publishapi_protos_publisher_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_publishclient_publishapi_protos_admin_proto_rawDesc), len(file_publishclient_publishapi_protos_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_publishclient_publishapi_protos_admin_proto_goTypes,
+		DependencyIndexes: file_publishclient_publishapi_protos_admin_proto_depIdxs,
+		MessageInfos:      file_publishclient_publishapi_protos_admin_proto_msgTypes,
+	}.Build()
+	File_publishclient_publishapi_protos_admin_proto = out.File
+	file_publishclient_publishapi_protos_admin_proto_goTypes = nil
+	file_publishclient_publishapi_protos_admin_proto_depIdxs = nil
+}
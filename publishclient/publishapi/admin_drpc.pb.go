@@ -0,0 +1,346 @@
+// Code generated by protoc-gen-go-drpc. DO NOT EDIT.
+// protoc-gen-go-drpc version: v0.0.34
+// source: publishclient/publishapi/protos/admin.proto
+
+package publishapi
+
+import (
+	context "context"
+	errors "errors"
+	drpc1 "github.com/planetscale/vtprotobuf/codec/drpc"
+	drpc "storj.io/drpc"
+	drpcerr "storj.io/drpc/drpcerr"
+)
+
+type drpcEncoding_File_publishclient_publishapi_protos_admin_proto struct{}
+
+func (drpcEncoding_File_publishclient_publishapi_protos_admin_proto) Marshal(msg drpc.Message) ([]byte, error) {
+	return drpc1.Marshal(msg)
+}
+
+func (drpcEncoding_File_publishclient_publishapi_protos_admin_proto) Unmarshal(buf []byte, msg drpc.Message) error {
+	return drpc1.Unmarshal(buf, msg)
+}
+
+func (drpcEncoding_File_publishclient_publishapi_protos_admin_proto) JSONMarshal(msg drpc.Message) ([]byte, error) {
+	return drpc1.JSONMarshal(msg)
+}
+
+func (drpcEncoding_File_publishclient_publishapi_protos_admin_proto) JSONUnmarshal(buf []byte, msg drpc.Message) error {
+	return drpc1.JSONUnmarshal(buf, msg)
+}
+
+type DRPCPublishAdminClient interface {
+	DRPCConn() drpc.Conn
+
+	AdminListPublishes(ctx context.Context, in *AdminListPublishesRequest) (*ListPublishesResponse, error)
+	AdminTakedown(ctx context.Context, in *AdminTakedownRequest) (*Ok, error)
+	AdminRestore(ctx context.Context, in *AdminRestoreRequest) (*Ok, error)
+	AdminSetLimit(ctx context.Context, in *AdminSetLimitRequest) (*Ok, error)
+	AdminGetLimit(ctx context.Context, in *AdminGetLimitRequest) (*AdminGetLimitResponse, error)
+	AdminCleanup(ctx context.Context, in *AdminCleanupRequest) (*Ok, error)
+	AdminGetStorageUsage(ctx context.Context, in *AdminGetStorageUsageRequest) (*AdminGetStorageUsageResponse, error)
+}
+
+type drpcPublishAdminClient struct {
+	cc drpc.Conn
+}
+
+func NewDRPCPublishAdminClient(cc drpc.Conn) DRPCPublishAdminClient {
+	return &drpcPublishAdminClient{cc}
+}
+
+func (c *drpcPublishAdminClient) DRPCConn() drpc.Conn { return c.cc }
+
+func (c *drpcPublishAdminClient) AdminListPublishes(ctx context.Context, in *AdminListPublishesRequest) (*ListPublishesResponse, error) {
+	out := new(ListPublishesResponse)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminListPublishes", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminTakedown(ctx context.Context, in *AdminTakedownRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminTakedown", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminRestore(ctx context.Context, in *AdminRestoreRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminRestore", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminSetLimit(ctx context.Context, in *AdminSetLimitRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminSetLimit", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminGetLimit(ctx context.Context, in *AdminGetLimitRequest) (*AdminGetLimitResponse, error) {
+	out := new(AdminGetLimitResponse)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminGetLimit", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminCleanup(ctx context.Context, in *AdminCleanupRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminCleanup", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcPublishAdminClient) AdminGetStorageUsage(ctx context.Context, in *AdminGetStorageUsageRequest) (*AdminGetStorageUsageResponse, error) {
+	out := new(AdminGetStorageUsageResponse)
+	err := c.cc.Invoke(ctx, "/client.PublishAdmin/AdminGetStorageUsage", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type DRPCPublishAdminServer interface {
+	AdminListPublishes(context.Context, *AdminListPublishesRequest) (*ListPublishesResponse, error)
+	AdminTakedown(context.Context, *AdminTakedownRequest) (*Ok, error)
+	AdminRestore(context.Context, *AdminRestoreRequest) (*Ok, error)
+	AdminSetLimit(context.Context, *AdminSetLimitRequest) (*Ok, error)
+	AdminGetLimit(context.Context, *AdminGetLimitRequest) (*AdminGetLimitResponse, error)
+	AdminCleanup(context.Context, *AdminCleanupRequest) (*Ok, error)
+	AdminGetStorageUsage(context.Context, *AdminGetStorageUsageRequest) (*AdminGetStorageUsageResponse, error)
+}
+
+type DRPCPublishAdminUnimplementedServer struct{}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminListPublishes(context.Context, *AdminListPublishesRequest) (*ListPublishesResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminTakedown(context.Context, *AdminTakedownRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminRestore(context.Context, *AdminRestoreRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminSetLimit(context.Context, *AdminSetLimitRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminGetLimit(context.Context, *AdminGetLimitRequest) (*AdminGetLimitResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminCleanup(context.Context, *AdminCleanupRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCPublishAdminUnimplementedServer) AdminGetStorageUsage(context.Context, *AdminGetStorageUsageRequest) (*AdminGetStorageUsageResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+type DRPCPublishAdminDescription struct{}
+
+func (DRPCPublishAdminDescription) NumMethods() int { return 7 }
+
+func (DRPCPublishAdminDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
+	switch n {
+	case 0:
+		return "/client.PublishAdmin/AdminListPublishes", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminListPublishes(
+						ctx,
+						in1.(*AdminListPublishesRequest),
+					)
+			}, DRPCPublishAdminServer.AdminListPublishes, true
+	case 1:
+		return "/client.PublishAdmin/AdminTakedown", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminTakedown(
+						ctx,
+						in1.(*AdminTakedownRequest),
+					)
+			}, DRPCPublishAdminServer.AdminTakedown, true
+	case 2:
+		return "/client.PublishAdmin/AdminRestore", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminRestore(
+						ctx,
+						in1.(*AdminRestoreRequest),
+					)
+			}, DRPCPublishAdminServer.AdminRestore, true
+	case 3:
+		return "/client.PublishAdmin/AdminSetLimit", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminSetLimit(
+						ctx,
+						in1.(*AdminSetLimitRequest),
+					)
+			}, DRPCPublishAdminServer.AdminSetLimit, true
+	case 4:
+		return "/client.PublishAdmin/AdminGetLimit", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminGetLimit(
+						ctx,
+						in1.(*AdminGetLimitRequest),
+					)
+			}, DRPCPublishAdminServer.AdminGetLimit, true
+	case 5:
+		return "/client.PublishAdmin/AdminCleanup", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminCleanup(
+						ctx,
+						in1.(*AdminCleanupRequest),
+					)
+			}, DRPCPublishAdminServer.AdminCleanup, true
+	case 6:
+		return "/client.PublishAdmin/AdminGetStorageUsage", drpcEncoding_File_publishclient_publishapi_protos_admin_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCPublishAdminServer).
+					AdminGetStorageUsage(
+						ctx,
+						in1.(*AdminGetStorageUsageRequest),
+					)
+			}, DRPCPublishAdminServer.AdminGetStorageUsage, true
+	default:
+		return "", nil, nil, nil, false
+	}
+}
+
+func DRPCRegisterPublishAdmin(mux drpc.Mux, impl DRPCPublishAdminServer) error {
+	return mux.Register(impl, DRPCPublishAdminDescription{})
+}
+
+type DRPCPublishAdmin_AdminListPublishesStream interface {
+	drpc.Stream
+	SendAndClose(*ListPublishesResponse) error
+}
+
+type drpcPublishAdmin_AdminListPublishesStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminListPublishesStream) SendAndClose(m *ListPublishesResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminTakedownStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcPublishAdmin_AdminTakedownStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminTakedownStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminRestoreStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcPublishAdmin_AdminRestoreStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminRestoreStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminSetLimitStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcPublishAdmin_AdminSetLimitStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminSetLimitStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminGetLimitStream interface {
+	drpc.Stream
+	SendAndClose(*AdminGetLimitResponse) error
+}
+
+type drpcPublishAdmin_AdminGetLimitStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminGetLimitStream) SendAndClose(m *AdminGetLimitResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminCleanupStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcPublishAdmin_AdminCleanupStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminCleanupStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCPublishAdmin_AdminGetStorageUsageStream interface {
+	drpc.Stream
+	SendAndClose(*AdminGetStorageUsageResponse) error
+}
+
+type drpcPublishAdmin_AdminGetStorageUsageStream struct {
+	drpc.Stream
+}
+
+func (x *drpcPublishAdmin_AdminGetStorageUsageStream) SendAndClose(m *AdminGetStorageUsageResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_admin_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
@@ -9,8 +9,16 @@ import (
 var (
 	errGroup = rpcerr.ErrGroup(ErrCodes_ErrorOffset)
 
-	ErrUnexpected   = errGroup.Register(errors.New("unexpected error"), uint64(ErrCodes_Unexpected))
-	ErrNotFound     = errGroup.Register(errors.New("not found"), uint64(ErrCodes_NotFound))
-	ErrAccessDenied = errGroup.Register(errors.New("access denied"), uint64(ErrCodes_AccessDenied))
-	ErrUriNotUnique = errGroup.Register(errors.New("uri already taken"), uint64(ErrCodes_UriNotUnique))
+	ErrUnexpected          = errGroup.Register(errors.New("unexpected error"), uint64(ErrCodes_Unexpected))
+	ErrNotFound            = errGroup.Register(errors.New("not found"), uint64(ErrCodes_NotFound))
+	ErrAccessDenied        = errGroup.Register(errors.New("access denied"), uint64(ErrCodes_AccessDenied))
+	ErrUriNotUnique        = errGroup.Register(errors.New("uri already taken"), uint64(ErrCodes_UriNotUnique))
+	ErrDomainNotUnique     = errGroup.Register(errors.New("domain already taken"), uint64(ErrCodes_DomainNotUnique))
+	ErrDomainNotVerified   = errGroup.Register(errors.New("domain verification record not found"), uint64(ErrCodes_DomainNotVerified))
+	ErrInvalidDomain       = errGroup.Register(errors.New("invalid domain"), uint64(ErrCodes_InvalidDomain))
+	ErrInvalidAnalytics    = errGroup.Register(errors.New("invalid analytics config"), uint64(ErrCodes_InvalidAnalytics))
+	ErrUploadLimitExceeded = errGroup.Register(errors.New("upload limit exceeded"), uint64(ErrCodes_UploadLimitExceeded))
+	ErrInvalidUpload       = errGroup.Register(errors.New("invalid upload"), uint64(ErrCodes_InvalidUpload))
+	ErrUploadUsed          = errGroup.Register(errors.New("upload url already used"), uint64(ErrCodes_UploadUsed))
+	ErrUploadExpired       = errGroup.Register(errors.New("upload url expired"), uint64(ErrCodes_UploadExpired))
 )
@@ -24,11 +24,19 @@ const (
 type ErrCodes int32
 
 const (
-	ErrCodes_Unexpected   ErrCodes = 0
-	ErrCodes_NotFound     ErrCodes = 1
-	ErrCodes_AccessDenied ErrCodes = 2
-	ErrCodes_UriNotUnique ErrCodes = 3
-	ErrCodes_ErrorOffset  ErrCodes = 1100
+	ErrCodes_Unexpected          ErrCodes = 0
+	ErrCodes_NotFound            ErrCodes = 1
+	ErrCodes_AccessDenied        ErrCodes = 2
+	ErrCodes_UriNotUnique        ErrCodes = 3
+	ErrCodes_DomainNotUnique     ErrCodes = 4
+	ErrCodes_DomainNotVerified   ErrCodes = 5
+	ErrCodes_InvalidDomain       ErrCodes = 6
+	ErrCodes_InvalidAnalytics    ErrCodes = 7
+	ErrCodes_UploadLimitExceeded ErrCodes = 8
+	ErrCodes_InvalidUpload       ErrCodes = 9
+	ErrCodes_UploadUsed          ErrCodes = 10
+	ErrCodes_UploadExpired       ErrCodes = 11
+	ErrCodes_ErrorOffset         ErrCodes = 1100
 )
 
 // Enum value maps for ErrCodes.
@@ -38,14 +46,30 @@ var (
 		1:    "NotFound",
 		2:    "AccessDenied",
 		3:    "UriNotUnique",
+		4:    "DomainNotUnique",
+		5:    "DomainNotVerified",
+		6:    "InvalidDomain",
+		7:    "InvalidAnalytics",
+		8:    "UploadLimitExceeded",
+		9:    "InvalidUpload",
+		10:   "UploadUsed",
+		11:   "UploadExpired",
 		1100: "ErrorOffset",
 	}
 	ErrCodes_value = map[string]int32{
-		"Unexpected":   0,
-		"NotFound":     1,
-		"AccessDenied": 2,
-		"UriNotUnique": 3,
-		"ErrorOffset":  1100,
+		"Unexpected":          0,
+		"NotFound":            1,
+		"AccessDenied":        2,
+		"UriNotUnique":        3,
+		"DomainNotUnique":     4,
+		"DomainNotVerified":   5,
+		"InvalidDomain":       6,
+		"InvalidAnalytics":    7,
+		"UploadLimitExceeded": 8,
+		"InvalidUpload":       9,
+		"UploadUsed":          10,
+		"UploadExpired":       11,
+		"ErrorOffset":         1100,
 	}
 )
 
@@ -124,6 +148,105 @@ func (PublishStatus) EnumDescriptor() ([]byte, []int) {
 	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{1}
 }
 
+type PublishType int32
+
+const (
+	// PublishTypeRendered means the uploaded files are rendered as an Anytype page
+	PublishType_PublishTypeRendered PublishType = 0
+	// PublishTypeStatic means the uploaded files are served as-is as a static site
+	PublishType_PublishTypeStatic PublishType = 1
+)
+
+// Enum value maps for PublishType.
+var (
+	PublishType_name = map[int32]string{
+		0: "PublishTypeRendered",
+		1: "PublishTypeStatic",
+	}
+	PublishType_value = map[string]int32{
+		"PublishTypeRendered": 0,
+		"PublishTypeStatic":   1,
+	}
+)
+
+func (x PublishType) Enum() *PublishType {
+	p := new(PublishType)
+	*p = x
+	return p
+}
+
+func (x PublishType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PublishType) Descriptor() protoreflect.EnumDescriptor {
+	return file_publishclient_publishapi_protos_publisher_proto_enumTypes[2].Descriptor()
+}
+
+func (PublishType) Type() protoreflect.EnumType {
+	return &file_publishclient_publishapi_protos_publisher_proto_enumTypes[2]
+}
+
+func (x PublishType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PublishType.Descriptor instead.
+func (PublishType) EnumDescriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{2}
+}
+
+type Visibility int32
+
+const (
+	Visibility_VisibilityPublic Visibility = 0
+	// VisibilityUnlisted pages get a random uri suffix and are not indexed
+	Visibility_VisibilityUnlisted Visibility = 1
+	// VisibilityPrivate pages are served only to the owner and members signed in on the gateway or by share links
+	Visibility_VisibilityPrivate Visibility = 2
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "VisibilityPublic",
+		1: "VisibilityUnlisted",
+		2: "VisibilityPrivate",
+	}
+	Visibility_value = map[string]int32{
+		"VisibilityPublic":   0,
+		"VisibilityUnlisted": 1,
+		"VisibilityPrivate":  2,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_publishclient_publishapi_protos_publisher_proto_enumTypes[3].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_publishclient_publishapi_protos_publisher_proto_enumTypes[3]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{3}
+}
+
 type ResolveUriRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
@@ -213,14 +336,21 @@ func (x *ResolveUriResponse) GetPublish() *Publish {
 }
 
 type Publish struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SpaceId       string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
-	ObjectId      string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
-	Uri           string                 `protobuf:"bytes,3,opt,name=uri,proto3" json:"uri,omitempty"`
-	Status        PublishStatus          `protobuf:"varint,4,opt,name=status,proto3,enum=client.PublishStatus" json:"status,omitempty"`
-	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Size          int64                  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId   string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId  string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	Uri       string                 `protobuf:"bytes,3,opt,name=uri,proto3" json:"uri,omitempty"`
+	Status    PublishStatus          `protobuf:"varint,4,opt,name=status,proto3,enum=client.PublishStatus" json:"status,omitempty"`
+	Version   string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	Timestamp int64                  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Size      int64                  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	// notFoundPage is true when the object is rendered for unknown uris of the identity
+	NotFoundPage bool        `protobuf:"varint,8,opt,name=notFoundPage,proto3" json:"notFoundPage,omitempty"`
+	Type         PublishType `protobuf:"varint,9,opt,name=type,proto3,enum=client.PublishType" json:"type,omitempty"`
+	// publishId of the active publish, {uri}@{publishId} is a permalink to this version
+	PublishId     string     `protobuf:"bytes,10,opt,name=publishId,proto3" json:"publishId,omitempty"`
+	Visibility    Visibility `protobuf:"varint,11,opt,name=visibility,proto3,enum=client.Visibility" json:"visibility,omitempty"`
+	Members       []string   `protobuf:"bytes,12,rep,name=members,proto3" json:"members,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -304,6 +434,41 @@ func (x *Publish) GetSize() int64 {
 	return 0
 }
 
+func (x *Publish) GetNotFoundPage() bool {
+	if x != nil {
+		return x.NotFoundPage
+	}
+	return false
+}
+
+func (x *Publish) GetType() PublishType {
+	if x != nil {
+		return x.Type
+	}
+	return PublishType_PublishTypeRendered
+}
+
+func (x *Publish) GetPublishId() string {
+	if x != nil {
+		return x.PublishId
+	}
+	return ""
+}
+
+func (x *Publish) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VisibilityPublic
+}
+
+func (x *Publish) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
 type Ok struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -437,11 +602,20 @@ func (x *GetPublishStatusResponse) GetPublish() *Publish {
 }
 
 type PublishRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SpaceId       string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
-	ObjectId      string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
-	Uri           string                 `protobuf:"bytes,3,opt,name=uri,proto3" json:"uri,omitempty"`
-	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId  string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	Uri      string                 `protobuf:"bytes,3,opt,name=uri,proto3" json:"uri,omitempty"`
+	Version  string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Type     PublishType            `protobuf:"varint,5,opt,name=type,proto3,enum=client.PublishType" json:"type,omitempty"`
+	// spaFallback serves index.html of the static site for unknown paths
+	SpaFallback bool       `protobuf:"varint,6,opt,name=spaFallback,proto3" json:"spaFallback,omitempty"`
+	Visibility  Visibility `protobuf:"varint,7,opt,name=visibility,proto3,enum=client.Visibility" json:"visibility,omitempty"`
+	// members are identities allowed to read the private page after signing in on the gateway.
+	// It's an explicit list of the publish, space members don't get access implicitly
+	Members []string `protobuf:"bytes,8,rep,name=members,proto3" json:"members,omitempty"`
+	// preview creates the publish served only by the secret preview url, it doesn't replace the live version until promoted
+	Preview       bool `protobuf:"varint,9,opt,name=preview,proto3" json:"preview,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -504,9 +678,49 @@ func (x *PublishRequest) GetVersion() string {
 	return ""
 }
 
+func (x *PublishRequest) GetType() PublishType {
+	if x != nil {
+		return x.Type
+	}
+	return PublishType_PublishTypeRendered
+}
+
+func (x *PublishRequest) GetSpaFallback() bool {
+	if x != nil {
+		return x.SpaFallback
+	}
+	return false
+}
+
+func (x *PublishRequest) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VisibilityPublic
+}
+
+func (x *PublishRequest) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *PublishRequest) GetPreview() bool {
+	if x != nil {
+		return x.Preview
+	}
+	return false
+}
+
 type PublishResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UploadUrl     string                 `protobuf:"bytes,1,opt,name=uploadUrl,proto3" json:"uploadUrl,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	UploadUrl string                 `protobuf:"bytes,1,opt,name=uploadUrl,proto3" json:"uploadUrl,omitempty"`
+	// uri of the publish, includes the random suffix for unlisted pages
+	Uri       string `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+	PublishId string `protobuf:"bytes,3,opt,name=publishId,proto3" json:"publishId,omitempty"`
+	// previewUrl is the secret url of the preview publish, available after the upload
+	PreviewUrl    string `protobuf:"bytes,4,opt,name=previewUrl,proto3" json:"previewUrl,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -548,6 +762,27 @@ func (x *PublishResponse) GetUploadUrl() string {
 	return ""
 }
 
+func (x *PublishResponse) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *PublishResponse) GetPublishId() string {
+	if x != nil {
+		return x.PublishId
+	}
+	return ""
+}
+
+func (x *PublishResponse) GetPreviewUrl() string {
+	if x != nil {
+		return x.PreviewUrl
+	}
+	return ""
+}
+
 type UnPublishRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SpaceId       string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
@@ -688,53 +923,1576 @@ func (x *ListPublishesResponse) GetPublishes() []*Publish {
 	return nil
 }
 
-var File_publishclient_publishapi_protos_publisher_proto protoreflect.FileDescriptor
+type SetNotFoundPageRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// spaceId and objectId of the published object; empty objectId resets the 404 page
+	SpaceId       string `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId      string `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_publishclient_publishapi_protos_publisher_proto_rawDesc = "" +
-	"\n" +
-	"/publishclient/publishapi/protos/publisher.proto\x12\x06client\"%\n" +
-	"\x11ResolveUriRequest\x12\x10\n" +
-	"\x03uri\x18\x01 \x01(\tR\x03uri\"?\n" +
-	"\x12ResolveUriResponse\x12)\n" +
-	"\apublish\x18\x01 \x01(\v2\x0f.client.PublishR\apublish\"\xcc\x01\n" +
-	"\aPublish\x12\x18\n" +
-	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
-	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x10\n" +
-	"\x03uri\x18\x03 \x01(\tR\x03uri\x12-\n" +
-	"\x06status\x18\x04 \x01(\x0e2\x15.client.PublishStatusR\x06status\x12\x18\n" +
-	"\aversion\x18\x05 \x01(\tR\aversion\x12\x1c\n" +
-	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x12\n" +
-	"\x04size\x18\a \x01(\x03R\x04size\"\x04\n" +
-	"\x02Ok\"O\n" +
-	"\x17GetPublishStatusRequest\x12\x18\n" +
-	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
-	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"E\n" +
-	"\x18GetPublishStatusResponse\x12)\n" +
-	"\apublish\x18\x01 \x01(\v2\x0f.client.PublishR\apublish\"r\n" +
-	"\x0ePublishRequest\x12\x18\n" +
-	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
-	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x10\n" +
-	"\x03uri\x18\x03 \x01(\tR\x03uri\x12\x18\n" +
-	"\aversion\x18\x04 \x01(\tR\aversion\"/\n" +
-	"\x0fPublishResponse\x12\x1c\n" +
-	"\tuploadUrl\x18\x01 \x01(\tR\tuploadUrl\"H\n" +
-	"\x10UnPublishRequest\x12\x18\n" +
-	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
-	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"0\n" +
-	"\x14ListPublishesRequest\x12\x18\n" +
-	"\aspaceId\x18\x01 \x01(\tR\aspaceId\"F\n" +
-	"\x15ListPublishesResponse\x12-\n" +
-	"\tpublishes\x18\x01 \x03(\v2\x0f.client.PublishR\tpublishes*^\n" +
-	"\bErrCodes\x12\x0e\n" +
-	"\n" +
-	"Unexpected\x10\x00\x12\f\n" +
-	"\bNotFound\x10\x01\x12\x10\n" +
-	"\fAccessDenied\x10\x02\x12\x10\n" +
-	"\fUriNotUnique\x10\x03\x12\x10\n" +
-	"\vErrorOffset\x10\xcc\b*E\n" +
-	"\rPublishStatus\x12\x18\n" +
-	"\x14PublishStatusCreated\x10\x00\x12\x1a\n" +
-	"\x16PublishStatusPublished\x10\x012\xe7\x02\n" +
+func (x *SetNotFoundPageRequest) Reset() {
+	*x = SetNotFoundPageRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetNotFoundPageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetNotFoundPageRequest) ProtoMessage() {}
+
+func (x *SetNotFoundPageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetNotFoundPageRequest.ProtoReflect.Descriptor instead.
+func (*SetNotFoundPageRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetNotFoundPageRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *SetNotFoundPageRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type Domain struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Host     string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Verified bool                   `protobuf:"varint,2,opt,name=verified,proto3" json:"verified,omitempty"`
+	// txtRecordName and txtRecordValue describe the DNS TXT record required for the verification
+	TxtRecordName  string `protobuf:"bytes,3,opt,name=txtRecordName,proto3" json:"txtRecordName,omitempty"`
+	TxtRecordValue string `protobuf:"bytes,4,opt,name=txtRecordValue,proto3" json:"txtRecordValue,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Domain) Reset() {
+	*x = Domain{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Domain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Domain) ProtoMessage() {}
+
+func (x *Domain) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Domain.ProtoReflect.Descriptor instead.
+func (*Domain) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Domain) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Domain) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Domain) GetTxtRecordName() string {
+	if x != nil {
+		return x.TxtRecordName
+	}
+	return ""
+}
+
+func (x *Domain) GetTxtRecordValue() string {
+	if x != nil {
+		return x.TxtRecordValue
+	}
+	return ""
+}
+
+type AddDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddDomainRequest) Reset() {
+	*x = AddDomainRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddDomainRequest) ProtoMessage() {}
+
+func (x *AddDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddDomainRequest.ProtoReflect.Descriptor instead.
+func (*AddDomainRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AddDomainRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type AddDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        *Domain                `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddDomainResponse) Reset() {
+	*x = AddDomainResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddDomainResponse) ProtoMessage() {}
+
+func (x *AddDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddDomainResponse.ProtoReflect.Descriptor instead.
+func (*AddDomainResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AddDomainResponse) GetDomain() *Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+type VerifyDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyDomainRequest) Reset() {
+	*x = VerifyDomainRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyDomainRequest) ProtoMessage() {}
+
+func (x *VerifyDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyDomainRequest.ProtoReflect.Descriptor instead.
+func (*VerifyDomainRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VerifyDomainRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type VerifyDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        *Domain                `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyDomainResponse) Reset() {
+	*x = VerifyDomainResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyDomainResponse) ProtoMessage() {}
+
+func (x *VerifyDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyDomainResponse.ProtoReflect.Descriptor instead.
+func (*VerifyDomainResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VerifyDomainResponse) GetDomain() *Domain {
+	if x != nil {
+		return x.Domain
+	}
+	return nil
+}
+
+type RemoveDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveDomainRequest) Reset() {
+	*x = RemoveDomainRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveDomainRequest) ProtoMessage() {}
+
+func (x *RemoveDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveDomainRequest.ProtoReflect.Descriptor instead.
+func (*RemoveDomainRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RemoveDomainRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type ListDomainsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDomainsRequest) Reset() {
+	*x = ListDomainsRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDomainsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDomainsRequest) ProtoMessage() {}
+
+func (x *ListDomainsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDomainsRequest.ProtoReflect.Descriptor instead.
+func (*ListDomainsRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{18}
+}
+
+type ListDomainsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domains       []*Domain              `protobuf:"bytes,1,rep,name=domains,proto3" json:"domains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDomainsResponse) Reset() {
+	*x = ListDomainsResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDomainsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDomainsResponse) ProtoMessage() {}
+
+func (x *ListDomainsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDomainsResponse.ProtoReflect.Descriptor instead.
+func (*ListDomainsResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListDomainsResponse) GetDomains() []*Domain {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+// Redirect points the previous uri of the object to the current one
+type Redirect struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	SpaceId       string                 `protobuf:"bytes,2,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId      string                 `protobuf:"bytes,3,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	TargetUri     string                 `protobuf:"bytes,4,opt,name=targetUri,proto3" json:"targetUri,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Redirect) Reset() {
+	*x = Redirect{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Redirect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Redirect) ProtoMessage() {}
+
+func (x *Redirect) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Redirect.ProtoReflect.Descriptor instead.
+func (*Redirect) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Redirect) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *Redirect) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *Redirect) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *Redirect) GetTargetUri() string {
+	if x != nil {
+		return x.TargetUri
+	}
+	return ""
+}
+
+func (x *Redirect) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type ListRedirectsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// optional filters
+	SpaceId       string `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId      string `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRedirectsRequest) Reset() {
+	*x = ListRedirectsRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRedirectsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRedirectsRequest) ProtoMessage() {}
+
+func (x *ListRedirectsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRedirectsRequest.ProtoReflect.Descriptor instead.
+func (*ListRedirectsRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListRedirectsRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *ListRedirectsRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type ListRedirectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Redirects     []*Redirect            `protobuf:"bytes,1,rep,name=redirects,proto3" json:"redirects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRedirectsResponse) Reset() {
+	*x = ListRedirectsResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRedirectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRedirectsResponse) ProtoMessage() {}
+
+func (x *ListRedirectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRedirectsResponse.ProtoReflect.Descriptor instead.
+func (*ListRedirectsResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListRedirectsResponse) GetRedirects() []*Redirect {
+	if x != nil {
+		return x.Redirects
+	}
+	return nil
+}
+
+type DeleteRedirectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRedirectRequest) Reset() {
+	*x = DeleteRedirectRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRedirectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRedirectRequest) ProtoMessage() {}
+
+func (x *DeleteRedirectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRedirectRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRedirectRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteRedirectRequest) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+type CreateShareLinkRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId  string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	// ttl of the link in seconds
+	Ttl           int64 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShareLinkRequest) Reset() {
+	*x = CreateShareLinkRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareLinkRequest) ProtoMessage() {}
+
+func (x *CreateShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*CreateShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateShareLinkRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+type CreateShareLinkResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Url             string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpireTimestamp int64                  `protobuf:"varint,2,opt,name=expireTimestamp,proto3" json:"expireTimestamp,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateShareLinkResponse) Reset() {
+	*x = CreateShareLinkResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareLinkResponse) ProtoMessage() {}
+
+func (x *CreateShareLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareLinkResponse.ProtoReflect.Descriptor instead.
+func (*CreateShareLinkResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateShareLinkResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateShareLinkResponse) GetExpireTimestamp() int64 {
+	if x != nil {
+		return x.ExpireTimestamp
+	}
+	return 0
+}
+
+type RevokeShareLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId       string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId      string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareLinksRequest) Reset() {
+	*x = RevokeShareLinksRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareLinksRequest) ProtoMessage() {}
+
+func (x *RevokeShareLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareLinksRequest.ProtoReflect.Descriptor instead.
+func (*RevokeShareLinksRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RevokeShareLinksRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *RevokeShareLinksRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+type PromotePreviewRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId  string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	// publishId of the uploaded preview
+	PublishId     string `protobuf:"bytes,3,opt,name=publishId,proto3" json:"publishId,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromotePreviewRequest) Reset() {
+	*x = PromotePreviewRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromotePreviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromotePreviewRequest) ProtoMessage() {}
+
+func (x *PromotePreviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromotePreviewRequest.ProtoReflect.Descriptor instead.
+func (*PromotePreviewRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PromotePreviewRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *PromotePreviewRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *PromotePreviewRequest) GetPublishId() string {
+	if x != nil {
+		return x.PublishId
+	}
+	return ""
+}
+
+type GetPublishStatsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	SpaceId  string                 `protobuf:"bytes,1,opt,name=spaceId,proto3" json:"spaceId,omitempty"`
+	ObjectId string                 `protobuf:"bytes,2,opt,name=objectId,proto3" json:"objectId,omitempty"`
+	// fromDate and toDate are inclusive UTC dates in the YYYY-MM-DD format, the last 30 days by default
+	FromDate      string `protobuf:"bytes,3,opt,name=fromDate,proto3" json:"fromDate,omitempty"`
+	ToDate        string `protobuf:"bytes,4,opt,name=toDate,proto3" json:"toDate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPublishStatsRequest) Reset() {
+	*x = GetPublishStatsRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublishStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublishStatsRequest) ProtoMessage() {}
+
+func (x *GetPublishStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublishStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetPublishStatsRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetPublishStatsRequest) GetSpaceId() string {
+	if x != nil {
+		return x.SpaceId
+	}
+	return ""
+}
+
+func (x *GetPublishStatsRequest) GetObjectId() string {
+	if x != nil {
+		return x.ObjectId
+	}
+	return ""
+}
+
+func (x *GetPublishStatsRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *GetPublishStatsRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+type GetPublishStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// days with views, sorted by date
+	Days  []*PublishStatsDay `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	Views int64              `protobuf:"varint,2,opt,name=views,proto3" json:"views,omitempty"`
+	// uniques is the sum of daily unique visitors
+	Uniques       int64 `protobuf:"varint,3,opt,name=uniques,proto3" json:"uniques,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPublishStatsResponse) Reset() {
+	*x = GetPublishStatsResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublishStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublishStatsResponse) ProtoMessage() {}
+
+func (x *GetPublishStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublishStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetPublishStatsResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetPublishStatsResponse) GetDays() []*PublishStatsDay {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *GetPublishStatsResponse) GetViews() int64 {
+	if x != nil {
+		return x.Views
+	}
+	return 0
+}
+
+func (x *GetPublishStatsResponse) GetUniques() int64 {
+	if x != nil {
+		return x.Uniques
+	}
+	return 0
+}
+
+type PublishStatsDay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Views         int64                  `protobuf:"varint,2,opt,name=views,proto3" json:"views,omitempty"`
+	Uniques       int64                  `protobuf:"varint,3,opt,name=uniques,proto3" json:"uniques,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishStatsDay) Reset() {
+	*x = PublishStatsDay{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishStatsDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishStatsDay) ProtoMessage() {}
+
+func (x *PublishStatsDay) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishStatsDay.ProtoReflect.Descriptor instead.
+func (*PublishStatsDay) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PublishStatsDay) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *PublishStatsDay) GetViews() int64 {
+	if x != nil {
+		return x.Views
+	}
+	return 0
+}
+
+func (x *PublishStatsDay) GetUniques() int64 {
+	if x != nil {
+		return x.Uniques
+	}
+	return 0
+}
+
+type Analytics struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// provider is one of: plausible, umami, google
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// siteId is the plausible site domain, the umami website id or the google measurement id
+	SiteId string `protobuf:"bytes,2,opt,name=siteId,proto3" json:"siteId,omitempty"`
+	// host of the self-hosted plausible or umami, the provider cloud when empty
+	Host          string `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Analytics) Reset() {
+	*x = Analytics{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Analytics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Analytics) ProtoMessage() {}
+
+func (x *Analytics) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Analytics.ProtoReflect.Descriptor instead.
+func (*Analytics) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Analytics) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Analytics) GetSiteId() string {
+	if x != nil {
+		return x.SiteId
+	}
+	return ""
+}
+
+func (x *Analytics) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type SetAnalyticsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// analytics of the identity pages, empty removes the integration
+	Analytics     *Analytics `protobuf:"bytes,1,opt,name=analytics,proto3" json:"analytics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAnalyticsRequest) Reset() {
+	*x = SetAnalyticsRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAnalyticsRequest) ProtoMessage() {}
+
+func (x *SetAnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*SetAnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SetAnalyticsRequest) GetAnalytics() *Analytics {
+	if x != nil {
+		return x.Analytics
+	}
+	return nil
+}
+
+type GetAnalyticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnalyticsRequest) Reset() {
+	*x = GetAnalyticsRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnalyticsRequest) ProtoMessage() {}
+
+func (x *GetAnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*GetAnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{33}
+}
+
+type GetAnalyticsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Analytics     *Analytics             `protobuf:"bytes,1,opt,name=analytics,proto3" json:"analytics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnalyticsResponse) Reset() {
+	*x = GetAnalyticsResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnalyticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnalyticsResponse) ProtoMessage() {}
+
+func (x *GetAnalyticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnalyticsResponse.ProtoReflect.Descriptor instead.
+func (*GetAnalyticsResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetAnalyticsResponse) GetAnalytics() *Analytics {
+	if x != nil {
+		return x.Analytics
+	}
+	return nil
+}
+
+// UploadFilesRequest is a message of the upload stream: the first message has the publishId,
+// every file starts with the message with the header followed by the data chunks
+type UploadFilesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PublishId     string                 `protobuf:"bytes,1,opt,name=publishId,proto3" json:"publishId,omitempty"`
+	Header        *UploadFileHeader      `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,3,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFilesRequest) Reset() {
+	*x = UploadFilesRequest{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFilesRequest) ProtoMessage() {}
+
+func (x *UploadFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFilesRequest.ProtoReflect.Descriptor instead.
+func (*UploadFilesRequest) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UploadFilesRequest) GetPublishId() string {
+	if x != nil {
+		return x.PublishId
+	}
+	return ""
+}
+
+func (x *UploadFilesRequest) GetHeader() *UploadFileHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *UploadFilesRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type UploadFileHeader struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the slash separated path of the file
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileHeader) Reset() {
+	*x = UploadFileHeader{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileHeader) ProtoMessage() {}
+
+func (x *UploadFileHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileHeader.ProtoReflect.Descriptor instead.
+func (*UploadFileHeader) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *UploadFileHeader) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UploadFileHeader) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type UploadFilesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// url is the page url or the secret preview url
+	Url           string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFilesResponse) Reset() {
+	*x = UploadFilesResponse{}
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFilesResponse) ProtoMessage() {}
+
+func (x *UploadFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_publishclient_publishapi_protos_publisher_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFilesResponse.ProtoReflect.Descriptor instead.
+func (*UploadFilesResponse) Descriptor() ([]byte, []int) {
+	return file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *UploadFilesResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+var File_publishclient_publishapi_protos_publisher_proto protoreflect.FileDescriptor
+
+const file_publishclient_publishapi_protos_publisher_proto_rawDesc = "" +
+	"\n" +
+	"/publishclient/publishapi/protos/publisher.proto\x12\x06client\"%\n" +
+	"\x11ResolveUriRequest\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\"?\n" +
+	"\x12ResolveUriResponse\x12)\n" +
+	"\apublish\x18\x01 \x01(\v2\x0f.client.PublishR\apublish\"\x85\x03\n" +
+	"\aPublish\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x10\n" +
+	"\x03uri\x18\x03 \x01(\tR\x03uri\x12-\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x15.client.PublishStatusR\x06status\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\x12\x1c\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04size\x18\a \x01(\x03R\x04size\x12\"\n" +
+	"\fnotFoundPage\x18\b \x01(\bR\fnotFoundPage\x12'\n" +
+	"\x04type\x18\t \x01(\x0e2\x13.client.PublishTypeR\x04type\x12\x1c\n" +
+	"\tpublishId\x18\n" +
+	" \x01(\tR\tpublishId\x122\n" +
+	"\n" +
+	"visibility\x18\v \x01(\x0e2\x12.client.VisibilityR\n" +
+	"visibility\x12\x18\n" +
+	"\amembers\x18\f \x03(\tR\amembers\"\x04\n" +
+	"\x02Ok\"O\n" +
+	"\x17GetPublishStatusRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"E\n" +
+	"\x18GetPublishStatusResponse\x12)\n" +
+	"\apublish\x18\x01 \x01(\v2\x0f.client.PublishR\apublish\"\xa5\x02\n" +
+	"\x0ePublishRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x10\n" +
+	"\x03uri\x18\x03 \x01(\tR\x03uri\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12'\n" +
+	"\x04type\x18\x05 \x01(\x0e2\x13.client.PublishTypeR\x04type\x12 \n" +
+	"\vspaFallback\x18\x06 \x01(\bR\vspaFallback\x122\n" +
+	"\n" +
+	"visibility\x18\a \x01(\x0e2\x12.client.VisibilityR\n" +
+	"visibility\x12\x18\n" +
+	"\amembers\x18\b \x03(\tR\amembers\x12\x18\n" +
+	"\apreview\x18\t \x01(\bR\apreview\"\x7f\n" +
+	"\x0fPublishResponse\x12\x1c\n" +
+	"\tuploadUrl\x18\x01 \x01(\tR\tuploadUrl\x12\x10\n" +
+	"\x03uri\x18\x02 \x01(\tR\x03uri\x12\x1c\n" +
+	"\tpublishId\x18\x03 \x01(\tR\tpublishId\x12\x1e\n" +
+	"\n" +
+	"previewUrl\x18\x04 \x01(\tR\n" +
+	"previewUrl\"H\n" +
+	"\x10UnPublishRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"0\n" +
+	"\x14ListPublishesRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\"F\n" +
+	"\x15ListPublishesResponse\x12-\n" +
+	"\tpublishes\x18\x01 \x03(\v2\x0f.client.PublishR\tpublishes\"N\n" +
+	"\x16SetNotFoundPageRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"\x86\x01\n" +
+	"\x06Domain\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x1a\n" +
+	"\bverified\x18\x02 \x01(\bR\bverified\x12$\n" +
+	"\rtxtRecordName\x18\x03 \x01(\tR\rtxtRecordName\x12&\n" +
+	"\x0etxtRecordValue\x18\x04 \x01(\tR\x0etxtRecordValue\"&\n" +
+	"\x10AddDomainRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\";\n" +
+	"\x11AddDomainResponse\x12&\n" +
+	"\x06domain\x18\x01 \x01(\v2\x0e.client.DomainR\x06domain\")\n" +
+	"\x13VerifyDomainRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\">\n" +
+	"\x14VerifyDomainResponse\x12&\n" +
+	"\x06domain\x18\x01 \x01(\v2\x0e.client.DomainR\x06domain\")\n" +
+	"\x13RemoveDomainRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\"\x14\n" +
+	"\x12ListDomainsRequest\"?\n" +
+	"\x13ListDomainsResponse\x12(\n" +
+	"\adomains\x18\x01 \x03(\v2\x0e.client.DomainR\adomains\"\x8e\x01\n" +
+	"\bRedirect\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\x12\x18\n" +
+	"\aspaceId\x18\x02 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x03 \x01(\tR\bobjectId\x12\x1c\n" +
+	"\ttargetUri\x18\x04 \x01(\tR\ttargetUri\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\"L\n" +
+	"\x14ListRedirectsRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"G\n" +
+	"\x15ListRedirectsResponse\x12.\n" +
+	"\tredirects\x18\x01 \x03(\v2\x10.client.RedirectR\tredirects\")\n" +
+	"\x15DeleteRedirectRequest\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\"`\n" +
+	"\x16CreateShareLinkRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x10\n" +
+	"\x03ttl\x18\x03 \x01(\x03R\x03ttl\"U\n" +
+	"\x17CreateShareLinkResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12(\n" +
+	"\x0fexpireTimestamp\x18\x02 \x01(\x03R\x0fexpireTimestamp\"O\n" +
+	"\x17RevokeShareLinksRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\"k\n" +
+	"\x15PromotePreviewRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x1c\n" +
+	"\tpublishId\x18\x03 \x01(\tR\tpublishId\"\x82\x01\n" +
+	"\x16GetPublishStatsRequest\x12\x18\n" +
+	"\aspaceId\x18\x01 \x01(\tR\aspaceId\x12\x1a\n" +
+	"\bobjectId\x18\x02 \x01(\tR\bobjectId\x12\x1a\n" +
+	"\bfromDate\x18\x03 \x01(\tR\bfromDate\x12\x16\n" +
+	"\x06toDate\x18\x04 \x01(\tR\x06toDate\"v\n" +
+	"\x17GetPublishStatsResponse\x12+\n" +
+	"\x04days\x18\x01 \x03(\v2\x17.client.PublishStatsDayR\x04days\x12\x14\n" +
+	"\x05views\x18\x02 \x01(\x03R\x05views\x12\x18\n" +
+	"\auniques\x18\x03 \x01(\x03R\auniques\"U\n" +
+	"\x0fPublishStatsDay\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x14\n" +
+	"\x05views\x18\x02 \x01(\x03R\x05views\x12\x18\n" +
+	"\auniques\x18\x03 \x01(\x03R\auniques\"S\n" +
+	"\tAnalytics\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06siteId\x18\x02 \x01(\tR\x06siteId\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\"F\n" +
+	"\x13SetAnalyticsRequest\x12/\n" +
+	"\tanalytics\x18\x01 \x01(\v2\x11.client.AnalyticsR\tanalytics\"\x15\n" +
+	"\x13GetAnalyticsRequest\"G\n" +
+	"\x14GetAnalyticsResponse\x12/\n" +
+	"\tanalytics\x18\x01 \x01(\v2\x11.client.AnalyticsR\tanalytics\"z\n" +
+	"\x12UploadFilesRequest\x12\x1c\n" +
+	"\tpublishId\x18\x01 \x01(\tR\tpublishId\x120\n" +
+	"\x06header\x18\x02 \x01(\v2\x18.client.UploadFileHeaderR\x06header\x12\x14\n" +
+	"\x05chunk\x18\x03 \x01(\fR\x05chunk\":\n" +
+	"\x10UploadFileHeader\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\"'\n" +
+	"\x13UploadFilesResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url*\x82\x02\n" +
+	"\bErrCodes\x12\x0e\n" +
+	"\n" +
+	"Unexpected\x10\x00\x12\f\n" +
+	"\bNotFound\x10\x01\x12\x10\n" +
+	"\fAccessDenied\x10\x02\x12\x10\n" +
+	"\fUriNotUnique\x10\x03\x12\x13\n" +
+	"\x0fDomainNotUnique\x10\x04\x12\x15\n" +
+	"\x11DomainNotVerified\x10\x05\x12\x11\n" +
+	"\rInvalidDomain\x10\x06\x12\x14\n" +
+	"\x10InvalidAnalytics\x10\a\x12\x17\n" +
+	"\x13UploadLimitExceeded\x10\b\x12\x11\n" +
+	"\rInvalidUpload\x10\t\x12\x0e\n" +
+	"\n" +
+	"UploadUsed\x10\n" +
+	"\x12\x11\n" +
+	"\rUploadExpired\x10\v\x12\x10\n" +
+	"\vErrorOffset\x10\xcc\b*E\n" +
+	"\rPublishStatus\x12\x18\n" +
+	"\x14PublishStatusCreated\x10\x00\x12\x1a\n" +
+	"\x16PublishStatusPublished\x10\x01*=\n" +
+	"\vPublishType\x12\x17\n" +
+	"\x13PublishTypeRendered\x10\x00\x12\x15\n" +
+	"\x11PublishTypeStatic\x10\x01*Q\n" +
+	"\n" +
+	"Visibility\x12\x14\n" +
+	"\x10VisibilityPublic\x10\x00\x12\x16\n" +
+	"\x12VisibilityUnlisted\x10\x01\x12\x15\n" +
+	"\x11VisibilityPrivate\x10\x022\xb3\n" +
+	"\n" +
 	"\fWebPublisher\x12C\n" +
 	"\n" +
 	"ResolveUri\x12\x19.client.ResolveUriRequest\x1a\x1a.client.ResolveUriResponse\x12U\n" +
@@ -742,7 +2500,27 @@ const file_publishclient_publishapi_protos_publisher_proto_rawDesc = "" +
 	"\aPublish\x12\x16.client.PublishRequest\x1a\x17.client.PublishResponse\x121\n" +
 	"\tUnPublish\x12\x18.client.UnPublishRequest\x1a\n" +
 	".client.Ok\x12L\n" +
-	"\rListPublishes\x12\x1c.client.ListPublishesRequest\x1a\x1d.client.ListPublishesResponseB\x1aZ\x18publishclient/publishapib\x06proto3"
+	"\rListPublishes\x12\x1c.client.ListPublishesRequest\x1a\x1d.client.ListPublishesResponse\x12=\n" +
+	"\x0fSetNotFoundPage\x12\x1e.client.SetNotFoundPageRequest\x1a\n" +
+	".client.Ok\x12@\n" +
+	"\tAddDomain\x12\x18.client.AddDomainRequest\x1a\x19.client.AddDomainResponse\x12I\n" +
+	"\fVerifyDomain\x12\x1b.client.VerifyDomainRequest\x1a\x1c.client.VerifyDomainResponse\x127\n" +
+	"\fRemoveDomain\x12\x1b.client.RemoveDomainRequest\x1a\n" +
+	".client.Ok\x12F\n" +
+	"\vListDomains\x12\x1a.client.ListDomainsRequest\x1a\x1b.client.ListDomainsResponse\x12L\n" +
+	"\rListRedirects\x12\x1c.client.ListRedirectsRequest\x1a\x1d.client.ListRedirectsResponse\x12;\n" +
+	"\x0eDeleteRedirect\x12\x1d.client.DeleteRedirectRequest\x1a\n" +
+	".client.Ok\x12R\n" +
+	"\x0fCreateShareLink\x12\x1e.client.CreateShareLinkRequest\x1a\x1f.client.CreateShareLinkResponse\x12?\n" +
+	"\x10RevokeShareLinks\x12\x1f.client.RevokeShareLinksRequest\x1a\n" +
+	".client.Ok\x12;\n" +
+	"\x0ePromotePreview\x12\x1d.client.PromotePreviewRequest\x1a\n" +
+	".client.Ok\x12R\n" +
+	"\x0fGetPublishStats\x12\x1e.client.GetPublishStatsRequest\x1a\x1f.client.GetPublishStatsResponse\x127\n" +
+	"\fSetAnalytics\x12\x1b.client.SetAnalyticsRequest\x1a\n" +
+	".client.Ok\x12I\n" +
+	"\fGetAnalytics\x12\x1b.client.GetAnalyticsRequest\x1a\x1c.client.GetAnalyticsResponse\x12H\n" +
+	"\vUploadFiles\x12\x1a.client.UploadFilesRequest\x1a\x1b.client.UploadFilesResponse(\x01B\x1aZ\x18publishclient/publishapib\x06proto3"
 
 var (
 	file_publishclient_publishapi_protos_publisher_proto_rawDescOnce sync.Once
@@ -756,43 +2534,112 @@ func file_publishclient_publishapi_protos_publisher_proto_rawDescGZIP() []byte {
 	return file_publishclient_publishapi_protos_publisher_proto_rawDescData
 }
 
-var file_publishclient_publishapi_protos_publisher_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_publishclient_publishapi_protos_publisher_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_publishclient_publishapi_protos_publisher_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_publishclient_publishapi_protos_publisher_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_publishclient_publishapi_protos_publisher_proto_goTypes = []any{
 	(ErrCodes)(0),                    // 0: client.ErrCodes
 	(PublishStatus)(0),               // 1: client.PublishStatus
-	(*ResolveUriRequest)(nil),        // 2: client.ResolveUriRequest
-	(*ResolveUriResponse)(nil),       // 3: client.ResolveUriResponse
-	(*Publish)(nil),                  // 4: client.Publish
-	(*Ok)(nil),                       // 5: client.Ok
-	(*GetPublishStatusRequest)(nil),  // 6: client.GetPublishStatusRequest
-	(*GetPublishStatusResponse)(nil), // 7: client.GetPublishStatusResponse
-	(*PublishRequest)(nil),           // 8: client.PublishRequest
-	(*PublishResponse)(nil),          // 9: client.PublishResponse
-	(*UnPublishRequest)(nil),         // 10: client.UnPublishRequest
-	(*ListPublishesRequest)(nil),     // 11: client.ListPublishesRequest
-	(*ListPublishesResponse)(nil),    // 12: client.ListPublishesResponse
+	(PublishType)(0),                 // 2: client.PublishType
+	(Visibility)(0),                  // 3: client.Visibility
+	(*ResolveUriRequest)(nil),        // 4: client.ResolveUriRequest
+	(*ResolveUriResponse)(nil),       // 5: client.ResolveUriResponse
+	(*Publish)(nil),                  // 6: client.Publish
+	(*Ok)(nil),                       // 7: client.Ok
+	(*GetPublishStatusRequest)(nil),  // 8: client.GetPublishStatusRequest
+	(*GetPublishStatusResponse)(nil), // 9: client.GetPublishStatusResponse
+	(*PublishRequest)(nil),           // 10: client.PublishRequest
+	(*PublishResponse)(nil),          // 11: client.PublishResponse
+	(*UnPublishRequest)(nil),         // 12: client.UnPublishRequest
+	(*ListPublishesRequest)(nil),     // 13: client.ListPublishesRequest
+	(*ListPublishesResponse)(nil),    // 14: client.ListPublishesResponse
+	(*SetNotFoundPageRequest)(nil),   // 15: client.SetNotFoundPageRequest
+	(*Domain)(nil),                   // 16: client.Domain
+	(*AddDomainRequest)(nil),         // 17: client.AddDomainRequest
+	(*AddDomainResponse)(nil),        // 18: client.AddDomainResponse
+	(*VerifyDomainRequest)(nil),      // 19: client.VerifyDomainRequest
+	(*VerifyDomainResponse)(nil),     // 20: client.VerifyDomainResponse
+	(*RemoveDomainRequest)(nil),      // 21: client.RemoveDomainRequest
+	(*ListDomainsRequest)(nil),       // 22: client.ListDomainsRequest
+	(*ListDomainsResponse)(nil),      // 23: client.ListDomainsResponse
+	(*Redirect)(nil),                 // 24: client.Redirect
+	(*ListRedirectsRequest)(nil),     // 25: client.ListRedirectsRequest
+	(*ListRedirectsResponse)(nil),    // 26: client.ListRedirectsResponse
+	(*DeleteRedirectRequest)(nil),    // 27: client.DeleteRedirectRequest
+	(*CreateShareLinkRequest)(nil),   // 28: client.CreateShareLinkRequest
+	(*CreateShareLinkResponse)(nil),  // 29: client.CreateShareLinkResponse
+	(*RevokeShareLinksRequest)(nil),  // 30: client.RevokeShareLinksRequest
+	(*PromotePreviewRequest)(nil),    // 31: client.PromotePreviewRequest
+	(*GetPublishStatsRequest)(nil),   // 32: client.GetPublishStatsRequest
+	(*GetPublishStatsResponse)(nil),  // 33: client.GetPublishStatsResponse
+	(*PublishStatsDay)(nil),          // 34: client.PublishStatsDay
+	(*Analytics)(nil),                // 35: client.Analytics
+	(*SetAnalyticsRequest)(nil),      // 36: client.SetAnalyticsRequest
+	(*GetAnalyticsRequest)(nil),      // 37: client.GetAnalyticsRequest
+	(*GetAnalyticsResponse)(nil),     // 38: client.GetAnalyticsResponse
+	(*UploadFilesRequest)(nil),       // 39: client.UploadFilesRequest
+	(*UploadFileHeader)(nil),         // 40: client.UploadFileHeader
+	(*UploadFilesResponse)(nil),      // 41: client.UploadFilesResponse
 }
 var file_publishclient_publishapi_protos_publisher_proto_depIdxs = []int32{
-	4,  // 0: client.ResolveUriResponse.publish:type_name -> client.Publish
+	6,  // 0: client.ResolveUriResponse.publish:type_name -> client.Publish
 	1,  // 1: client.Publish.status:type_name -> client.PublishStatus
-	4,  // 2: client.GetPublishStatusResponse.publish:type_name -> client.Publish
-	4,  // 3: client.ListPublishesResponse.publishes:type_name -> client.Publish
-	2,  // 4: client.WebPublisher.ResolveUri:input_type -> client.ResolveUriRequest
-	6,  // 5: client.WebPublisher.GetPublishStatus:input_type -> client.GetPublishStatusRequest
-	8,  // 6: client.WebPublisher.Publish:input_type -> client.PublishRequest
-	10, // 7: client.WebPublisher.UnPublish:input_type -> client.UnPublishRequest
-	11, // 8: client.WebPublisher.ListPublishes:input_type -> client.ListPublishesRequest
-	3,  // 9: client.WebPublisher.ResolveUri:output_type -> client.ResolveUriResponse
-	7,  // 10: client.WebPublisher.GetPublishStatus:output_type -> client.GetPublishStatusResponse
-	9,  // 11: client.WebPublisher.Publish:output_type -> client.PublishResponse
-	5,  // 12: client.WebPublisher.UnPublish:output_type -> client.Ok
-	12, // 13: client.WebPublisher.ListPublishes:output_type -> client.ListPublishesResponse
-	9,  // [9:14] is the sub-list for method output_type
-	4,  // [4:9] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	2,  // 2: client.Publish.type:type_name -> client.PublishType
+	3,  // 3: client.Publish.visibility:type_name -> client.Visibility
+	6,  // 4: client.GetPublishStatusResponse.publish:type_name -> client.Publish
+	2,  // 5: client.PublishRequest.type:type_name -> client.PublishType
+	3,  // 6: client.PublishRequest.visibility:type_name -> client.Visibility
+	6,  // 7: client.ListPublishesResponse.publishes:type_name -> client.Publish
+	16, // 8: client.AddDomainResponse.domain:type_name -> client.Domain
+	16, // 9: client.VerifyDomainResponse.domain:type_name -> client.Domain
+	16, // 10: client.ListDomainsResponse.domains:type_name -> client.Domain
+	24, // 11: client.ListRedirectsResponse.redirects:type_name -> client.Redirect
+	34, // 12: client.GetPublishStatsResponse.days:type_name -> client.PublishStatsDay
+	35, // 13: client.SetAnalyticsRequest.analytics:type_name -> client.Analytics
+	35, // 14: client.GetAnalyticsResponse.analytics:type_name -> client.Analytics
+	40, // 15: client.UploadFilesRequest.header:type_name -> client.UploadFileHeader
+	4,  // 16: client.WebPublisher.ResolveUri:input_type -> client.ResolveUriRequest
+	8,  // 17: client.WebPublisher.GetPublishStatus:input_type -> client.GetPublishStatusRequest
+	10, // 18: client.WebPublisher.Publish:input_type -> client.PublishRequest
+	12, // 19: client.WebPublisher.UnPublish:input_type -> client.UnPublishRequest
+	13, // 20: client.WebPublisher.ListPublishes:input_type -> client.ListPublishesRequest
+	15, // 21: client.WebPublisher.SetNotFoundPage:input_type -> client.SetNotFoundPageRequest
+	17, // 22: client.WebPublisher.AddDomain:input_type -> client.AddDomainRequest
+	19, // 23: client.WebPublisher.VerifyDomain:input_type -> client.VerifyDomainRequest
+	21, // 24: client.WebPublisher.RemoveDomain:input_type -> client.RemoveDomainRequest
+	22, // 25: client.WebPublisher.ListDomains:input_type -> client.ListDomainsRequest
+	25, // 26: client.WebPublisher.ListRedirects:input_type -> client.ListRedirectsRequest
+	27, // 27: client.WebPublisher.DeleteRedirect:input_type -> client.DeleteRedirectRequest
+	28, // 28: client.WebPublisher.CreateShareLink:input_type -> client.CreateShareLinkRequest
+	30, // 29: client.WebPublisher.RevokeShareLinks:input_type -> client.RevokeShareLinksRequest
+	31, // 30: client.WebPublisher.PromotePreview:input_type -> client.PromotePreviewRequest
+	32, // 31: client.WebPublisher.GetPublishStats:input_type -> client.GetPublishStatsRequest
+	36, // 32: client.WebPublisher.SetAnalytics:input_type -> client.SetAnalyticsRequest
+	37, // 33: client.WebPublisher.GetAnalytics:input_type -> client.GetAnalyticsRequest
+	39, // 34: client.WebPublisher.UploadFiles:input_type -> client.UploadFilesRequest
+	5,  // 35: client.WebPublisher.ResolveUri:output_type -> client.ResolveUriResponse
+	9,  // 36: client.WebPublisher.GetPublishStatus:output_type -> client.GetPublishStatusResponse
+	11, // 37: client.WebPublisher.Publish:output_type -> client.PublishResponse
+	7,  // 38: client.WebPublisher.UnPublish:output_type -> client.Ok
+	14, // 39: client.WebPublisher.ListPublishes:output_type -> client.ListPublishesResponse
+	7,  // 40: client.WebPublisher.SetNotFoundPage:output_type -> client.Ok
+	18, // 41: client.WebPublisher.AddDomain:output_type -> client.AddDomainResponse
+	20, // 42: client.WebPublisher.VerifyDomain:output_type -> client.VerifyDomainResponse
+	7,  // 43: client.WebPublisher.RemoveDomain:output_type -> client.Ok
+	23, // 44: client.WebPublisher.ListDomains:output_type -> client.ListDomainsResponse
+	26, // 45: client.WebPublisher.ListRedirects:output_type -> client.ListRedirectsResponse
+	7,  // 46: client.WebPublisher.DeleteRedirect:output_type -> client.Ok
+	29, // 47: client.WebPublisher.CreateShareLink:output_type -> client.CreateShareLinkResponse
+	7,  // 48: client.WebPublisher.RevokeShareLinks:output_type -> client.Ok
+	7,  // 49: client.WebPublisher.PromotePreview:output_type -> client.Ok
+	33, // 50: client.WebPublisher.GetPublishStats:output_type -> client.GetPublishStatsResponse
+	7,  // 51: client.WebPublisher.SetAnalytics:output_type -> client.Ok
+	38, // 52: client.WebPublisher.GetAnalytics:output_type -> client.GetAnalyticsResponse
+	41, // 53: client.WebPublisher.UploadFiles:output_type -> client.UploadFilesResponse
+	35, // [35:54] is the sub-list for method output_type
+	16, // [16:35] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_publishclient_publishapi_protos_publisher_proto_init() }
@@ -805,8 +2652,8 @@ func file_publishclient_publishapi_protos_publisher_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_publishclient_publishapi_protos_publisher_proto_rawDesc), len(file_publishclient_publishapi_protos_publisher_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   11,
+			NumEnums:      4,
+			NumMessages:   38,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
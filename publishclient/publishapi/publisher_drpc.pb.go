@@ -38,6 +38,20 @@ type DRPCWebPublisherClient interface {
 	Publish(ctx context.Context, in *PublishRequest) (*PublishResponse, error)
 	UnPublish(ctx context.Context, in *UnPublishRequest) (*Ok, error)
 	ListPublishes(ctx context.Context, in *ListPublishesRequest) (*ListPublishesResponse, error)
+	SetNotFoundPage(ctx context.Context, in *SetNotFoundPageRequest) (*Ok, error)
+	AddDomain(ctx context.Context, in *AddDomainRequest) (*AddDomainResponse, error)
+	VerifyDomain(ctx context.Context, in *VerifyDomainRequest) (*VerifyDomainResponse, error)
+	RemoveDomain(ctx context.Context, in *RemoveDomainRequest) (*Ok, error)
+	ListDomains(ctx context.Context, in *ListDomainsRequest) (*ListDomainsResponse, error)
+	ListRedirects(ctx context.Context, in *ListRedirectsRequest) (*ListRedirectsResponse, error)
+	DeleteRedirect(ctx context.Context, in *DeleteRedirectRequest) (*Ok, error)
+	CreateShareLink(ctx context.Context, in *CreateShareLinkRequest) (*CreateShareLinkResponse, error)
+	RevokeShareLinks(ctx context.Context, in *RevokeShareLinksRequest) (*Ok, error)
+	PromotePreview(ctx context.Context, in *PromotePreviewRequest) (*Ok, error)
+	GetPublishStats(ctx context.Context, in *GetPublishStatsRequest) (*GetPublishStatsResponse, error)
+	SetAnalytics(ctx context.Context, in *SetAnalyticsRequest) (*Ok, error)
+	GetAnalytics(ctx context.Context, in *GetAnalyticsRequest) (*GetAnalyticsResponse, error)
+	UploadFiles(ctx context.Context) (DRPCWebPublisher_UploadFilesClient, error)
 }
 
 type drpcWebPublisherClient struct {
@@ -95,12 +109,188 @@ func (c *drpcWebPublisherClient) ListPublishes(ctx context.Context, in *ListPubl
 	return out, nil
 }
 
+func (c *drpcWebPublisherClient) SetNotFoundPage(ctx context.Context, in *SetNotFoundPageRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/SetNotFoundPage", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) AddDomain(ctx context.Context, in *AddDomainRequest) (*AddDomainResponse, error) {
+	out := new(AddDomainResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/AddDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) VerifyDomain(ctx context.Context, in *VerifyDomainRequest) (*VerifyDomainResponse, error) {
+	out := new(VerifyDomainResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/VerifyDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) RemoveDomain(ctx context.Context, in *RemoveDomainRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/RemoveDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) ListDomains(ctx context.Context, in *ListDomainsRequest) (*ListDomainsResponse, error) {
+	out := new(ListDomainsResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/ListDomains", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) ListRedirects(ctx context.Context, in *ListRedirectsRequest) (*ListRedirectsResponse, error) {
+	out := new(ListRedirectsResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/ListRedirects", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) DeleteRedirect(ctx context.Context, in *DeleteRedirectRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/DeleteRedirect", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) CreateShareLink(ctx context.Context, in *CreateShareLinkRequest) (*CreateShareLinkResponse, error) {
+	out := new(CreateShareLinkResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/CreateShareLink", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) RevokeShareLinks(ctx context.Context, in *RevokeShareLinksRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/RevokeShareLinks", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) PromotePreview(ctx context.Context, in *PromotePreviewRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/PromotePreview", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) GetPublishStats(ctx context.Context, in *GetPublishStatsRequest) (*GetPublishStatsResponse, error) {
+	out := new(GetPublishStatsResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/GetPublishStats", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) SetAnalytics(ctx context.Context, in *SetAnalyticsRequest) (*Ok, error) {
+	out := new(Ok)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/SetAnalytics", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) GetAnalytics(ctx context.Context, in *GetAnalyticsRequest) (*GetAnalyticsResponse, error) {
+	out := new(GetAnalyticsResponse)
+	err := c.cc.Invoke(ctx, "/client.WebPublisher/GetAnalytics", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drpcWebPublisherClient) UploadFiles(ctx context.Context) (DRPCWebPublisher_UploadFilesClient, error) {
+	stream, err := c.cc.NewStream(ctx, "/client.WebPublisher/UploadFiles", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{})
+	if err != nil {
+		return nil, err
+	}
+	x := &drpcWebPublisher_UploadFilesClient{stream}
+	return x, nil
+}
+
+type DRPCWebPublisher_UploadFilesClient interface {
+	drpc.Stream
+	Send(*UploadFilesRequest) error
+	CloseAndRecv() (*UploadFilesResponse, error)
+}
+
+type drpcWebPublisher_UploadFilesClient struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_UploadFilesClient) GetStream() drpc.Stream {
+	return x.Stream
+}
+
+func (x *drpcWebPublisher_UploadFilesClient) Send(m *UploadFilesRequest) error {
+	return x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{})
+}
+
+func (x *drpcWebPublisher_UploadFilesClient) CloseAndRecv() (*UploadFilesResponse, error) {
+	if err := x.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(UploadFilesResponse)
+	if err := x.MsgRecv(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcWebPublisher_UploadFilesClient) CloseAndRecvMsg(m *UploadFilesResponse) error {
+	if err := x.CloseSend(); err != nil {
+		return err
+	}
+	return x.MsgRecv(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{})
+}
+
 type DRPCWebPublisherServer interface {
 	ResolveUri(context.Context, *ResolveUriRequest) (*ResolveUriResponse, error)
 	GetPublishStatus(context.Context, *GetPublishStatusRequest) (*GetPublishStatusResponse, error)
 	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
 	UnPublish(context.Context, *UnPublishRequest) (*Ok, error)
 	ListPublishes(context.Context, *ListPublishesRequest) (*ListPublishesResponse, error)
+	SetNotFoundPage(context.Context, *SetNotFoundPageRequest) (*Ok, error)
+	AddDomain(context.Context, *AddDomainRequest) (*AddDomainResponse, error)
+	VerifyDomain(context.Context, *VerifyDomainRequest) (*VerifyDomainResponse, error)
+	RemoveDomain(context.Context, *RemoveDomainRequest) (*Ok, error)
+	ListDomains(context.Context, *ListDomainsRequest) (*ListDomainsResponse, error)
+	ListRedirects(context.Context, *ListRedirectsRequest) (*ListRedirectsResponse, error)
+	DeleteRedirect(context.Context, *DeleteRedirectRequest) (*Ok, error)
+	CreateShareLink(context.Context, *CreateShareLinkRequest) (*CreateShareLinkResponse, error)
+	RevokeShareLinks(context.Context, *RevokeShareLinksRequest) (*Ok, error)
+	PromotePreview(context.Context, *PromotePreviewRequest) (*Ok, error)
+	GetPublishStats(context.Context, *GetPublishStatsRequest) (*GetPublishStatsResponse, error)
+	SetAnalytics(context.Context, *SetAnalyticsRequest) (*Ok, error)
+	GetAnalytics(context.Context, *GetAnalyticsRequest) (*GetAnalyticsResponse, error)
+	UploadFiles(DRPCWebPublisher_UploadFilesStream) error
 }
 
 type DRPCWebPublisherUnimplementedServer struct{}
@@ -125,9 +315,65 @@ func (s *DRPCWebPublisherUnimplementedServer) ListPublishes(context.Context, *Li
 	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
 }
 
+func (s *DRPCWebPublisherUnimplementedServer) SetNotFoundPage(context.Context, *SetNotFoundPageRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) AddDomain(context.Context, *AddDomainRequest) (*AddDomainResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) VerifyDomain(context.Context, *VerifyDomainRequest) (*VerifyDomainResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) RemoveDomain(context.Context, *RemoveDomainRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) ListDomains(context.Context, *ListDomainsRequest) (*ListDomainsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) ListRedirects(context.Context, *ListRedirectsRequest) (*ListRedirectsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) DeleteRedirect(context.Context, *DeleteRedirectRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) CreateShareLink(context.Context, *CreateShareLinkRequest) (*CreateShareLinkResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) RevokeShareLinks(context.Context, *RevokeShareLinksRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) PromotePreview(context.Context, *PromotePreviewRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) GetPublishStats(context.Context, *GetPublishStatsRequest) (*GetPublishStatsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) SetAnalytics(context.Context, *SetAnalyticsRequest) (*Ok, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) GetAnalytics(context.Context, *GetAnalyticsRequest) (*GetAnalyticsResponse, error) {
+	return nil, drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
+func (s *DRPCWebPublisherUnimplementedServer) UploadFiles(DRPCWebPublisher_UploadFilesStream) error {
+	return drpcerr.WithCode(errors.New("Unimplemented"), drpcerr.Unimplemented)
+}
+
 type DRPCWebPublisherDescription struct{}
 
-func (DRPCWebPublisherDescription) NumMethods() int { return 5 }
+func (DRPCWebPublisherDescription) NumMethods() int { return 19 }
 
 func (DRPCWebPublisherDescription) Method(n int) (string, drpc.Encoding, drpc.Receiver, interface{}, bool) {
 	switch n {
@@ -176,6 +422,131 @@ func (DRPCWebPublisherDescription) Method(n int) (string, drpc.Encoding, drpc.Re
 						in1.(*ListPublishesRequest),
 					)
 			}, DRPCWebPublisherServer.ListPublishes, true
+	case 5:
+		return "/client.WebPublisher/SetNotFoundPage", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					SetNotFoundPage(
+						ctx,
+						in1.(*SetNotFoundPageRequest),
+					)
+			}, DRPCWebPublisherServer.SetNotFoundPage, true
+	case 6:
+		return "/client.WebPublisher/AddDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					AddDomain(
+						ctx,
+						in1.(*AddDomainRequest),
+					)
+			}, DRPCWebPublisherServer.AddDomain, true
+	case 7:
+		return "/client.WebPublisher/VerifyDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					VerifyDomain(
+						ctx,
+						in1.(*VerifyDomainRequest),
+					)
+			}, DRPCWebPublisherServer.VerifyDomain, true
+	case 8:
+		return "/client.WebPublisher/RemoveDomain", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					RemoveDomain(
+						ctx,
+						in1.(*RemoveDomainRequest),
+					)
+			}, DRPCWebPublisherServer.RemoveDomain, true
+	case 9:
+		return "/client.WebPublisher/ListDomains", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					ListDomains(
+						ctx,
+						in1.(*ListDomainsRequest),
+					)
+			}, DRPCWebPublisherServer.ListDomains, true
+	case 10:
+		return "/client.WebPublisher/ListRedirects", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					ListRedirects(
+						ctx,
+						in1.(*ListRedirectsRequest),
+					)
+			}, DRPCWebPublisherServer.ListRedirects, true
+	case 11:
+		return "/client.WebPublisher/DeleteRedirect", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					DeleteRedirect(
+						ctx,
+						in1.(*DeleteRedirectRequest),
+					)
+			}, DRPCWebPublisherServer.DeleteRedirect, true
+	case 12:
+		return "/client.WebPublisher/CreateShareLink", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					CreateShareLink(
+						ctx,
+						in1.(*CreateShareLinkRequest),
+					)
+			}, DRPCWebPublisherServer.CreateShareLink, true
+	case 13:
+		return "/client.WebPublisher/RevokeShareLinks", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					RevokeShareLinks(
+						ctx,
+						in1.(*RevokeShareLinksRequest),
+					)
+			}, DRPCWebPublisherServer.RevokeShareLinks, true
+	case 14:
+		return "/client.WebPublisher/PromotePreview", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					PromotePreview(
+						ctx,
+						in1.(*PromotePreviewRequest),
+					)
+			}, DRPCWebPublisherServer.PromotePreview, true
+	case 15:
+		return "/client.WebPublisher/GetPublishStats", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					GetPublishStats(
+						ctx,
+						in1.(*GetPublishStatsRequest),
+					)
+			}, DRPCWebPublisherServer.GetPublishStats, true
+	case 16:
+		return "/client.WebPublisher/SetAnalytics", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					SetAnalytics(
+						ctx,
+						in1.(*SetAnalyticsRequest),
+					)
+			}, DRPCWebPublisherServer.SetAnalytics, true
+	case 17:
+		return "/client.WebPublisher/GetAnalytics", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return srv.(DRPCWebPublisherServer).
+					GetAnalytics(
+						ctx,
+						in1.(*GetAnalyticsRequest),
+					)
+			}, DRPCWebPublisherServer.GetAnalytics, true
+	case 18:
+		return "/client.WebPublisher/UploadFiles", drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{},
+			func(srv interface{}, ctx context.Context, in1, in2 interface{}) (drpc.Message, error) {
+				return nil, srv.(DRPCWebPublisherServer).
+					UploadFiles(
+						&drpcWebPublisher_UploadFilesStream{in1.(drpc.Stream)},
+					)
+			}, DRPCWebPublisherServer.UploadFiles, true
 	default:
 		return "", nil, nil, nil, false
 	}
@@ -264,3 +635,240 @@ func (x *drpcWebPublisher_ListPublishesStream) SendAndClose(m *ListPublishesResp
 	}
 	return x.CloseSend()
 }
+
+type DRPCWebPublisher_SetNotFoundPageStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_SetNotFoundPageStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_SetNotFoundPageStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_AddDomainStream interface {
+	drpc.Stream
+	SendAndClose(*AddDomainResponse) error
+}
+
+type drpcWebPublisher_AddDomainStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_AddDomainStream) SendAndClose(m *AddDomainResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_VerifyDomainStream interface {
+	drpc.Stream
+	SendAndClose(*VerifyDomainResponse) error
+}
+
+type drpcWebPublisher_VerifyDomainStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_VerifyDomainStream) SendAndClose(m *VerifyDomainResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_RemoveDomainStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_RemoveDomainStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_RemoveDomainStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_ListDomainsStream interface {
+	drpc.Stream
+	SendAndClose(*ListDomainsResponse) error
+}
+
+type drpcWebPublisher_ListDomainsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_ListDomainsStream) SendAndClose(m *ListDomainsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_ListRedirectsStream interface {
+	drpc.Stream
+	SendAndClose(*ListRedirectsResponse) error
+}
+
+type drpcWebPublisher_ListRedirectsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_ListRedirectsStream) SendAndClose(m *ListRedirectsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_DeleteRedirectStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_DeleteRedirectStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_DeleteRedirectStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_CreateShareLinkStream interface {
+	drpc.Stream
+	SendAndClose(*CreateShareLinkResponse) error
+}
+
+type drpcWebPublisher_CreateShareLinkStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_CreateShareLinkStream) SendAndClose(m *CreateShareLinkResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_RevokeShareLinksStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_RevokeShareLinksStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_RevokeShareLinksStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_PromotePreviewStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_PromotePreviewStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_PromotePreviewStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_GetPublishStatsStream interface {
+	drpc.Stream
+	SendAndClose(*GetPublishStatsResponse) error
+}
+
+type drpcWebPublisher_GetPublishStatsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_GetPublishStatsStream) SendAndClose(m *GetPublishStatsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_SetAnalyticsStream interface {
+	drpc.Stream
+	SendAndClose(*Ok) error
+}
+
+type drpcWebPublisher_SetAnalyticsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_SetAnalyticsStream) SendAndClose(m *Ok) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_GetAnalyticsStream interface {
+	drpc.Stream
+	SendAndClose(*GetAnalyticsResponse) error
+}
+
+type drpcWebPublisher_GetAnalyticsStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_GetAnalyticsStream) SendAndClose(m *GetAnalyticsResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+type DRPCWebPublisher_UploadFilesStream interface {
+	drpc.Stream
+	SendAndClose(*UploadFilesResponse) error
+	Recv() (*UploadFilesRequest, error)
+}
+
+type drpcWebPublisher_UploadFilesStream struct {
+	drpc.Stream
+}
+
+func (x *drpcWebPublisher_UploadFilesStream) SendAndClose(m *UploadFilesResponse) error {
+	if err := x.MsgSend(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return err
+	}
+	return x.CloseSend()
+}
+
+func (x *drpcWebPublisher_UploadFilesStream) Recv() (*UploadFilesRequest, error) {
+	m := new(UploadFilesRequest)
+	if err := x.MsgRecv(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *drpcWebPublisher_UploadFilesStream) RecvMsg(m *UploadFilesRequest) error {
+	return x.MsgRecv(m, drpcEncoding_File_publishclient_publishapi_protos_publisher_proto{})
+}
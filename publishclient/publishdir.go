@@ -0,0 +1,162 @@
+package publishclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+const (
+	defaultUploadAttempts = 3
+	defaultRetryDelay     = time.Second
+	// maxUriAttempts limits the suffixed uris tried by PublishOptions.UniqueUri
+	maxUriAttempts = 10
+)
+
+// ErrVerifyFailed is returned by PublishDir when the publish status doesn't match the uploaded directory
+var ErrVerifyFailed = errors.New("publish verification failed")
+
+// PublishOptions describes the directory published by PublishDir
+type PublishOptions struct {
+	SpaceId     string
+	ObjectId    string
+	Uri         string
+	Version     string
+	Type        publishapi.PublishType
+	SpaFallback bool
+	Visibility  publishapi.Visibility
+	Members     []string
+	// Preview uploads the preview, previews are not visible in the publish status and are not verified
+	Preview bool
+	// Dir is the uploaded directory
+	Dir string
+	// FS is uploaded instead of Dir when set
+	FS fs.FS
+	// UniqueUri adds the -2, -3... suffix to the uri taken by another object instead of failing with ErrUriNotUnique
+	UniqueUri bool
+	// UploadAttempts limits the uploads failed with 5xx statuses or network errors, every attempt gets a fresh upload url, 3 by default
+	UploadAttempts int
+	// RetryDelay is the delay before the second attempt, doubled for every next one, 1s by default
+	RetryDelay time.Duration
+	// UploadOptions are applied to every upload
+	UploadOptions []UploadOption
+}
+
+// PublishResult is the result of PublishDir
+type PublishResult struct {
+	Uri       string `json:"uri"`
+	PublishId string `json:"publishId"`
+	// Url is the public page url or the secret preview url
+	Url string `json:"url"`
+	// Publish is the verified publish status, nil for previews
+	Publish  *publishapi.Publish `json:"publish,omitempty"`
+	Attempts int                 `json:"attempts"`
+}
+
+func (p *publishClient) PublishDir(ctx context.Context, opts PublishOptions) (result PublishResult, err error) {
+	if opts.SpaceId == "" || opts.ObjectId == "" || opts.Uri == "" || (opts.Dir == "" && opts.FS == nil) {
+		return result, errors.New("space, object, uri and dir are required")
+	}
+	fsys := opts.FS
+	if fsys == nil {
+		fsys = os.DirFS(opts.Dir)
+	}
+	source := fsSource{fsys: fsys}
+	_, size, err := source.stats()
+	if err != nil {
+		return
+	}
+	attempts := opts.UploadAttempts
+	if attempts <= 0 {
+		attempts = defaultUploadAttempts
+	}
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	req := &publishapi.PublishRequest{
+		SpaceId:     opts.SpaceId,
+		ObjectId:    opts.ObjectId,
+		Uri:         opts.Uri,
+		Version:     opts.Version,
+		Type:        opts.Type,
+		SpaFallback: opts.SpaFallback,
+		Visibility:  opts.Visibility,
+		Members:     opts.Members,
+		Preview:     opts.Preview,
+	}
+	for result.Attempts < attempts {
+		if result.Attempts > 0 {
+			if err = sleep(ctx, delay); err != nil {
+				return
+			}
+			delay *= 2
+		}
+		result.Attempts++
+		var resp *publishapi.PublishResponse
+		if resp, err = p.publishUniqueUri(ctx, req, opts.UniqueUri); err != nil {
+			return
+		}
+		// req.Uri keeps the requested uri for next attempts: resp.Uri of an unlisted publish has the server suffix
+		result.Uri, result.PublishId = resp.Uri, resp.PublishId
+		if result.Url, err = p.uploadFiles(ctx, resp.PublishId, resp.UploadUrl, source, opts.UploadOptions...); err != nil {
+			if ctx.Err() != nil || !isRetryable(err) {
+				return result, err
+			}
+			continue
+		}
+		if opts.Preview {
+			if result.Url == "" {
+				result.Url = resp.PreviewUrl
+			}
+			return result, nil
+		}
+		result.Publish, err = p.verifyPublish(ctx, opts.SpaceId, opts.ObjectId, resp.PublishId, size)
+		return
+	}
+	return result, fmt.Errorf("upload failed after %d attempts: %w", result.Attempts, err)
+}
+
+// publishUniqueUri creates the publish, the taken uri is suffixed when unique is set
+func (p *publishClient) publishUniqueUri(ctx context.Context, req *publishapi.PublishRequest, unique bool) (resp *publishapi.PublishResponse, err error) {
+	uri := req.Uri
+	for i := 1; ; i++ {
+		resp, err = p.publish(ctx, req)
+		if !unique || !errors.Is(err, publishapi.ErrUriNotUnique) || i == maxUriAttempts {
+			return
+		}
+		req.Uri = fmt.Sprintf("%s-%d", uri, i+1)
+	}
+}
+
+// verifyPublish checks the uploaded publish became the live one with the size of the uploaded files
+func (p *publishClient) verifyPublish(ctx context.Context, spaceId, objectId, publishId string, size int64) (publish *publishapi.Publish, err error) {
+	if publish, err = p.GetPublishStatus(ctx, spaceId, objectId); err != nil {
+		return
+	}
+	switch {
+	case publish.Status != publishapi.PublishStatus_PublishStatusPublished:
+		return publish, fmt.Errorf("%w: unexpected status %s", ErrVerifyFailed, publish.Status)
+	case publish.PublishId != publishId:
+		return publish, fmt.Errorf("%w: publish %s replaced by %s", ErrVerifyFailed, publishId, publish.PublishId)
+	case publish.Size != size:
+		return publish, fmt.Errorf("%w: size %d, uploaded %d", ErrVerifyFailed, publish.Size, size)
+	}
+	return publish, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
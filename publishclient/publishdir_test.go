@@ -0,0 +1,161 @@
+package publishclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/net/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+func TestPublishClient_PublishDir(t *testing.T) {
+	t.Run("unique uri and upload retry", func(t *testing.T) {
+		fx := newPublishDirFixture(t)
+		fx.takenUris["page"] = true
+		fx.failUploads = 1
+
+		res, err := fx.client.PublishDir(context.Background(), fx.options())
+		require.NoError(t, err)
+		assert.Equal(t, "page-2", res.Uri)
+		assert.Equal(t, "p2", res.PublishId)
+		assert.Equal(t, "https://example.org/identity/page-2", res.Url)
+		assert.Equal(t, 2, res.Attempts)
+		require.NotNil(t, res.Publish)
+		assert.Equal(t, int64(8), res.Publish.Size)
+	})
+	t.Run("unlisted upload retry", func(t *testing.T) {
+		fx := newPublishDirFixture(t)
+		fx.failUploads = 1
+		opts := fx.options()
+		opts.Visibility = publishapi.Visibility_VisibilityUnlisted
+
+		res, err := fx.client.PublishDir(context.Background(), opts)
+		require.NoError(t, err)
+		assert.Equal(t, "page-x", res.Uri)
+		assert.Equal(t, []string{"page", "page"}, fx.requestedUris)
+	})
+	t.Run("uri not unique", func(t *testing.T) {
+		fx := newPublishDirFixture(t)
+		fx.takenUris["page"] = true
+		opts := fx.options()
+		opts.UniqueUri = false
+
+		_, err := fx.client.PublishDir(context.Background(), opts)
+		assert.ErrorIs(t, err, publishapi.ErrUriNotUnique)
+	})
+	t.Run("attempts exceeded", func(t *testing.T) {
+		fx := newPublishDirFixture(t)
+		fx.failUploads = 3
+
+		res, err := fx.client.PublishDir(context.Background(), fx.options())
+		require.Error(t, err)
+		assert.Equal(t, 2, res.Attempts)
+	})
+	t.Run("size mismatch", func(t *testing.T) {
+		fx := newPublishDirFixture(t)
+		fx.statusSize = 1
+
+		_, err := fx.client.PublishDir(context.Background(), fx.options())
+		assert.ErrorIs(t, err, ErrVerifyFailed)
+	})
+}
+
+type publishDirFixture struct {
+	client *publishClient
+	publishapi.DRPCWebPublisherUnimplementedServer
+	dir         string
+	httpServer  *httptest.Server
+	mu          sync.Mutex
+	takenUris   map[string]bool
+	failUploads int
+	statusSize  int64
+	publishes   int
+	last        *publishapi.Publish
+
+	requestedUris []string
+}
+
+func newPublishDirFixture(t *testing.T) *publishDirFixture {
+	fx := &publishDirFixture{
+		dir:       t.TempDir(),
+		takenUris: map[string]bool{},
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(fx.dir, "index.html"), []byte("content1"), 0644))
+	fx.httpServer = httptest.NewServer(http.HandlerFunc(fx.upload))
+	t.Cleanup(fx.httpServer.Close)
+
+	ts := rpctest.NewTestServer()
+	require.NoError(t, publishapi.DRPCRegisterWebPublisher(ts, fx))
+	fx.client = &publishClient{
+		pool:    rpctest.NewTestPool().WithServer(ts),
+		peerIds: []string{"publishServer"},
+	}
+	return fx
+}
+
+func (fx *publishDirFixture) options() PublishOptions {
+	return PublishOptions{
+		SpaceId:        "space",
+		ObjectId:       "object",
+		Uri:            "page",
+		Dir:            fx.dir,
+		UniqueUri:      true,
+		UploadAttempts: 2,
+		RetryDelay:     time.Millisecond,
+	}
+}
+
+func (fx *publishDirFixture) Publish(ctx context.Context, req *publishapi.PublishRequest) (*publishapi.PublishResponse, error) {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	if fx.takenUris[req.Uri] {
+		return nil, publishapi.ErrUriNotUnique
+	}
+	fx.requestedUris = append(fx.requestedUris, req.Uri)
+	fx.publishes++
+	publishId := fmt.Sprintf("p%d", fx.publishes)
+	uri := req.Uri
+	if req.Visibility == publishapi.Visibility_VisibilityUnlisted {
+		uri += "-x"
+	}
+	fx.last = &publishapi.Publish{SpaceId: req.SpaceId, ObjectId: req.ObjectId, Uri: uri, PublishId: publishId}
+	return &publishapi.PublishResponse{
+		UploadUrl: fx.httpServer.URL + "/" + publishId,
+		Uri:       uri,
+		PublishId: publishId,
+	}, nil
+}
+
+func (fx *publishDirFixture) GetPublishStatus(ctx context.Context, req *publishapi.GetPublishStatusRequest) (*publishapi.GetPublishStatusResponse, error) {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	return &publishapi.GetPublishStatusResponse{Publish: fx.last}, nil
+}
+
+func (fx *publishDirFixture) upload(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	if fx.failUploads > 0 {
+		fx.failUploads--
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	fx.last.Status = publishapi.PublishStatus_PublishStatusPublished
+	fx.last.Size = 8
+	if fx.statusSize != 0 {
+		fx.last.Size = fx.statusSize
+	}
+	_, _ = fmt.Fprintf(w, `{"uploadUrl":"https://example.org/identity/%s"}`, fx.last.Uri)
+}
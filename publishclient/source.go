@@ -0,0 +1,157 @@
+package publishclient
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"path"
+	"time"
+)
+
+// UploadEntry is a file of the uploaded archive
+type UploadEntry struct {
+	// Name is the slash separated path of the file inside the publish
+	Name string
+	// Size must match the length of the Reader content
+	Size int64
+	// Reader is the file content, it's closed after the copy when it implements io.Closer
+	Reader io.Reader
+	// ModTime is the current time by default
+	ModTime time.Time
+}
+
+// uploadSource iterates the uploaded files
+type uploadSource interface {
+	// stats returns the number and the size of files, zeros when unknown
+	stats() (files int, size int64, err error)
+	// each calls do for every regular file, the entry reader is valid until do returns
+	each(ctx context.Context, do func(entry UploadEntry) error) error
+}
+
+// fsSource uploads the file system, os.DirFS makes it from the directory
+type fsSource struct {
+	fsys fs.FS
+}
+
+func (s fsSource) stats() (files int, size int64, err error) {
+	err = fs.WalkDir(s.fsys, ".", func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+		return nil
+	})
+	return
+}
+
+func (s fsSource) each(ctx context.Context, do func(entry UploadEntry) error) error {
+	return fs.WalkDir(s.fsys, ".", func(name string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		// Check if the context is cancelled
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// directories are created by the file paths, symlinks and other special files are not published
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f, err := s.fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		return do(UploadEntry{Name: name, Size: info.Size(), Reader: f, ModTime: info.ModTime()})
+	})
+}
+
+// entriesSource uploads the entries, the entries are iterated again on every upload attempt
+type entriesSource struct {
+	entries iter.Seq2[UploadEntry, error]
+}
+
+func (s entriesSource) stats() (files int, size int64, err error) {
+	return 0, 0, nil
+}
+
+func (s entriesSource) each(ctx context.Context, do func(entry UploadEntry) error) (err error) {
+	for entry, entryErr := range s.entries {
+		if entryErr != nil {
+			return entryErr
+		}
+		if err = ctx.Err(); err == nil {
+			if entry.Name, err = entryName(entry); err == nil {
+				err = do(entry)
+			}
+		}
+		if closer, ok := entry.Reader.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		if err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+// entryName validates the entry and returns the cleaned name
+func entryName(entry UploadEntry) (string, error) {
+	name := path.Clean(entry.Name)
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("invalid entry name %q", entry.Name)
+	}
+	if entry.Reader == nil {
+		return "", fmt.Errorf("entry %q has no reader", entry.Name)
+	}
+	return name, nil
+}
+
+// writeTar writes the source files to the tar archive
+func writeTar(ctx context.Context, source uploadSource, tw *tar.Writer, tracker *progressTracker) error {
+	return source.each(ctx, func(entry UploadEntry) error {
+		return writeEntry(tw, entry, tracker)
+	})
+}
+
+func writeEntry(tw *tar.Writer, entry UploadEntry, tracker *progressTracker) error {
+	modTime := entry.ModTime
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.Name,
+		Size:     entry.Size,
+		Mode:     0644,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	if err = tracker.copyFile(tw, entry.Reader); err != nil {
+		if errors.Is(err, tar.ErrWriteTooLong) {
+			return fmt.Errorf("entry %q is larger than its size %d", entry.Name, entry.Size)
+		}
+		return err
+	}
+	// tar.Writer fails on the next header or close when the content is shorter than the size
+	return tw.Flush()
+}
@@ -0,0 +1,128 @@
+package publishclient
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tarServer records the files of the uploaded archives
+type tarServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	files    map[string]string
+	failures int
+}
+
+func newTarServer(t *testing.T) *tarServer {
+	ts := &tarServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.files = map[string]string{}
+		body, err := uploadBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tr := tar.NewReader(body)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if header.FileInfo().IsDir() {
+				continue
+			}
+			var buf bytes.Buffer
+			_, _ = io.Copy(&buf, tr)
+			ts.files[header.Name] = buf.String()
+		}
+		if ts.failures > 0 {
+			ts.failures--
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestUploadFS(t *testing.T) {
+	ts := newTarServer(t)
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte("index")},
+		"assets/app.css": {Data: []byte("body{}")},
+	}
+	var last UploadProgress
+	err := New().UploadFS(context.Background(), ts.URL, fsys, WithProgress(func(p UploadProgress) {
+		last = p
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"index.html": "index", "assets/app.css": "body{}"}, ts.files)
+	assert.Equal(t, UploadProgress{Files: 2, TotalFiles: 2, Bytes: 11, TotalBytes: 11, Attempt: 1}, last)
+}
+
+func TestUploadEntries(t *testing.T) {
+	entries := func(files map[string]string) iter.Seq2[UploadEntry, error] {
+		return func(yield func(UploadEntry, error) bool) {
+			for name, content := range files {
+				entry := UploadEntry{Name: name, Size: int64(len(content)), Reader: strings.NewReader(content), ModTime: time.Unix(1, 0)}
+				if !yield(entry, nil) {
+					return
+				}
+			}
+		}
+	}
+	t.Run("retry iterates again", func(t *testing.T) {
+		ts := newTarServer(t)
+		ts.failures = 1
+		files := map[string]string{"index.html": "index", "sub/page.html": "page"}
+		err := New().UploadEntries(context.Background(), ts.URL, entries(files), WithRetry(RetryPolicy{Attempts: 2, Delay: time.Millisecond}))
+		require.NoError(t, err)
+		assert.Equal(t, files, ts.files)
+	})
+	t.Run("size mismatch", func(t *testing.T) {
+		ts := newTarServer(t)
+		seq := func(yield func(UploadEntry, error) bool) {
+			yield(UploadEntry{Name: "index.html", Size: 2, Reader: strings.NewReader("index")}, nil)
+		}
+		err := New().UploadEntries(context.Background(), ts.URL, seq)
+		assert.ErrorContains(t, err, "larger than its size")
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		ts := newTarServer(t)
+		seq := func(yield func(UploadEntry, error) bool) {
+			yield(UploadEntry{Name: "../index.html", Reader: strings.NewReader("")}, nil)
+		}
+		err := New().UploadEntries(context.Background(), ts.URL, seq)
+		assert.ErrorContains(t, err, "invalid entry name")
+	})
+	t.Run("iterator error", func(t *testing.T) {
+		ts := newTarServer(t)
+		iterErr := errors.New("export failed")
+		seq := func(yield func(UploadEntry, error) bool) {
+			yield(UploadEntry{}, iterErr)
+		}
+		err := New().UploadEntries(context.Background(), ts.URL, seq)
+		assert.ErrorIs(t, err, iterErr)
+	})
+}
@@ -0,0 +1,152 @@
+package publishclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strings"
+
+	"github.com/anyproto/any-sync/net/rpc/rpcerr"
+	"storj.io/drpc"
+	"storj.io/drpc/drpcerr"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+// uploadChunkSize is the max size of the file chunk in the stream message
+const uploadChunkSize = 1 << 20
+
+func (p *publishClient) UploadFiles(ctx context.Context, publishId, uploadUrl string, fsys fs.FS, opts ...UploadOption) (err error) {
+	_, err = p.uploadFiles(ctx, publishId, uploadUrl, fsSource{fsys: fsys}, opts...)
+	return
+}
+
+// uploadFiles streams the source over the rpc retrying by the policy, servers without the UploadFiles rpc get the http upload
+func (p *publishClient) uploadFiles(ctx context.Context, publishId, uploadUrl string, source uploadSource, opts ...UploadOption) (resultUrl string, err error) {
+	options := newUploadOptions(opts)
+	for attempt := 1; ; attempt++ {
+		if resultUrl, err = p.streamOnce(ctx, publishId, source, attempt, options); err == nil {
+			return
+		}
+		if isUnimplemented(err) {
+			return p.upload(ctx, uploadUrl, source, opts...)
+		}
+		if attempt >= options.retry.Attempts || ctx.Err() != nil || !isRetryable(err) {
+			return
+		}
+		if err = sleep(ctx, options.retry.delay(attempt)); err != nil {
+			return
+		}
+	}
+}
+
+func (p *publishClient) streamOnce(ctx context.Context, publishId string, source uploadSource, attempt int, options uploadOptions) (resultUrl string, err error) {
+	tracker, err := newProgressTracker(source, attempt, options.progress)
+	if err != nil {
+		return "", err
+	}
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+	// serverErr is the error answered by the server, it's final unlike the connection failures
+	var sourceErr, serverErr error
+	err = p.doClient(ctx, func(c publishapi.DRPCWebPublisherClient) error {
+		stream, err := c.UploadFiles(ctx)
+		if err != nil {
+			return err
+		}
+		w := &chunkWriter{stream: stream}
+		if sourceErr = sendFiles(ctx, w, publishId, source, tracker); sourceErr != nil && w.err == nil {
+			_ = stream.Close()
+			return sourceErr
+		}
+		// the failed send means the server closed the stream with the error returned by the receive
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			if !isConnError(err) {
+				serverErr = err
+			}
+			return err
+		}
+		if w.err != nil {
+			return w.err
+		}
+		resultUrl = resp.Url
+		return nil
+	})
+	switch {
+	case err == nil, err == sourceErr, isUnimplemented(err):
+		return
+	case rpcerr.Code(err) != 0:
+		return "", rpcerr.Unwrap(err)
+	case ctx.Err() != nil, err == serverErr:
+		return "", err
+	}
+	return "", &TransportError{Err: err}
+}
+
+// isConnError reports whether the stream failed on the connection before the server answered
+func isConnError(err error) bool {
+	var netErr net.Error
+	return drpc.ClosedError.Has(err) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// sendFiles sends the publishId message and the files of the source
+func sendFiles(ctx context.Context, w *chunkWriter, publishId string, source uploadSource, tracker *progressTracker) error {
+	if err := w.send(&publishapi.UploadFilesRequest{PublishId: publishId}); err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(w, uploadChunkSize)
+	return source.each(ctx, func(entry UploadEntry) (err error) {
+		if err = w.send(&publishapi.UploadFilesRequest{
+			Header: &publishapi.UploadFileHeader{Name: entry.Name, Size: entry.Size},
+		}); err != nil {
+			return
+		}
+		w.written = 0
+		if err = tracker.copyFile(bw, entry.Reader); err != nil {
+			return
+		}
+		if err = bw.Flush(); err != nil {
+			return
+		}
+		if w.written != entry.Size {
+			return fmt.Errorf("entry %q has size %d, expected %d", entry.Name, w.written, entry.Size)
+		}
+		return nil
+	})
+}
+
+// chunkWriter sends the written data as the file chunks and keeps the stream error
+type chunkWriter struct {
+	stream  publishapi.DRPCWebPublisher_UploadFilesClient
+	written int64
+	err     error
+}
+
+func (w *chunkWriter) send(msg *publishapi.UploadFilesRequest) error {
+	if w.err = w.stream.Send(msg); w.err != nil {
+		return w.err
+	}
+	return nil
+}
+
+func (w *chunkWriter) Write(p []byte) (n int, err error) {
+	// the message is marshaled by Send, so the buffer can be reused
+	if err = w.send(&publishapi.UploadFilesRequest{Chunk: p}); err != nil {
+		return 0, err
+	}
+	w.written += int64(len(p))
+	return len(p), nil
+}
+
+// isUnimplemented reports whether the server doesn't serve the rpc, older servers don't register UploadFiles
+func isUnimplemented(err error) bool {
+	return err != nil && (drpcerr.Code(err) == drpcerr.Unimplemented || strings.Contains(err.Error(), "unknown rpc"))
+}
@@ -0,0 +1,115 @@
+package publishclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anyproto/any-sync/net/rpc/rpctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+// streamServer records the files of the UploadFiles streams
+type streamServer struct {
+	publishapi.DRPCWebPublisherUnimplementedServer
+	publishId string
+	files     map[string]string
+	err       error
+	calls     int
+}
+
+func (s *streamServer) UploadFiles(stream publishapi.DRPCWebPublisher_UploadFilesStream) error {
+	s.calls++
+	s.files = map[string]string{}
+	var (
+		name string
+		buf  bytes.Buffer
+	)
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if msg.PublishId != "" {
+			s.publishId = msg.PublishId
+		}
+		if msg.Header != nil {
+			name = msg.Header.Name
+			buf.Reset()
+		}
+		buf.Write(msg.Chunk)
+		if name != "" {
+			s.files[name] = buf.String()
+		}
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return stream.SendAndClose(&publishapi.UploadFilesResponse{Url: "https://example.org/identity/page"})
+}
+
+func newStreamClient(t *testing.T, server publishapi.DRPCWebPublisherServer) *publishClient {
+	ts := rpctest.NewTestServer()
+	require.NoError(t, publishapi.DRPCRegisterWebPublisher(ts, server))
+	return &publishClient{
+		pool:    rpctest.NewTestPool().WithServer(ts),
+		peerIds: []string{"publishServer"},
+	}
+}
+
+func TestPublishClient_UploadFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("index")},
+		"assets/big.js": {Data: bytes.Repeat([]byte("a"), uploadChunkSize+10)},
+	}
+	expected := map[string]string{
+		"index.html":    "index",
+		"assets/big.js": string(fsys["assets/big.js"].Data),
+	}
+	t.Run("stream", func(t *testing.T) {
+		server := &streamServer{}
+		c := newStreamClient(t, server)
+		var last UploadProgress
+		err := c.UploadFiles(context.Background(), "publishId", "", fsys, WithProgress(func(p UploadProgress) {
+			last = p
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "publishId", server.publishId)
+		assert.Equal(t, expected, server.files)
+		assert.Equal(t, 2, last.Files)
+	})
+	t.Run("typed error", func(t *testing.T) {
+		c := newStreamClient(t, &streamServer{err: publishapi.ErrUploadLimitExceeded})
+		err := c.UploadFiles(context.Background(), "publishId", "", fsys)
+		assert.ErrorIs(t, err, ErrUploadLimitExceeded)
+	})
+	t.Run("used upload", func(t *testing.T) {
+		c := newStreamClient(t, &streamServer{err: publishapi.ErrUploadUsed})
+		err := c.UploadFiles(context.Background(), "publishId", "", fsys)
+		assert.ErrorIs(t, err, ErrUploadUrlUsed)
+	})
+	t.Run("server error is not retried", func(t *testing.T) {
+		server := &streamServer{err: errors.New("upload url already used")}
+		c := newStreamClient(t, server)
+		err := c.UploadFiles(context.Background(), "publishId", "", fsys, WithRetry(RetryPolicy{Attempts: 3}))
+		require.Error(t, err)
+		var transportErr *TransportError
+		assert.False(t, errors.As(err, &transportErr))
+		assert.Equal(t, 1, server.calls)
+	})
+	t.Run("http fallback", func(t *testing.T) {
+		ts := newTarServer(t)
+		c := newStreamClient(t, &publishapi.DRPCWebPublisherUnimplementedServer{})
+		require.NoError(t, c.UploadFiles(context.Background(), "publishId", ts.URL, fsys))
+		assert.Equal(t, expected, ts.files)
+	})
+}
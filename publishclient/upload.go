@@ -0,0 +1,328 @@
+package publishclient
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/anyproto/anytype-publish-server/publishclient/publishapi"
+)
+
+var (
+	// ErrUploadLimitExceeded is returned when the uploaded files exceed the limit of the account
+	ErrUploadLimitExceeded = publishapi.ErrUploadLimitExceeded
+	// ErrInvalidUpload is returned when the server rejects the archive content, e.g. invalid _redirects syntax
+	ErrInvalidUpload = publishapi.ErrInvalidUpload
+	// ErrUploadUrlExpired is returned when the upload url is expired, the next Publish call returns the fresh one
+	ErrUploadUrlExpired = publishapi.ErrUploadExpired
+	// ErrUploadUrlUsed is returned when the single-use upload url is taken by the concurrent or previous upload
+	ErrUploadUrlUsed = publishapi.ErrUploadUsed
+)
+
+// UploadError is the upload rejected by the server
+type UploadError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("upload failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches the status with ErrUploadLimitExceeded, ErrInvalidUpload, ErrUploadUrlExpired, ErrUploadUrlUsed and publishapi.ErrAccessDenied
+func (e *UploadError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return target == ErrUploadLimitExceeded
+	case http.StatusBadRequest:
+		return target == ErrInvalidUpload
+	case http.StatusForbidden:
+		return target == publishapi.ErrAccessDenied
+	case http.StatusGone:
+		return target == ErrUploadUrlExpired
+	case http.StatusConflict:
+		return target == ErrUploadUrlUsed
+	}
+	return false
+}
+
+// TransportError is the upload failed before the server response
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return "upload transport error: " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// isRetryable reports whether the failed upload may succeed on the next attempt
+func isRetryable(err error) bool {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		return uploadErr.StatusCode >= http.StatusInternalServerError
+	}
+	var transportErr *TransportError
+	return errors.As(err, &transportErr)
+}
+
+// UploadProgress is reported after every chunk of the archive written to the upload stream
+type UploadProgress struct {
+	Files      int
+	TotalFiles int
+	Bytes      int64
+	TotalBytes int64
+	// TotalFiles and TotalBytes are zero for UploadEntries
+	// Attempt is the upload attempt starting from 1, the progress restarts with every attempt
+	Attempt int
+}
+
+// RetryPolicy retries uploads failed with 5xx statuses and network errors
+type RetryPolicy struct {
+	// Attempts is the total number of attempts, 1 disables retries
+	Attempts int
+	// Delay is the delay before the first retry, doubled for every next one
+	Delay time.Duration
+	// MaxDelay limits the delay, unlimited by default
+	MaxDelay time.Duration
+}
+
+func (r RetryPolicy) delay(retry int) time.Duration {
+	d := r.Delay
+	for i := 1; i < retry && (r.MaxDelay <= 0 || d < r.MaxDelay); i++ {
+		d *= 2
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	return d
+}
+
+// Compression is the compression of the upload archive
+type Compression int
+
+const (
+	// CompressionGzip is the default compression
+	CompressionGzip Compression = iota
+	// CompressionNone sends the raw tar, e.g. for servers without compressed uploads support
+	CompressionNone
+)
+
+// UploadOption configures the upload
+type UploadOption func(o *uploadOptions)
+
+type uploadOptions struct {
+	httpClient  *http.Client
+	timeout     time.Duration
+	retry       RetryPolicy
+	progress    func(p UploadProgress)
+	compression Compression
+}
+
+// WithHTTPClient sets the http client of the upload requests
+func WithHTTPClient(client *http.Client) UploadOption {
+	return func(o *uploadOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithTimeout limits every upload attempt
+func WithTimeout(timeout time.Duration) UploadOption {
+	return func(o *uploadOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry sets the retry policy, uploads are not retried by default.
+// Servers with single-use upload urls reject the retries after the started upload, PublishDir retries with fresh urls
+func WithRetry(policy RetryPolicy) UploadOption {
+	return func(o *uploadOptions) {
+		o.retry = policy
+	}
+}
+
+// WithCompression sets the compression of the archive, gzip by default
+func WithCompression(compression Compression) UploadOption {
+	return func(o *uploadOptions) {
+		o.compression = compression
+	}
+}
+
+// WithProgress sets the callback receiving the upload progress, the callback is called from the archiving goroutine
+func WithProgress(progress func(p UploadProgress)) UploadOption {
+	return func(o *uploadOptions) {
+		o.progress = progress
+	}
+}
+
+func newUploadOptions(opts []UploadOption) uploadOptions {
+	o := uploadOptions{
+		httpClient: http.DefaultClient,
+		retry:      RetryPolicy{Attempts: 1},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.retry.Attempts < 1 {
+		o.retry.Attempts = 1
+	}
+	return o
+}
+
+// upload uploads the archive of the source retrying by the policy and returns the result url from the server response
+func (p *publishClient) upload(ctx context.Context, uploadUrl string, source uploadSource, opts ...UploadOption) (resultUrl string, err error) {
+	options := newUploadOptions(opts)
+	for attempt := 1; ; attempt++ {
+		if resultUrl, err = p.uploadOnce(ctx, uploadUrl, source, attempt, options); err == nil {
+			return
+		}
+		if attempt >= options.retry.Attempts || ctx.Err() != nil || !isRetryable(err) {
+			return
+		}
+		if err = sleep(ctx, options.retry.delay(attempt)); err != nil {
+			return
+		}
+	}
+}
+
+func (p *publishClient) uploadOnce(ctx context.Context, uploadUrl string, source uploadSource, attempt int, options uploadOptions) (resultUrl string, err error) {
+	tracker, err := newProgressTracker(source, attempt, options.progress)
+	if err != nil {
+		return "", err
+	}
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	// Create a pipe for streaming the tar archive
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	walkDone := make(chan error, 1)
+
+	// Start a goroutine for packing files into the tar archive
+	go func() {
+		var (
+			w  io.Writer = pw
+			zw *gzip.Writer
+		)
+		if options.compression == CompressionGzip {
+			zw = gzip.NewWriter(pw)
+			w = zw
+		}
+		tw := tar.NewWriter(w)
+		walkErr := writeTar(ctx, source, tw, tracker)
+		if walkErr == nil {
+			walkErr = tw.Close()
+		}
+		if walkErr == nil && zw != nil {
+			walkErr = zw.Close()
+		}
+
+		// Close the writer with the resulting error, nil closes it with EOF
+		_ = pw.CloseWithError(walkErr)
+		walkDone <- walkErr
+	}()
+
+	// Send the tar archive to the server as a POST request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadUrl, pr)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	if options.compression == CompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := options.httpClient.Do(req)
+	if err != nil {
+		// the transport closes the body on errors, so the archiving goroutine is done soon
+		walkErr := <-walkDone
+		if walkErr != nil && !errors.Is(walkErr, io.ErrClosedPipe) && ctx.Err() == nil {
+			return "", walkErr
+		}
+		return "", &TransportError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newUploadError(resp)
+	}
+
+	var result struct {
+		UploadUrl string `json:"uploadUrl"`
+	}
+	// the result url is optional, older servers answer with an empty body
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return result.UploadUrl, nil
+}
+
+// newUploadError reads the error message of the server response
+func newUploadError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	msg := string(body)
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+		msg = errResp.Error
+	}
+	return &UploadError{StatusCode: resp.StatusCode, Message: msg}
+}
+
+// progressTracker counts files and bytes of the archived directory
+type progressTracker struct {
+	progress UploadProgress
+	report   func(p UploadProgress)
+}
+
+func newProgressTracker(source uploadSource, attempt int, report func(p UploadProgress)) (*progressTracker, error) {
+	t := &progressTracker{report: report}
+	if report == nil {
+		return t, nil
+	}
+	t.progress.Attempt = attempt
+	var err error
+	if t.progress.TotalFiles, t.progress.TotalBytes, err = source.stats(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// copyFile copies the file content reporting the progress
+func (t *progressTracker) copyFile(w io.Writer, r io.Reader) (err error) {
+	if t.report == nil {
+		_, err = io.Copy(w, r)
+		return
+	}
+	_, err = io.Copy(w, &progressReader{r: r, t: t})
+	if err == nil {
+		t.progress.Files++
+		t.report(t.progress)
+	}
+	return
+}
+
+type progressReader struct {
+	r io.Reader
+	t *progressTracker
+}
+
+func (p *progressReader) Read(b []byte) (n int, err error) {
+	n, err = p.r.Read(b)
+	if n > 0 {
+		p.t.progress.Bytes += int64(n)
+		p.t.report(p.t.progress)
+	}
+	return
+}
@@ -0,0 +1,12 @@
+package stats
+
+type configGetter interface {
+	GetStats() Config
+}
+
+type Config struct {
+	// Salt is mixed into the visitor ip hash, it's required and the same salt must be used by all gateway instances
+	Salt string `yaml:"salt"`
+	// FlushIntervalSec is the interval of copying counters from redis to mongo, 300 by default
+	FlushIntervalSec int `yaml:"flushIntervalSec"`
+}
@@ -0,0 +1,279 @@
+// Package stats counts page views on the gateway.
+//
+// Counters live in redis per identity+uri per UTC day: views are incremented and visitors are added
+// to a HyperLogLog by the salted hash of the ip, so ips are never stored. The flush job copies
+// the counters of the recent days to mongo, the copy is idempotent as counters are absolute.
+package stats
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/app/logger"
+	"github.com/anyproto/any-sync/util/periodicsync"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/anyproto/anytype-publish-server/db"
+	"github.com/anyproto/anytype-publish-server/domain"
+	"github.com/anyproto/anytype-publish-server/redisprovider"
+)
+
+const CName = "publish.stats"
+
+var log = logger.NewNamed(CName)
+
+const (
+	DateLayout = "2006-01-02"
+	// MaxRangeDays is the max number of days returned by Get
+	MaxRangeDays = 366
+
+	// counters are kept in redis until they are flushed for sure
+	counterTTL      = 3 * 24 * time.Hour
+	flushDays       = 2
+	countTimeout    = 5 * time.Second
+	defaultFlushSec = 300
+	// views are counted by the single worker in batches, views over the queue size are dropped
+	viewQueueSize = 10000
+	viewBatchSize = 100
+)
+
+var ErrInvalidRange = errors.New("invalid date range")
+
+func New() Stats {
+	return new(stats)
+}
+
+type Stats interface {
+	// CountView queues the page view for the background count, it never blocks the request
+	CountView(identity, uri, visitorIp string)
+	// Get returns the daily stats of the uri between the UTC dates inclusive, days without views are omitted
+	Get(ctx context.Context, identity, uri string, from, to time.Time) (days []domain.PageStats, err error)
+	app.ComponentRunnable
+}
+
+type stats struct {
+	config      Config
+	redisClient redis.UniversalClient
+	coll        *mongo.Collection
+	ticker      periodicsync.PeriodicSync
+
+	views      chan view
+	dropped    atomic.Int64
+	done       chan struct{}
+	workerDone chan struct{}
+}
+
+type view struct {
+	identity, uri, day, visitor string
+}
+
+func (s *stats) Name() (name string) {
+	return CName
+}
+
+func (s *stats) Init(a *app.App) (err error) {
+	s.config = a.MustComponent("config").(configGetter).GetStats()
+	if s.config.Salt == "" {
+		// the unsalted hash of the ip can be reversed by brute force
+		return errors.New("stats.salt is required")
+	}
+	s.redisClient = a.MustComponent(redisprovider.CName).(redisprovider.RedisProvider).Redis()
+	s.coll = a.MustComponent(db.CName).(db.Database).Db().Collection("stats")
+	s.views = make(chan view, viewQueueSize)
+	s.done = make(chan struct{})
+	return
+}
+
+func (s *stats) Run(ctx context.Context) (err error) {
+	if _, err = s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{"identity", 1}, {"uri", 1}, {"date", 1}},
+	}); err != nil {
+		return
+	}
+	flushSec := s.config.FlushIntervalSec
+	if flushSec <= 0 {
+		flushSec = defaultFlushSec
+	}
+	s.ticker = periodicsync.NewPeriodicSync(flushSec, time.Minute, s.Flush, log)
+	s.ticker.Run()
+	s.workerDone = make(chan struct{})
+	go s.countWorker()
+	return
+}
+
+func (s *stats) CountView(identity, uri, visitorIp string) {
+	day := time.Now().UTC().Format(DateLayout)
+	select {
+	case s.views <- view{identity: identity, uri: uri, day: day, visitor: hashVisitor(s.config.Salt, day, visitorIp)}:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// countWorker counts the queued views, the views queued meanwhile are counted by one pipeline
+func (s *stats) countWorker() {
+	defer close(s.workerDone)
+	batch := make([]view, 0, viewBatchSize)
+	for {
+		select {
+		case <-s.done:
+			return
+		case v := <-s.views:
+			batch = append(batch[:0], v)
+		fill:
+			for len(batch) < viewBatchSize {
+				select {
+				case v = <-s.views:
+					batch = append(batch, v)
+				default:
+					break fill
+				}
+			}
+			s.countViews(batch)
+		}
+	}
+}
+
+func (s *stats) countViews(views []view) {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		log.Warn("views dropped, the queue is full", zap.Int64("count", dropped))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+	_, err := s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, v := range views {
+			prefix := counterPrefix(v.identity, v.uri, v.day)
+			pipe.Incr(ctx, prefix+":views")
+			pipe.Expire(ctx, prefix+":views", counterTTL)
+			pipe.PFAdd(ctx, prefix+":uniques", v.visitor)
+			pipe.Expire(ctx, prefix+":uniques", counterTTL)
+			pipe.SAdd(ctx, dirtyKey(v.day), v.identity+"/"+v.uri)
+			pipe.Expire(ctx, dirtyKey(v.day), counterTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn("count view error", zap.Int("count", len(views)), zap.Error(err))
+	}
+}
+
+// Flush copies the counters of today and yesterday to mongo
+func (s *stats) Flush(ctx context.Context) error {
+	st := time.Now()
+	var flushed int
+	for i := range flushDays {
+		day := st.UTC().AddDate(0, 0, -i).Format(DateLayout)
+		n, err := s.flushDay(ctx, day)
+		flushed += n
+		if err != nil {
+			log.Warn("flush stats error", zap.String("day", day), zap.Error(err))
+		}
+	}
+	log.Info("flushed stats", zap.Int("count", flushed), zap.Duration("dur", time.Since(st)))
+	return nil
+}
+
+func (s *stats) flushDay(ctx context.Context, day string) (flushed int, err error) {
+	pages, err := s.redisClient.SMembers(ctx, dirtyKey(day)).Result()
+	if err != nil {
+		return
+	}
+	for _, page := range pages {
+		identity, uri, ok := strings.Cut(page, "/")
+		if !ok {
+			continue
+		}
+		prefix := counterPrefix(identity, uri, day)
+		var (
+			views   *redis.StringCmd
+			uniques *redis.IntCmd
+		)
+		if _, err = s.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			views = pipe.Get(ctx, prefix+":views")
+			uniques = pipe.PFCount(ctx, prefix+":uniques")
+			return nil
+		}); err != nil && !errors.Is(err, redis.Nil) {
+			return
+		}
+		viewCount, _ := views.Int64()
+		if _, err = s.coll.UpdateOne(
+			ctx,
+			bson.D{{"_id", page + "/" + day}},
+			bson.D{{"$set", domain.PageStats{
+				Identity: identity,
+				Uri:      uri,
+				Date:     day,
+				Views:    viewCount,
+				Uniques:  uniques.Val(),
+			}}},
+			options.Update().SetUpsert(true),
+		); err != nil {
+			return
+		}
+		flushed++
+	}
+	return flushed, nil
+}
+
+func (s *stats) Get(ctx context.Context, identity, uri string, from, to time.Time) (days []domain.PageStats, err error) {
+	from, to = from.UTC(), to.UTC()
+	if to.Before(from) || to.Sub(from) > MaxRangeDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	cur, err := s.coll.Find(
+		ctx,
+		bson.D{
+			{"identity", identity},
+			{"uri", uri},
+			{"date", bson.D{
+				{"$gte", from.Format(DateLayout)},
+				{"$lte", to.Format(DateLayout)},
+			}},
+		},
+		options.Find().SetSort(bson.D{{"date", 1}}),
+	)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cur.Close(ctx)
+	}()
+	err = cur.All(ctx, &days)
+	return
+}
+
+func (s *stats) Close(ctx context.Context) (err error) {
+	if s.ticker != nil {
+		s.ticker.Close()
+	}
+	if s.workerDone != nil {
+		close(s.done)
+		<-s.workerDone
+	}
+	return
+}
+
+// hashVisitor returns the visitor id, it can't be reversed to the ip or correlated between days
+func hashVisitor(salt, day, ip string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + day + "\x00" + ip))
+	return hex.EncodeToString(sum[:16])
+}
+
+func counterPrefix(identity, uri, day string) string {
+	// all counters of the page share the hash slot
+	return "stats:{" + identity + "/" + uri + "}:" + day
+}
+
+func dirtyKey(day string) string {
+	return "stats:pages:" + day
+}
@@ -0,0 +1,40 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashVisitor(t *testing.T) {
+	h := hashVisitor("salt", "2024-01-01", "1.2.3.4")
+	assert.Len(t, h, 32)
+	assert.NotContains(t, h, "1.2.3.4")
+	assert.Equal(t, h, hashVisitor("salt", "2024-01-01", "1.2.3.4"))
+	assert.NotEqual(t, h, hashVisitor("salt", "2024-01-02", "1.2.3.4"))
+	assert.NotEqual(t, h, hashVisitor("other", "2024-01-01", "1.2.3.4"))
+	assert.NotEqual(t, h, hashVisitor("salt", "2024-01-01", "1.2.3.5"))
+}
+
+func TestStats_GetInvalidRange(t *testing.T) {
+	s := &stats{}
+	now := time.Now()
+	_, err := s.Get(context.Background(), "a1", "u1", now, now.AddDate(0, 0, -1))
+	require.ErrorIs(t, err, ErrInvalidRange)
+	_, err = s.Get(context.Background(), "a1", "u1", now.AddDate(-2, 0, 0), now)
+	require.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestStats_CountViewQueueFull(t *testing.T) {
+	s := &stats{views: make(chan view, 1)}
+	s.CountView("a1", "u1", "1.2.3.4")
+	s.CountView("a1", "u1", "1.2.3.5")
+	require.Len(t, s.views, 1)
+	assert.Equal(t, int64(1), s.dropped.Load())
+	v := <-s.views
+	assert.Equal(t, "u1", v.uri)
+	assert.Equal(t, hashVisitor("", v.day, "1.2.3.4"), v.visitor)
+}
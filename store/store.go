@@ -36,6 +36,8 @@ type Store interface {
 	Put(ctx context.Context, file File) error
 	Get(ctx context.Context, key string) (io.ReadCloser, error)
 	DeletePath(ctx context.Context, path string) error
+	// ListPrefixes calls do for every top level path, publishes are stored under their ids
+	ListPrefixes(ctx context.Context, do func(prefix string) error) error
 }
 
 type store struct {
@@ -160,3 +162,22 @@ func (s *store) DeletePath(ctx context.Context, path string) error {
 	}
 	return nil
 }
+
+func (s *store) ListPrefixes(ctx context.Context, do func(prefix string) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    s.bucket,
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, prefix := range output.CommonPrefixes {
+			if err = do(strings.TrimSuffix(aws.ToString(prefix.Prefix), "/")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}